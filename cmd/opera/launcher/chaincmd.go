@@ -6,6 +6,10 @@ import (
 )
 
 var (
+	GenesisEpochFlag = cli.Uint64Flag{
+		Name:  "epoch",
+		Usage: "sealed epoch to export the genesis at (the latest sealed epoch by default)",
+	}
 	EventsCheckFlag = cli.BoolTFlag{
 		Name:  "check",
 		Usage: "true if events should be fully checked before importing",
@@ -38,6 +42,23 @@ Events are fully verified by default, unless overridden by check=false flag.`,
 The import command imports events from an RLP-encoded files.
 Events are fully verified by default, unless overridden by --check=false flag.`,
 			},
+			{
+				Action:    utils.MigrateFlags(importBlocks),
+				Name:      "blocks",
+				Usage:     "Index finalized blocks without their execution",
+				ArgsUsage: "<filename> (<filename 2> ... <filename N>)",
+				Flags: []cli.Flag{
+					DataDirFlag,
+					utils.CacheFlag,
+				},
+				Description: `
+    opera import blocks
+
+The command indexes the blocks, transactions, receipts and logs from the files
+produced by the 'opera export blocks' command. Transactions aren't executed and
+the DAG events aren't imported, so the indexed blocks may only be read.
+Blocks must be imported in order, the parent of the first block must be already known.`,
+			},
 		},
 	}
 	exportCommand = cli.Command{
@@ -64,6 +85,46 @@ Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last epoch to write. If the file ends with .gz, the output will
 be gzipped
+`,
+			},
+			{
+				Name:      "blocks",
+				Usage:     "Export finalized blocks with transactions and receipts",
+				ArgsUsage: "<filename> [<blockFrom> <blockTo>]",
+				Action:    utils.MigrateFlags(exportBlocks),
+				Flags: []cli.Flag{
+					DataDirFlag,
+					utils.CacheFlag,
+				},
+				Description: `
+    opera export blocks
+
+Requires a first argument of the file to write to.
+Optional second and third arguments control the first and
+last block to write. If the file ends with .gz, the output will
+be gzipped.
+Each block is written with its EVM header, transactions and receipts,
+the output file may be imported with 'opera import blocks'.
+`,
+			},
+			{
+				Name:      "genesis",
+				Usage:     "Export a genesis of the network state at a sealed epoch",
+				ArgsUsage: "<filename> [--epoch=N]",
+				Action:    utils.MigrateFlags(exportGenesis),
+				Flags: []cli.Flag{
+					DataDirFlag,
+					utils.CacheFlag,
+					GenesisEpochFlag,
+				},
+				Description: `
+    opera export genesis
+
+Requires a first argument of the file to write to.
+The genesis contains the EVM state, the last block, the validators and
+the delegations right after the epoch is sealed. The epoch must be sealed
+by a node which records the epochs history.
+A network started from the genesis continues from the next epoch.
 `,
 			},
 		},
@@ -305,6 +305,23 @@ func gossipConfigWithFlags(ctx *cli.Context, src gossip.Config) (gossip.Config,
 	return cfg, nil
 }
 
+func gossipStoreConfigWithFlags(ctx *cli.Context, src gossip.StoreConfig) (gossip.StoreConfig, error) {
+	cfg := src
+
+	if ctx.GlobalIsSet(utils.GCModeFlag.Name) {
+		switch gcmode := ctx.GlobalString(utils.GCModeFlag.Name); gcmode {
+		case "full":
+			cfg.EVM.StateGC.Enabled = true
+		case "archive":
+			cfg.EVM.StateGC.Enabled = false
+		default:
+			return cfg, fmt.Errorf("--%s must be either 'full' or 'archive', got '%s'", utils.GCModeFlag.Name, gcmode)
+		}
+	}
+
+	return cfg, nil
+}
+
 func nodeConfigWithFlags(ctx *cli.Context, cfg node.Config) node.Config {
 	utils.SetNodeConfig(ctx, &cfg)
 
@@ -343,6 +360,10 @@ func mayMakeAllConfigs(ctx *cli.Context) (*config, error) {
 	if err != nil {
 		return nil, err
 	}
+	cfg.OperaStore, err = gossipStoreConfigWithFlags(ctx, cfg.OperaStore)
+	if err != nil {
+		return nil, err
+	}
 	cfg.Node = nodeConfigWithFlags(ctx, cfg.Node)
 
 	if err := cfg.Opera.Validate(); err != nil {
@@ -0,0 +1,128 @@
+package launcher
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/Fantom-foundation/go-opera/inter/dagdump"
+)
+
+var (
+	DagEpochFlag = cli.Uint64Flag{
+		Name:  "epoch",
+		Usage: "epoch to dump (the current epoch by default)",
+	}
+	DagFromLamportFlag = cli.Uint64Flag{
+		Name:  "from-lamport",
+		Usage: "first Lamport time of the dumped events",
+	}
+	DagToLamportFlag = cli.Uint64Flag{
+		Name:  "to-lamport",
+		Usage: "last Lamport time of the dumped events (no limit by default)",
+	}
+	DagValidatorsFlag = cli.StringFlag{
+		Name:  "validators",
+		Usage: "comma separated IDs of the validators whose events are dumped (all the validators by default)",
+	}
+	DagFormatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "output format: dot or json",
+		Value: "dot",
+	}
+	DagOutFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "output file (stdout by default)",
+	}
+	dagCommand = cli.Command{
+		Name:     "dag",
+		Usage:    "DAG inspection commands",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Action: utils.MigrateFlags(dumpDag),
+				Name:   "dump",
+				Usage:  "Dump a subgraph of the epoch events for visualization",
+				Flags: []cli.Flag{
+					DataDirFlag,
+					utils.CacheFlag,
+					DagEpochFlag,
+					DagFromLamportFlag,
+					DagToLamportFlag,
+					DagValidatorsFlag,
+					DagFormatFlag,
+					DagOutFlag,
+				},
+				Description: `
+    opera dag dump [--epoch N] [--from-lamport A] [--to-lamport B] [--validators 1,2] [--format dot|json] [--out file]
+
+The command writes the stored events of the epoch with their parents, creators,
+sequence numbers, Lamport times and frames, marking the roots and the Atropos
+events. The Graphviz DOT output may be rendered with 'dot -Tsvg'.
+The node must be stopped.`,
+			},
+		},
+	}
+)
+
+func dumpDag(ctx *cli.Context) error {
+	format := ctx.String(DagFormatFlag.Name)
+	if format != "dot" && format != "json" {
+		utils.Fatalf("Unknown format %s", format)
+	}
+	filter := dagdump.Filter{
+		FromLamport: idx.Lamport(ctx.Uint64(DagFromLamportFlag.Name)),
+		ToLamport:   idx.Lamport(ctx.Uint64(DagToLamportFlag.Name)),
+	}
+	if ctx.IsSet(DagValidatorsFlag.Name) {
+		filter.Validators = make(map[idx.ValidatorID]bool)
+		for _, s := range strings.Split(ctx.String(DagValidatorsFlag.Name), ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+			if err != nil {
+				utils.Fatalf("Invalid validator ID %s: %v", s, err)
+			}
+			filter.Validators[idx.ValidatorID(id)] = true
+		}
+	}
+
+	cfg := makeAllConfigs(ctx)
+
+	gdb := makeGossipStore(cfg.Node.DataDir, cfg)
+	defer gdb.Close()
+
+	epoch := gdb.GetEpoch()
+	if ctx.IsSet(DagEpochFlag.Name) {
+		epoch = idx.Epoch(ctx.Uint64(DagEpochFlag.Name))
+	}
+	vertices, err := dagdump.Collect(gdb, epoch, filter, 0)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if fn := ctx.String(DagOutFlag.Name); fn != "" {
+		fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		w = fh
+	}
+	if format == "json" {
+		err = dagdump.WriteJSON(w, vertices)
+	} else {
+		err = dagdump.WriteDOT(w, vertices)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the subgraph: %v", err)
+	}
+	log.Info("Dumped DAG", "epoch", epoch, "events", len(vertices))
+	return nil
+}
@@ -0,0 +1,470 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"runtime"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/Fantom-foundation/go-opera/gossip"
+	"github.com/Fantom-foundation/go-opera/gossip/blockproc/evmmodule"
+	"github.com/Fantom-foundation/go-opera/integration"
+	"github.com/Fantom-foundation/go-opera/topicsdb"
+	"github.com/Fantom-foundation/go-opera/utils/migration"
+)
+
+var (
+	VerifyFromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "first block to verify (the first block after the genesis by default)",
+	}
+	VerifyToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "last block to verify (the latest block by default)",
+	}
+	ReindexFromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "first block to reindex (the first stored block by default)",
+	}
+	ReindexToFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "last block to reindex (the latest block by default)",
+	}
+	ReindexVerifyFlag = cli.BoolFlag{
+		Name:  "verify",
+		Usage: "cross-check the index instead of rebuilding it",
+	}
+	DryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "report the pending migrations without applying them",
+	}
+	dbCommand = cli.Command{
+		Name:     "db",
+		Usage:    "Low level database operations",
+		Category: "MISCELLANEOUS COMMANDS",
+
+		Subcommands: []cli.Command{
+			{
+				Action: utils.MigrateFlags(inspectDB),
+				Name:   "inspect",
+				Usage:  "Print the number of keys and the size of every DB table",
+				Flags: []cli.Flag{
+					DataDirFlag,
+					JSONOutputFlag,
+				},
+				Description: `
+    opera db inspect
+
+The command iterates over all the keys of every DB, including the epoch DBs,
+and prints the number of keys and the size of every table. Keys which don't
+belong to any known table are reported in the Unknown table.
+The node must be stopped. Use --json to get the machine-readable output.`,
+			},
+			{
+				Action: utils.MigrateFlags(listMigrations),
+				Name:   "migrations",
+				Usage:  "List the applied and pending DB migrations",
+				Flags: []cli.Flag{
+					DataDirFlag,
+					JSONOutputFlag,
+				},
+				Description: `
+    opera db migrations
+
+The command prints the name, ID and status of every DB migration step, and
+the tables which are checkpointed before the step. The step which failed
+last time is reported along with the error. The node must be stopped.`,
+			},
+			{
+				Action: utils.MigrateFlags(migrateDB),
+				Name:   "migrate",
+				Usage:  "Apply the pending DB migrations",
+				Flags: []cli.Flag{
+					DataDirFlag,
+					DryRunFlag,
+				},
+				Description: `
+    opera db migrate [--dry-run]
+
+The command applies the pending DB migrations, which are otherwise applied
+at the node startup. The tables affected by a step are checkpointed before
+the step and restored if the step fails. Use --dry-run to report what would
+run. The node must be stopped.`,
+			},
+			{
+				Action: utils.MigrateFlags(recoverDB),
+				Name:   "recover",
+				Usage:  "Recover the DBs after an unclean shutdown",
+				Flags: []cli.Flag{
+					DataDirFlag,
+					utils.CacheFlag,
+					utils.SyncModeFlag,
+					utils.GCModeFlag,
+				},
+				Description: `
+    opera db recover
+
+The command brings the DBs to the last consistent flush after an unclean
+shutdown. If the last flush was interrupted while the data were written,
+the DBs are rolled back to the start of the latest fully flushed epoch, and
+the events of the rolled back epochs are connected again. All the DBs are
+dropped only if the recovery is impossible. The recovery is also performed
+automatically at the node startup. The node must be stopped.`,
+			},
+			{
+				Action: utils.MigrateFlags(verifyDB),
+				Name:   "verify",
+				Usage:  "Re-execute blocks and check the state roots and receipts",
+				Flags: []cli.Flag{
+					DataDirFlag,
+					utils.CacheFlag,
+					VerifyFromFlag,
+					VerifyToFlag,
+				},
+				Description: `
+    opera db verify [--from N] [--to M]
+
+The command re-executes the stored blocks in the range using their events,
+and compares the state roots, the skipped transactions and the receipts with
+the stored ones. The state of the block preceding the first block must be
+available. The first divergent block and transaction are reported.
+The node must be stopped.`,
+			},
+			{
+				Name:  "reindex",
+				Usage: "Rebuild the DB indexes",
+				Subcommands: []cli.Command{
+					{
+						Action: utils.MigrateFlags(reindexLogs),
+						Name:   "logs",
+						Usage:  "Rebuild the logs index from the stored receipts",
+						Flags: []cli.Flag{
+							DataDirFlag,
+							utils.CacheFlag,
+							ReindexFromFlag,
+							ReindexToFlag,
+							ReindexVerifyFlag,
+						},
+						Description: `
+    opera db reindex logs [--from N] [--to M] [--verify]
+
+The command deletes the logs index entries of the blocks range and indexes
+the logs of the stored receipts again. The blocks are processed in parallel
+batches, and an interrupted reindexing is resumed if the command is started
+again with the same range. Use --verify to cross-check the topic keys against
+the log records of the range without changing the index.
+The node must be stopped.`,
+					},
+				},
+			},
+		},
+	}
+)
+
+// makeRawDBProducer returns the producer of the existing chaindata DBs, or exits if there are no DBs.
+func makeRawDBProducer(ctx *cli.Context) kvdb.IterableDBProducer {
+	cfg := makeAllConfigs(ctx)
+	chaindataDir := path.Join(cfg.Node.DataDir, "chaindata")
+	if _, err := os.Stat(chaindataDir); err != nil {
+		utils.Fatalf("Failed to open the chaindata: %v", err)
+	}
+	producer := integration.DBProducer(chaindataDir)
+	if len(producer.Names()) == 0 {
+		utils.Fatalf("No DBs found in %s", chaindataDir)
+	}
+	return producer
+}
+
+func inspectDB(ctx *cli.Context) error {
+	stats, err := integration.InspectDBs(makeRawDBProducer(ctx))
+	if err != nil {
+		utils.Fatalf("Failed to inspect DBs: %v", err)
+	}
+	if ctx.Bool(JSONOutputFlag.Name) {
+		return printJSON(stats)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var totalKeys, totalSize uint64
+	for _, db := range stats {
+		fmt.Fprintf(w, "%s\t\t%d\t%s\t\n", db.Name, db.Keys, common.StorageSize(db.Size))
+		printTableStats(w, db.Tables, 1)
+		totalKeys += db.Keys
+		totalSize += db.Size
+	}
+	fmt.Fprintf(w, "Total\t\t%d\t%s\t\n", totalKeys, common.StorageSize(totalSize))
+	return w.Flush()
+}
+
+func printTableStats(w *tabwriter.Writer, tables []*integration.TableStats, depth int) {
+	for _, t := range tables {
+		if t.Keys == 0 {
+			continue
+		}
+		prefix := t.Prefix
+		if len(prefix) > 10 {
+			prefix = prefix[:10] + "..."
+		}
+		fmt.Fprintf(w, "%s%s\t%s\t%d\t%s\t\n", strings.Repeat("  ", depth), t.Name, prefix, t.Keys, common.StorageSize(t.Size))
+		printTableStats(w, t.Tables, depth+1)
+	}
+}
+
+type migrationsStatus struct {
+	Steps  []migration.Step   `json:"steps"`
+	Failed *migration.Failure `json:"failed,omitempty"`
+}
+
+func getMigrationsStatus(gdb *gossip.Store) *migrationsStatus {
+	steps, err := gdb.MigrationSteps()
+	if err != nil {
+		utils.Fatalf("Failed to get the migrations: %v", err)
+	}
+	return &migrationsStatus{
+		Steps:  steps,
+		Failed: gdb.GetMigrationFailure(),
+	}
+}
+
+func listMigrations(ctx *cli.Context) error {
+	cfg := makeAllConfigs(ctx)
+	gdb := makeGossipStore(cfg.Node.DataDir, cfg)
+	defer gdb.Close()
+
+	status := getMigrationsStatus(gdb)
+	if ctx.Bool(JSONOutputFlag.Name) {
+		return printJSON(status)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\tName\tStatus\tTables\t\n")
+	for _, step := range status.Steps {
+		state := "pending"
+		if step.Applied {
+			state = "applied"
+		} else if status.Failed != nil && status.Failed.ID == step.ID {
+			state = "failed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", step.ID[:16], step.Name, state, strings.Join(step.Tables, ","))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if status.Failed != nil {
+		fmt.Printf("Migration '%s' failed: %s\n", status.Failed.Name, status.Failed.Error)
+	}
+	return nil
+}
+
+func migrateDB(ctx *cli.Context) error {
+	cfg := makeAllConfigs(ctx)
+	gdb := makeGossipStore(cfg.Node.DataDir, cfg)
+	defer gdb.Close()
+
+	status := getMigrationsStatus(gdb)
+	pending := 0
+	for _, step := range status.Steps {
+		if step.Applied {
+			continue
+		}
+		pending++
+		if ctx.Bool(DryRunFlag.Name) {
+			fmt.Printf("Would apply '%s' (%s), checkpointed tables: %s\n", step.Name, step.ID[:16], strings.Join(step.Tables, ","))
+		}
+	}
+	if pending == 0 {
+		log.Info("No pending migrations")
+		return nil
+	}
+	if ctx.Bool(DryRunFlag.Name) {
+		return nil
+	}
+
+	if err := gdb.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate: %v", err)
+	}
+	if err := gdb.Commit(); err != nil {
+		return err
+	}
+	log.Info("Applied migrations", "steps", pending)
+	return nil
+}
+
+// recoveryJournalPath returns the file of the events saved by the DBs rollback.
+func recoveryJournalPath(cfg *config) string {
+	return path.Join(cfg.Node.DataDir, "chaindata", "recovery-events.rlp")
+}
+
+// replayRecoveryJournal connects the events saved by the DBs rollback and deletes the journal.
+func replayRecoveryJournal(srv *gossip.Service, fn string) error {
+	fh, err := os.Open(fn)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	log.Info("Replaying events of the recovery journal", "file", fn)
+	if err := importEventsStream(srv, fn, fh, interrupt); err != nil {
+		return err
+	}
+	return os.Remove(fn)
+}
+
+func recoverDB(ctx *cli.Context) error {
+	genesis := getOperaGenesis(ctx)
+	cfg := makeAllConfigs(ctx)
+	setOfflineConfig(cfg)
+
+	// the DBs are recovered when the node is made
+	node, svc, nodeClose := makeNode(ctx, cfg, genesis)
+	defer nodeClose()
+	startNode(ctx, node)
+
+	return replayRecoveryJournal(svc, recoveryJournalPath(cfg))
+}
+
+func verifyDB(ctx *cli.Context) error {
+	cfg := makeAllConfigs(ctx)
+
+	gdb := makeGossipStore(cfg.Node.DataDir, cfg)
+	defer gdb.Close()
+
+	from, to := idx.Block(1), gdb.GetLatestBlockIndex()
+	if genesis := gdb.GetGenesisBlockIndex(); genesis != nil {
+		from = *genesis + 1
+	}
+	if ctx.IsSet(VerifyFromFlag.Name) {
+		from = idx.Block(ctx.Uint64(VerifyFromFlag.Name))
+	}
+	if ctx.IsSet(VerifyToFlag.Name) {
+		to = idx.Block(ctx.Uint64(VerifyToFlag.Name))
+	}
+	if from > to {
+		utils.Fatalf("Invalid blocks range [%d, %d]", from, to)
+	}
+
+	// Watch for Ctrl-C while the verification is running.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	log.Info("Verifying blocks", "from", from, "to", to)
+	start, reported := time.Now(), time.Now()
+	interrupted := false
+	err := gdb.VerifyBlocks(from, to, evmmodule.New(), func(n idx.Block) bool {
+		select {
+		case <-interrupt:
+			interrupted = true
+			return false
+		default:
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Verifying blocks", "last", n, "remaining", to-n, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		return true
+	})
+	if mismatch, ok := err.(*gossip.BlockMismatchError); ok {
+		fmt.Printf("First divergent block: %d\n", mismatch.Block)
+		if mismatch.Tx != (common.Hash{}) {
+			fmt.Printf("First divergent tx:    %s (index %d)\n", mismatch.Tx.String(), mismatch.TxIndex)
+		}
+		fmt.Printf("Reason:                %s\n", mismatch.Reason)
+	}
+	if err != nil {
+		return err
+	}
+	if interrupted {
+		return fmt.Errorf("interrupted")
+	}
+	log.Info("Verified blocks", "from", from, "to", to, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func reindexLogs(ctx *cli.Context) error {
+	cfg := makeAllConfigs(ctx)
+
+	gdb := makeGossipStore(cfg.Node.DataDir, cfg)
+	defer gdb.Close()
+
+	from, to := idx.Block(0), gdb.GetLatestBlockIndex()
+	if ctx.IsSet(ReindexFromFlag.Name) {
+		from = idx.Block(ctx.Uint64(ReindexFromFlag.Name))
+	}
+	if ctx.IsSet(ReindexToFlag.Name) {
+		to = idx.Block(ctx.Uint64(ReindexToFlag.Name))
+	}
+	if from > to {
+		utils.Fatalf("Invalid blocks range [%d, %d]", from, to)
+	}
+	workers := runtime.NumCPU()
+
+	if ctx.Bool(ReindexVerifyFlag.Name) {
+		log.Info("Verifying logs index", "from", from, "to", to)
+		issues := 0
+		err := gdb.CheckLogsIndex(from, to, workers, func(id topicsdb.ID, issue string) bool {
+			fmt.Printf("Block %d, tx %s, log %d: %s\n", id.BlockNumber(), id.TxHash().String(), id.Index(), issue)
+			issues++
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if issues != 0 {
+			return fmt.Errorf("logs index has %d issues", issues)
+		}
+		log.Info("Verified logs index", "from", from, "to", to)
+		return nil
+	}
+
+	// Watch for Ctrl-C while the reindexing is running.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	log.Info("Reindexing logs", "from", from, "to", to)
+	start, reported := time.Now(), time.Now()
+	interrupted := false
+	err := gdb.ReindexLogs(from, to, workers, func(last idx.Block) bool {
+		select {
+		case <-interrupt:
+			interrupted = true
+			return false
+		default:
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Reindexing logs", "last", last, "remaining", to-last, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if err := gdb.Commit(); err != nil {
+		return err
+	}
+	if interrupted {
+		return fmt.Errorf("interrupted, run the command again with the same range to resume")
+	}
+	log.Info("Reindexed logs", "from", from, "to", to, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
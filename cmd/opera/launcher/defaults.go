@@ -29,6 +29,7 @@ func overrideFlags() {
 	utils.LegacyWSPortFlag.Value = DefaultWSPort
 	utils.LegacyGraphQLPortFlag.Value = DefaultGraphQLPort
 	utils.LegacyGraphQLPortFlag.Value = DefaultGraphQLPort
+	utils.GCModeFlag.Value = "archive"
 }
 
 // NodeDefaultConfig contains reasonable default settings.
@@ -2,6 +2,7 @@ package launcher
 
 import (
 	"compress/gzip"
+	"fmt"
 	"io"
 	"os"
 	"path"
@@ -20,12 +21,15 @@ import (
 
 	"github.com/Fantom-foundation/go-opera/gossip"
 	"github.com/Fantom-foundation/go-opera/integration"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
 	"github.com/Fantom-foundation/lachesis-base/hash"
 )
 
 var (
 	eventsFileHeader  = hexutils.HexToBytes("7e995678")
 	eventsFileVersion = hexutils.HexToBytes("00010001")
+	blocksFileHeader  = hexutils.HexToBytes("7e995679")
+	blocksFileVersion = hexutils.HexToBytes("00010001")
 )
 
 // statsReportLimit is the time limit during import and export after which we
@@ -88,6 +92,106 @@ func exportEvents(ctx *cli.Context) error {
 	return nil
 }
 
+func exportBlocks(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+
+	cfg := makeAllConfigs(ctx)
+
+	gdb := makeGossipStore(cfg.Node.DataDir, cfg)
+	defer gdb.Close()
+
+	fn := ctx.Args().First()
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+
+	from := idx.Block(1)
+	if len(ctx.Args()) > 1 {
+		n, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		if err != nil {
+			return err
+		}
+		from = idx.Block(n)
+	}
+	to := gdb.GetLatestBlockIndex()
+	if len(ctx.Args()) > 2 {
+		n, err := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+		if err != nil {
+			return err
+		}
+		to = idx.Block(n)
+	}
+
+	log.Info("Exporting blocks to file", "file", fn)
+	// Write header and version
+	_, err = writer.Write(append(blocksFileHeader, blocksFileVersion...))
+	if err != nil {
+		return err
+	}
+	err = exportBlocksTo(writer, gdb, from, to)
+	if err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+
+	return nil
+}
+
+func exportGenesis(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+
+	cfg := makeAllConfigs(ctx)
+
+	gdb := makeGossipStore(cfg.Node.DataDir, cfg)
+	defer gdb.Close()
+
+	epoch := gdb.GetEpoch() - 1
+	if ctx.IsSet(GenesisEpochFlag.Name) {
+		epoch = idx.Epoch(ctx.Uint64(GenesisEpochFlag.Name))
+	}
+
+	genStore, closeStore, err := openTempGenesisStore("opera-genesis-export")
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	log.Info("Exporting genesis", "epoch", epoch)
+	err = gdb.ExportGenesis(epoch, genStore)
+	if err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+
+	fn := ctx.Args().First()
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	log.Info("Writing genesis to file", "file", fn)
+	err = genesisstore.WriteGenesisStore(fh, genStore)
+	if err != nil {
+		return err
+	}
+	log.Info("Exported genesis", "file", fn, "hash", genStore.Hash().String())
+
+	return nil
+}
+
 func makeGossipStore(dataDir string, cfg *config) *gossip.Store {
 	rawProducer := integration.DBProducer(path.Join(dataDir, "chaindata"))
 	dbs := flushable.NewSyncedPool(integration.DBProducer(path.Join(dataDir, "chaindata")), integration.FlushIDKey)
@@ -128,3 +232,27 @@ func exportTo(w io.Writer, gdb *gossip.Store, from, to idx.Epoch) (err error) {
 
 	return
 }
+
+// exportBlocksTo writes the finalized blocks with their transactions and receipts.
+func exportBlocksTo(w io.Writer, gdb *gossip.Store, from, to idx.Block) error {
+	start, reported := time.Now(), time.Time{}
+
+	counter := 0
+	for n := from; n <= to; n++ {
+		block := gdb.GetFullBlock(n)
+		if block == nil {
+			return fmt.Errorf("block %d not found", n)
+		}
+		if err := rlp.Encode(w, block); err != nil {
+			return err
+		}
+		counter++
+		if counter%100 == 1 && time.Since(reported) >= statsReportLimit {
+			log.Info("Exporting blocks", "last", n, "exported", counter, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	log.Info("Exported blocks", "last", to, "exported", counter, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	return nil
+}
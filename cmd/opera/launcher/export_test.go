@@ -0,0 +1,127 @@
+package launcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip"
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestBlocksExportImport(t *testing.T) {
+	require := require.New(t)
+
+	genStore := makegenesis.FakeGenesisStore(3, utils.ToFtm(1e18), utils.ToFtm(8e6))
+	genesis := genStore.GetGenesis()
+	newStore := func() *gossip.Store {
+		s := gossip.NewMemStore()
+		_, err := s.ApplyGenesis(gossip.DefaultBlockProc(genesis), genesis)
+		require.NoError(err)
+		return s
+	}
+	src := newStore()
+	defer src.Close()
+
+	// the block after the genesis, with a tx and a log
+	genesisLatest := src.GetLatestBlockIndex()
+	parent := src.GetFullBlock(genesisLatest)
+	tx := types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	block := &inter.Block{
+		Time:        parent.Block.Time + 1,
+		Atropos:     hash.Event{1, 2, 3},
+		Events:      hash.Events{},
+		Txs:         []common.Hash{tx.Hash()},
+		InternalTxs: []common.Hash{},
+		SkippedTxs:  []uint32{},
+		GasUsed:     21000,
+		Root:        parent.Block.Root,
+	}
+	require.NoError(src.IndexFullBlock(&gossip.FullBlock{
+		Number: genesisLatest + 1,
+		Block:  block,
+		Header: *evmcore.ToEvmHeader(block, genesisLatest+1, parent.Block.Atropos),
+		Txs:    types.Transactions{tx},
+		Receipts: []*types.ReceiptForStorage{{
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21000,
+			Logs:              []*types.Log{{Address: common.Address{1}, Topics: []common.Hash{{2}}, Data: []byte{3}}},
+		}},
+	}))
+	latest := genesisLatest + 1
+
+	dir, err := ioutil.TempDir("", "opera-blocks-export")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"blocks.rlp", "blocks.rlp.gz"} {
+		fn := filepath.Join(dir, name)
+		writeBlocksFile(t, fn, func(w *bytes.Buffer) {
+			require.NoError(exportBlocksTo(w, src, 1, latest))
+		})
+
+		dst := newStore()
+		require.NoError(importBlocksFile(dst, fn))
+		for n := idx.Block(1); n <= latest; n++ {
+			exp := src.GetFullBlock(n)
+			got := dst.GetFullBlock(n)
+			require.NotNil(got)
+			require.Equal(exp.Header, got.Header)
+			require.Equal(exp.Txs.Len(), got.Txs.Len())
+			require.Equal(len(exp.Receipts), len(got.Receipts))
+		}
+		got := dst.GetFullBlock(latest)
+		require.Equal(tx.Hash(), got.Txs[0].Hash())
+		require.Equal(types.ReceiptStatusSuccessful, got.Receipts[0].Status)
+		require.Equal([]byte{3}, got.Receipts[0].Logs[0].Data)
+		dst.Close()
+	}
+
+	// the file of another kind is rejected
+	fn := filepath.Join(dir, "events.rlp")
+	require.NoError(ioutil.WriteFile(fn, append(eventsFileHeader, eventsFileVersion...), 0600))
+	dst := newStore()
+	defer dst.Close()
+	require.EqualError(importBlocksFile(dst, fn), "expected an blocks file, mismatched file header")
+
+	// the tampered block is rejected and isn't indexed
+	fn = filepath.Join(dir, "tampered.rlp")
+	writeBlocksFile(t, fn, func(w *bytes.Buffer) {
+		b := src.GetFullBlock(latest)
+		b.Header.Root = common.Hash{1}
+		require.NoError(rlp.Encode(w, b))
+	})
+	require.EqualError(importBlocksFile(dst, fn), fmt.Sprintf("block %d: mismatched state root", latest))
+	require.Nil(dst.GetBlock(latest))
+}
+
+// writeBlocksFile writes the blocks file header and the encoded blocks, the file is gzipped if it ends with .gz.
+func writeBlocksFile(t *testing.T, fn string, write func(w *bytes.Buffer)) {
+	buf := new(bytes.Buffer)
+	buf.Write(append(blocksFileHeader, blocksFileVersion...))
+	write(buf)
+	data := buf.Bytes()
+	if filepath.Ext(fn) == ".gz" {
+		gz := new(bytes.Buffer)
+		w := gzip.NewWriter(gz)
+		_, _ = w.Write(data)
+		require.NoError(t, w.Close())
+		data = gz.Bytes()
+	}
+	require.NoError(t, ioutil.WriteFile(fn, data, 0600))
+}
@@ -0,0 +1,373 @@
+package launcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/Fantom-foundation/go-opera/integration"
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/opera/genesis"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+)
+
+var (
+	GenesisHashFlag = cli.StringFlag{
+		Name:  "hash",
+		Usage: "expected hash of the genesis file",
+	}
+	JSONOutputFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "print the output in JSON format",
+	}
+	genesisCommand = cli.Command{
+		Name:     "genesis",
+		Usage:    "Build and inspect genesis files",
+		Category: "MISCELLANEOUS COMMANDS",
+
+		Subcommands: []cli.Command{
+			{
+				Action:    utils.MigrateFlags(inspectGenesis),
+				Name:      "inspect",
+				Usage:     "Print the genesis file content",
+				ArgsUsage: "<filename>",
+				Flags: []cli.Flag{
+					JSONOutputFlag,
+				},
+				Description: `
+    opera genesis inspect <filename>
+
+The command prints the hash, the network rules, the validators, the number of
+accounts, the total supply and the blocks of the genesis file.
+Use --json to get the machine-readable output.`,
+			},
+			{
+				Action:    utils.MigrateFlags(verifyGenesis),
+				Name:      "verify",
+				Usage:     "Verify the genesis file integrity",
+				ArgsUsage: "<filename>",
+				Flags: []cli.Flag{
+					GenesisHashFlag,
+					JSONOutputFlag,
+				},
+				Description: `
+    opera genesis verify <filename> [--hash <hash>]
+
+The command checks the file header, recomputes the genesis hash and compares it with
+the hash written in the file header and with the expected hash, if --hash is specified.
+The command exits with a non-zero code if the genesis file isn't valid.`,
+			},
+			{
+				Action:    utils.MigrateFlags(buildGenesis),
+				Name:      "build",
+				Usage:     "Build a genesis file from a JSON or TOML spec",
+				ArgsUsage: "<spec.json|spec.toml> <filename>",
+				Description: `
+    opera genesis build <spec.json|spec.toml> <filename>
+
+The command builds a genesis file of a new network from the declarative spec.
+The spec describes the network rules, the accounts with balances, code and storage,
+the validators with pubkeys and self-stakes, the delegations with lockups,
+the driver owner and the total supply. The omitted rules are taken from the mainnet rules.
+The spec is checked for consistency before the genesis file is written.`,
+			},
+		},
+	}
+)
+
+type (
+	genesisValidatorInfo struct {
+		ID            idx.ValidatorID `json:"id"`
+		Address       common.Address  `json:"address"`
+		PubKey        string          `json:"pubkey"`
+		CreationEpoch idx.Epoch       `json:"creationEpoch"`
+		Status        uint64          `json:"status"`
+	}
+	genesisBlockInfo struct {
+		Number      idx.Block       `json:"number"`
+		Atropos     common.Hash     `json:"atropos"`
+		Time        inter.Timestamp `json:"time"`
+		Root        common.Hash     `json:"root"`
+		Txs         int             `json:"txs"`
+		InternalTxs int             `json:"internalTxs"`
+	}
+	genesisInfo struct {
+		Hash          hash.Hash              `json:"hash"`
+		Rules         opera.Rules            `json:"rules"`
+		FirstEpoch    idx.Epoch              `json:"firstEpoch"`
+		Time          inter.Timestamp        `json:"time"`
+		PrevEpochTime inter.Timestamp        `json:"prevEpochTime"`
+		DriverOwner   common.Address         `json:"driverOwner"`
+		TotalSupply   *big.Int               `json:"totalSupply"`
+		Validators    []genesisValidatorInfo `json:"validators"`
+		Accounts      int                    `json:"accounts"`
+		StorageSlots  int                    `json:"storageSlots"`
+		RawEvmItems   int                    `json:"rawEvmItems"`
+		Delegations   int                    `json:"delegations"`
+		Blocks        []genesisBlockInfo     `json:"blocks"`
+	}
+	genesisVerification struct {
+		Hash         hash.Hash  `json:"hash"`
+		HeaderHash   hash.Hash  `json:"headerHash"`
+		ExpectedHash *hash.Hash `json:"expectedHash,omitempty"`
+		Valid        bool       `json:"valid"`
+		Error        string     `json:"error,omitempty"`
+	}
+)
+
+// openTempGenesisStore creates a genesis store in a temporary directory, as the genesis may be too large for memory.
+func openTempGenesisStore(name string) (*genesisstore.Store, func(), error) {
+	tmpDir, err := ioutil.TempDir("", name)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpDB, err := integration.DBProducer(tmpDir).OpenDB("genesis")
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, nil, err
+	}
+	genStore := genesisstore.NewStore(tmpDB)
+	return genStore, func() {
+		genStore.Close()
+		_ = os.RemoveAll(tmpDir)
+	}, nil
+}
+
+// readGenesisFile reads the genesis file into a temporary genesis store.
+// It returns the hash written in the file header.
+func readGenesisFile(fn string) (hash.Hash, *genesisstore.Store, func(), error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return hash.Zero, nil, nil, err
+	}
+	defer fh.Close()
+
+	headerHash, readGenesisStore, err := genesisstore.OpenGenesisStore(fh)
+	if err != nil {
+		return hash.Zero, nil, nil, err
+	}
+	genStore, closeStore, err := openTempGenesisStore("opera-genesis")
+	if err != nil {
+		return hash.Zero, nil, nil, err
+	}
+	err = readGenesisStore(genStore)
+	if err != nil {
+		closeStore()
+		return hash.Zero, nil, nil, err
+	}
+	return headerHash, genStore, closeStore, nil
+}
+
+func collectGenesisInfo(headerHash hash.Hash, genStore *genesisstore.Store) *genesisInfo {
+	metadata := genStore.GetMetadata()
+	gen := genStore.GetGenesis()
+	info := &genesisInfo{
+		Hash:          headerHash,
+		Rules:         genStore.GetRules(),
+		FirstEpoch:    metadata.FirstEpoch,
+		Time:          metadata.Time,
+		PrevEpochTime: metadata.PrevEpochTime,
+		DriverOwner:   metadata.DriverOwner,
+		TotalSupply:   metadata.TotalSupply,
+		Validators:    make([]genesisValidatorInfo, 0, len(metadata.Validators)),
+		Blocks:        []genesisBlockInfo{},
+	}
+	for _, v := range metadata.Validators {
+		info.Validators = append(info.Validators, genesisValidatorInfo{
+			ID:            v.ID,
+			Address:       v.Address,
+			PubKey:        v.PubKey.String(),
+			CreationEpoch: v.CreationEpoch,
+			Status:        v.Status,
+		})
+	}
+	gen.Accounts.ForEach(func(common.Address, genesis.Account) {
+		info.Accounts++
+	})
+	gen.Storage.ForEach(func(common.Address, common.Hash, common.Hash) {
+		info.StorageSlots++
+	})
+	gen.Delegations.ForEach(func(common.Address, idx.ValidatorID, genesis.Delegation) {
+		info.Delegations++
+	})
+	it := gen.RawEvmItems.NewIterator(nil, nil)
+	for it.Next() {
+		info.RawEvmItems++
+	}
+	it.Release()
+	gen.Blocks.ForEach(func(index idx.Block, block genesis.Block) {
+		info.Blocks = append(info.Blocks, genesisBlockInfo{
+			Number:      index,
+			Atropos:     common.Hash(block.Atropos),
+			Time:        block.Time,
+			Root:        common.Hash(block.Root),
+			Txs:         len(block.Txs),
+			InternalTxs: len(block.InternalTxs),
+		})
+	})
+	return info
+}
+
+func printJSON(v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func inspectGenesis(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+
+	headerHash, genStore, closeStore, err := readGenesisFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read genesis file: %v", err)
+	}
+	defer closeStore()
+
+	info := collectGenesisInfo(headerHash, genStore)
+	if ctx.Bool(JSONOutputFlag.Name) {
+		return printJSON(info)
+	}
+
+	fmt.Printf("Hash:            %s\n", info.Hash.String())
+	fmt.Printf("Network:         %s (ID %d)\n", info.Rules.Name, info.Rules.NetworkID)
+	fmt.Printf("First epoch:     %d\n", info.FirstEpoch)
+	fmt.Printf("Time:            %s\n", info.Time.Time().UTC().String())
+	fmt.Printf("Driver owner:    %s\n", info.DriverOwner.String())
+	fmt.Printf("Total supply:    %s\n", info.TotalSupply)
+	fmt.Printf("Accounts:        %d\n", info.Accounts)
+	fmt.Printf("Storage slots:   %d\n", info.StorageSlots)
+	fmt.Printf("Raw EVM items:   %d\n", info.RawEvmItems)
+	fmt.Printf("Delegations:     %d\n", info.Delegations)
+	fmt.Printf("Rules:           %s\n", info.Rules.String())
+	fmt.Printf("Validators:      %d\n", len(info.Validators))
+	for _, v := range info.Validators {
+		fmt.Printf("  #%d address=%s status=%d creationEpoch=%d pubkey=%s\n", v.ID, v.Address.String(), v.Status, v.CreationEpoch, v.PubKey)
+	}
+	fmt.Printf("Blocks:          %d\n", len(info.Blocks))
+	for _, b := range info.Blocks {
+		fmt.Printf("  #%d atropos=%s root=%s txs=%d internalTxs=%d\n", b.Number, b.Atropos.String(), b.Root.String(), b.Txs, b.InternalTxs)
+	}
+	return nil
+}
+
+func verifyGenesis(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+
+	res := genesisVerification{}
+	if ctx.IsSet(GenesisHashFlag.Name) {
+		raw, err := hexutil.Decode(ctx.String(GenesisHashFlag.Name))
+		if err != nil || len(raw) != len(hash.Hash{}) {
+			utils.Fatalf("Invalid --%s value: expected a 32 bytes hex string", GenesisHashFlag.Name)
+		}
+		expected := hash.BytesToHash(raw)
+		res.ExpectedHash = &expected
+	}
+
+	err := func() error {
+		headerHash, genStore, closeStore, err := readGenesisFile(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		res.HeaderHash = headerHash
+		res.Hash = genStore.Hash()
+		if res.Hash != res.HeaderHash {
+			return fmt.Errorf("genesis hash %s mismatches the file header hash %s", res.Hash.String(), res.HeaderHash.String())
+		}
+		if res.ExpectedHash != nil && res.Hash != *res.ExpectedHash {
+			return fmt.Errorf("genesis hash %s mismatches the expected hash %s", res.Hash.String(), res.ExpectedHash.String())
+		}
+		return nil
+	}()
+	res.Valid = err == nil
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	if ctx.Bool(JSONOutputFlag.Name) {
+		if err := printJSON(res); err != nil {
+			return err
+		}
+	} else if res.Valid {
+		fmt.Printf("Genesis file is valid, hash %s\n", res.Hash.String())
+	}
+	if err != nil {
+		return errors.New("invalid genesis file: " + err.Error())
+	}
+	return nil
+}
+
+func readGenesisSpec(fn string) (*makegenesis.GenesisSpec, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	spec := makegenesis.NewGenesisSpec()
+	if strings.ToLower(filepath.Ext(fn)) == ".toml" {
+		err = tomlSettings.NewDecoder(bufio.NewReader(f)).Decode(spec)
+	} else {
+		decoder := json.NewDecoder(bufio.NewReader(f))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	return spec, nil
+}
+
+func buildGenesis(ctx *cli.Context) error {
+	if len(ctx.Args()) < 2 {
+		utils.Fatalf("This command requires 2 arguments.")
+	}
+
+	spec, err := readGenesisSpec(ctx.Args().Get(0))
+	if err != nil {
+		utils.Fatalf("Failed to read genesis spec: %v", err)
+	}
+	genStore := genesisstore.NewMemStore()
+	defer genStore.Close()
+	err = makegenesis.BuildGenesisStore(spec, genStore)
+	if err != nil {
+		utils.Fatalf("Invalid genesis spec: %v", err)
+	}
+
+	fn := ctx.Args().Get(1)
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	err = genesisstore.WriteGenesisStore(fh, genStore)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Genesis file is written to %s, hash %s\n", fn, genStore.Hash().String())
+	return nil
+}
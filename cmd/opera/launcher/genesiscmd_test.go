@@ -0,0 +1,111 @@
+package launcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+// writeFakeGenesis writes a fake genesis file and returns the genesis store and the file content.
+func writeFakeGenesis(t *testing.T, fn string) (*genesisstore.Store, []byte) {
+	genStore := makegenesis.FakeGenesisStore(3, utils.ToFtm(1e18), utils.ToFtm(8e6))
+	buf := new(bytes.Buffer)
+	require.NoError(t, genesisstore.WriteGenesisStore(buf, genStore))
+	require.NoError(t, ioutil.WriteFile(fn, buf.Bytes(), 0600))
+	return genStore, buf.Bytes()
+}
+
+func expectJSON(t *testing.T, cli *testcli, v interface{}) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	require.NoError(t, err)
+	cli.Expect(string(b) + "\n")
+	cli.ExpectExit()
+}
+
+func expectFailure(t *testing.T, cli *testcli, errText string) {
+	cli.WaitExit()
+	require.Equal(t, 1, cli.ExitStatus())
+	require.Contains(t, cli.StderrText(), errText)
+}
+
+func TestGenesisInspect(t *testing.T) {
+	dir := tmpdir(t)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "fake.g")
+	genStore, _ := writeFakeGenesis(t, fn)
+	h := genStore.Hash()
+
+	cli := exec(t, "genesis", "inspect", "--json", fn)
+	expectJSON(t, cli, collectGenesisInfo(h, genStore))
+
+	cli = exec(t, "genesis", "inspect", fn)
+	cli.Expect(fmt.Sprintf("Hash:            %s\n", h.String()))
+	cli.WaitExit()
+	require.Equal(t, 0, cli.ExitStatus())
+}
+
+func TestGenesisVerify(t *testing.T) {
+	dir := tmpdir(t)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "fake.g")
+	genStore, content := writeFakeGenesis(t, fn)
+	h := genStore.Hash()
+
+	// valid file
+	cli := exec(t, "genesis", "verify", fn)
+	cli.Expect(fmt.Sprintf("Genesis file is valid, hash %s\n", h.String()))
+	cli.ExpectExit()
+	require.Equal(t, 0, cli.ExitStatus())
+
+	cli = exec(t, "genesis", "verify", "--hash", h.String(), "--json", fn)
+	expectJSON(t, cli, genesisVerification{
+		Hash:         h,
+		HeaderHash:   h,
+		ExpectedHash: &h,
+		Valid:        true,
+	})
+
+	// unexpected hash
+	other := hash.Hash{1}
+	cli = exec(t, "genesis", "verify", "--hash", other.String(), fn)
+	expectFailure(t, cli, fmt.Sprintf("invalid genesis file: genesis hash %s mismatches the expected hash %s", h.String(), other.String()))
+
+	cli = exec(t, "genesis", "verify", "--hash", other.String(), "--json", fn)
+	errText := fmt.Sprintf("genesis hash %s mismatches the expected hash %s", h.String(), other.String())
+	expectJSON(t, cli, genesisVerification{
+		Hash:         h,
+		HeaderHash:   h,
+		ExpectedHash: &other,
+		Error:        errText,
+	})
+	require.Equal(t, 1, cli.ExitStatus())
+
+	// tampered header hash
+	pos := bytes.Index(content, h.Bytes())
+	require.True(t, pos > 0)
+	tampered := append([]byte{}, content...)
+	tampered[pos] ^= 0xff
+	tamperedFn := filepath.Join(dir, "tampered.g")
+	require.NoError(t, ioutil.WriteFile(tamperedFn, tampered, 0600))
+	var headerHash hash.Hash
+	copy(headerHash[:], tampered[pos:])
+	cli = exec(t, "genesis", "verify", tamperedFn)
+	expectFailure(t, cli, fmt.Sprintf("genesis hash %s mismatches the file header hash %s", h.String(), headerHash.String()))
+
+	// truncated file
+	truncatedFn := filepath.Join(dir, "truncated.g")
+	require.NoError(t, ioutil.WriteFile(truncatedFn, content[:pos+len(h)+10], 0600))
+	cli = exec(t, "genesis", "verify", truncatedFn)
+	expectFailure(t, cli, "invalid genesis file")
+}
@@ -3,7 +3,6 @@ package launcher
 import (
 	"bytes"
 	"compress/gzip"
-	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -33,9 +32,20 @@ func importEvents(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 
-	// avoid P2P interaction, API calls and events emitting
 	genesis := getOperaGenesis(ctx)
 	cfg := makeAllConfigs(ctx)
+	setOfflineConfig(cfg)
+
+	err := importToNode(ctx, cfg, genesis, ctx.Args()...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setOfflineConfig disables P2P interaction, API calls and events emitting.
+func setOfflineConfig(cfg *config) {
 	cfg.Opera.Protocol.EventsSemaphoreLimit.Size = math.MaxUint32
 	cfg.Opera.Protocol.EventsSemaphoreLimit.Num = math.MaxUint32
 	cfg.Opera.Emitter.Validator = emitter.ValidatorConfig{}
@@ -51,13 +61,6 @@ func importEvents(ctx *cli.Context) error {
 	cfg.Node.P2P.BootstrapNodesV5 = nil
 	cfg.Node.P2P.StaticNodes = nil
 	cfg.Node.P2P.TrustedNodes = nil
-
-	err := importToNode(ctx, cfg, genesis, ctx.Args()...)
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
 
 func importToNode(ctx *cli.Context, cfg *config, genesis integration.InputGenesis, args ...string) error {
@@ -75,21 +78,29 @@ func importToNode(ctx *cli.Context, cfg *config, genesis integration.InputGenesi
 }
 
 func checkEventsFileHeader(reader io.Reader) error {
-	headerAndVersion := make([]byte, len(eventsFileHeader)+len(eventsFileVersion))
-	n, err := reader.Read(headerAndVersion)
-	if err != nil {
+	return checkFileHeader(reader, "events", eventsFileHeader, eventsFileVersion)
+}
+
+func checkBlocksFileHeader(reader io.Reader) error {
+	return checkFileHeader(reader, "blocks", blocksFileHeader, blocksFileVersion)
+}
+
+func checkFileHeader(reader io.Reader, kind string, fileHeader, fileVersion []byte) error {
+	headerAndVersion := make([]byte, len(fileHeader)+len(fileVersion))
+	n, err := io.ReadFull(reader, headerAndVersion)
+	if err != nil && err != io.ErrUnexpectedEOF {
 		return err
 	}
 	if n != len(headerAndVersion) {
-		return errors.New("expected an events file, the given file is too short")
+		return fmt.Errorf("expected an %s file, the given file is too short", kind)
 	}
-	if bytes.Compare(headerAndVersion[:len(eventsFileHeader)], eventsFileHeader) != 0 {
-		return errors.New("expected an events file, mismatched file header")
+	if bytes.Compare(headerAndVersion[:len(fileHeader)], fileHeader) != 0 {
+		return fmt.Errorf("expected an %s file, mismatched file header", kind)
 	}
-	if bytes.Compare(headerAndVersion[len(eventsFileHeader):], eventsFileVersion) != 0 {
-		got := hexutils.BytesToHex(headerAndVersion[len(eventsFileHeader):])
-		expected := hexutils.BytesToHex(eventsFileVersion)
-		return errors.New(fmt.Sprintf("wrong version of events file, got=%s, expected=%s", got, expected))
+	if bytes.Compare(headerAndVersion[len(fileHeader):], fileVersion) != 0 {
+		got := hexutils.BytesToHex(headerAndVersion[len(fileHeader):])
+		expected := hexutils.BytesToHex(fileVersion)
+		return fmt.Errorf("wrong version of %s file, got=%s, expected=%s", kind, got, expected)
 	}
 	return nil
 }
@@ -123,6 +134,11 @@ func importFile(srv *gossip.Service, fn string) error {
 		return err
 	}
 
+	return importEventsStream(srv, fn, reader, interrupt)
+}
+
+// importEventsStream connects the RLP-encoded events, the already connected events are skipped.
+func importEventsStream(srv *gossip.Service, fn string, reader io.Reader, interrupt <-chan os.Signal) error {
 	stream := rlp.NewStream(reader, 0)
 
 	start := time.Now()
@@ -160,7 +176,7 @@ func importFile(srv *gossip.Service, fn string) error {
 		default:
 		}
 		e := new(inter.EventPayload)
-		err = stream.Decode(e)
+		err := stream.Decode(e)
 		if err == io.EOF {
 			err = processBatch()
 			if err != nil {
@@ -188,3 +204,98 @@ func importFile(srv *gossip.Service, fn string) error {
 
 	return nil
 }
+
+func importBlocks(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+
+	cfg := makeAllConfigs(ctx)
+
+	gdb := makeGossipStore(cfg.Node.DataDir, cfg)
+	defer gdb.Close()
+
+	for _, fn := range ctx.Args() {
+		if err := importBlocksFile(gdb, fn); err != nil {
+			log.Error("Import error", "file", fn, "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// importBlocksFile indexes the blocks, transactions and receipts without the transactions execution.
+func importBlocksFile(gdb *gossip.Store, fn string) error {
+	// Watch for Ctrl-C while the import is running.
+	// If a signal is received, the import will stop.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	log.Info("Importing blocks from file", "file", fn)
+
+	// Open the file handle and potentially unwrap the gzip stream
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+		defer reader.(*gzip.Reader).Close()
+	}
+
+	// Check file version and header
+	if err := checkBlocksFileHeader(reader); err != nil {
+		return err
+	}
+
+	stream := rlp.NewStream(reader, 0)
+
+	start, reported := time.Now(), time.Time{}
+	last := idx.Block(0)
+	blocks := 0
+	txs := 0
+
+	for {
+		select {
+		case <-interrupt:
+			return fmt.Errorf("interrupted")
+		default:
+		}
+		b := new(gossip.FullBlock)
+		err = stream.Decode(b)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := gdb.IndexFullBlock(b); err != nil {
+			return err
+		}
+		last = b.Number
+		blocks++
+		txs += len(b.Txs)
+
+		if gdb.IsCommitNeeded(false) {
+			if err := gdb.Commit(); err != nil {
+				return err
+			}
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Importing blocks", "last", last, "imported", blocks, "txs", txs, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	if err := gdb.Commit(); err != nil {
+		return err
+	}
+	log.Info("Blocks import is finished", "file", fn, "last", last, "imported", blocks, "txs", txs, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	return nil
+}
@@ -9,6 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/cmd/utils"
@@ -89,6 +92,7 @@ func init() {
 		utils.CacheTrieFlag,
 		utils.CacheGCFlag,
 		utils.CacheNoPrefetchFlag,
+		utils.GCModeFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
@@ -185,6 +189,12 @@ func init() {
 		// See chaincmd.go
 		importCommand,
 		exportCommand,
+		// See genesiscmd.go
+		genesisCommand,
+		// See dbcmd.go
+		dbCommand,
+		// See dagcmd.go
+		dagCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -238,9 +248,12 @@ func lachesisMain(ctx *cli.Context) error {
 
 	cfg := makeAllConfigs(ctx)
 	genesisPath := getOperaGenesis(ctx)
-	node, _, nodeClose := makeNode(ctx, cfg, genesisPath)
+	node, svc, nodeClose := makeNode(ctx, cfg, genesisPath)
 	defer nodeClose()
 	startNode(ctx, node)
+	if err := replayRecoveryJournal(svc, recoveryJournalPath(cfg)); err != nil {
+		log.Warn("Failed to replay the recovery journal", "err", err)
+	}
 	node.Wait()
 	return nil
 }
@@ -257,7 +270,11 @@ func makeNode(ctx *cli.Context, cfg *config, genesis integration.InputGenesis) (
 	if err := os.MkdirAll(chaindataDir, 0700); err != nil {
 		utils.Fatalf("Failed to create chaindata directory: %v", err)
 	}
-	engine, dagIndex, gdb, cdb, genesisStore, blockProc := integration.MakeEngine(integration.DBProducer(chaindataDir), genesis, cfg.AppConfigs())
+	rawProducer := integration.DBProducer(chaindataDir)
+	if err := integration.RecoverDBs(rawProducer, recoveryJournalPath(cfg), cfg.AppConfigs()); err != nil {
+		utils.Fatalf("Failed to recover the DBs: %v", err)
+	}
+	engine, dagIndex, gdb, cdb, genesisStore, blockProc := integration.MakeEngine(rawProducer, genesis, cfg.AppConfigs())
 	_ = genesis.Close()
 	metrics.SetDataDir(cfg.Node.DataDir)
 
@@ -284,9 +301,23 @@ func makeNode(ctx *cli.Context, cfg *config, genesis integration.InputGenesis) (
 	if err != nil {
 		utils.Fatalf("Failed to create the service: %v", err)
 	}
-	err = engine.Bootstrap(svc.GetConsensusCallbacks())
-	if err != nil {
-		utils.Fatalf("Failed to bootstrap the engine: %v", err)
+	if cfg.Opera.Protocol.SnapshotSync.Enabled && gdb.GetLatestBlockIndex() == *gdb.GetGenesisBlockIndex() {
+		// the engine is bootstrapped once the snapshot sync is finished
+		svc.EnableSnapshotSync(gossip.SnapshotSyncEngine{
+			Reset: func(epoch idx.Epoch, validators *pos.Validators) error {
+				cdb.SetEpochState(&abft.EpochState{Epoch: epoch, Validators: validators})
+				cdb.SetLastDecidedState(&abft.LastDecidedState{LastDecidedFrame: abft.FirstFrame - 1})
+				return nil
+			},
+			Bootstrap: func() error {
+				return engine.Bootstrap(svc.GetConsensusCallbacks())
+			},
+		})
+	} else {
+		err = engine.Bootstrap(svc.GetConsensusCallbacks())
+		if err != nil {
+			utils.Fatalf("Failed to bootstrap the engine: %v", err)
+		}
 	}
 
 	stack.RegisterAPIs(svc.APIs())
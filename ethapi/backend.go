@@ -37,6 +37,8 @@ import (
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/sfcapi"
 	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/dagdump"
+	"github.com/Fantom-foundation/go-opera/opera"
 )
 
 // PeerProgress is synchronization status of a peer
@@ -49,6 +51,38 @@ type PeerProgress struct {
 	HighestEpoch     idx.Epoch
 }
 
+// ReplayedTx is a transaction which is re-executed during a block replay
+type ReplayedTx struct {
+	Index    int
+	Tx       *types.Transaction
+	Internal bool
+}
+
+// TxPosition is the position of a transaction in its block and in the event which counted it.
+// Event is zero for the internal transactions.
+type TxPosition struct {
+	Block       idx.Block
+	Event       hash.Event
+	EventOffset uint32
+	BlockOffset uint32
+}
+
+// AtroposNotify is posted when a new block is decided by an Atropos.
+type AtroposNotify struct {
+	Block   idx.Block
+	Time    inter.Timestamp
+	Atropos hash.Event
+	Events  hash.Events
+}
+
+// SealedEpochNotify is posted when an epoch is sealed.
+// Validators and Rules are the ones of the next epoch.
+type SealedEpochNotify struct {
+	Epoch      idx.Epoch
+	Validators *pos.Validators
+	Rules      opera.Rules
+}
+
 // Backend interface provides the common API services (that are provided by
 // both full and light clients) with access to necessary functions.
 type Backend interface {
@@ -59,9 +93,11 @@ type Backend interface {
 	ChainDb() ethdb.Database
 	AccountManager() *accounts.Manager
 	ExtRPCEnabled() bool
-	RPCGasCap() uint64    // global gas cap for eth_call over rpc: DoS protection
-	RPCTxFeeCap() float64 // global tx fee cap for all transaction related APIs
+	RPCGasCap() uint64                    // global gas cap for eth_call over rpc: DoS protection
+	RPCTxFeeCap() float64                 // global tx fee cap for all transaction related APIs
+	TraceFilterLimits() TraceFilterLimits // trace_filter limits: DoS protection
 	CalcLogsBloom() bool
+	GetBlockBloom(ctx context.Context, number rpc.BlockNumber) (*types.Bloom, error)
 
 	// Blockchain API
 	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*evmcore.EvmHeader, error)
@@ -72,10 +108,15 @@ type Backend interface {
 	BlockByHash(ctx context.Context, hash common.Hash) (*evmcore.EvmBlock, error)
 	GetReceiptsByNumber(ctx context.Context, number rpc.BlockNumber) (types.Receipts, error)
 	GetTd(hash common.Hash) *big.Int
-	GetEVM(ctx context.Context, msg evmcore.Message, state *state.StateDB, header *evmcore.EvmHeader) (*vm.EVM, func() error, error)
+	// GetEVM returns EVM to execute the message on top of the state, the state isn't modified
+	GetEVM(ctx context.Context, msg evmcore.Message, state *state.StateDB, header *evmcore.EvmHeader, vmConfig *vm.Config) (*vm.EVM, func() error, error)
 	MinGasPrice() *big.Int
 	MaxGasLimit() uint64
 
+	// Tracing API
+	ReplayBlock(ctx context.Context, number rpc.BlockNumber, onTxStart func(tx ReplayedTx) vm.Config, onTxEnd func(tx ReplayedTx, receipt *types.Receipt) bool) error
+	ForEachTraceAddressBlock(ctx context.Context, addr common.Address, from, to idx.Block, onBlock func(n idx.Block) bool) error
+
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
 	GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, uint64, uint64, error)
@@ -93,8 +134,20 @@ type Backend interface {
 	GetEventPayload(ctx context.Context, shortEventID string) (*inter.EventPayload, error)
 	GetEvent(ctx context.Context, shortEventID string) (*inter.Event, error)
 	GetHeads(ctx context.Context, epoch rpc.BlockNumber) (hash.Events, error)
+	GetDecisiveEvents(ctx context.Context, number rpc.BlockNumber) (atropos hash.Event, decisive hash.Event, roots hash.Events, err error)
+	GetDagBlock(ctx context.Context, number rpc.BlockNumber) (idx.Block, *inter.Block, error)
+	GetSkippedTxs(ctx context.Context, number idx.Block, explain bool) (types.Transactions, []error, error)
+	GetTxPosition(ctx context.Context, txHash common.Hash) (*TxPosition, error)
+	GetEventArrival(ctx context.Context, shortEventID string) (*inter.Event, inter.Timestamp, error)
+	ForEachEventArrival(ctx context.Context, epoch rpc.BlockNumber, onEvent func(e *inter.Event, arrival inter.Timestamp) bool) error
+	ForEachEpochEvent(ctx context.Context, epoch rpc.BlockNumber, onEvent func(event *inter.EventPayload) bool) error
 	CurrentEpoch(ctx context.Context) idx.Epoch
 	SealedEpochTiming(ctx context.Context) (start inter.Timestamp, end inter.Timestamp)
+	GetEpochStats(ctx context.Context, epoch rpc.BlockNumber) (*inter.EpochStats, error)
+	GetDagSubgraph(ctx context.Context, epoch rpc.BlockNumber, filter dagdump.Filter, limit int) ([]dagdump.Vertex, error)
+	SubscribeNewEventsNotify(ch chan<- *inter.EventPayload) notify.Subscription
+	SubscribeSealedEpochsNotify(ch chan<- SealedEpochNotify) notify.Subscription
+	SubscribeNewAtroposNotify(ch chan<- AtroposNotify) notify.Subscription
 
 	// Lachesis SFC API
 	GetValidators(ctx context.Context) *pos.Validators
@@ -151,6 +204,10 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(apiBackend),
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   NewPublicTxTraceAPI(apiBackend),
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
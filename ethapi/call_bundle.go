@@ -0,0 +1,152 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// CallBundleResult is the result of a single call of a calls bundle.
+type CallBundleResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnValue"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Logs        []*types.Log   `json:"logs"`
+	Error       string         `json:"error,omitempty"`
+	Revert      hexutil.Bytes  `json:"revert,omitempty"` // revert data, if the call was reverted
+}
+
+// DoCallBundle executes the calls sequentially on top of the same state, i.e. each call observes
+// the state changes of the previous calls.
+// The senders are funded once before the first call, so the balance changes made by the calls are preserved.
+// The senders with the overridden balance aren't funded.
+// The global gas cap is the total gas budget of all the calls.
+func DoCallBundle(ctx context.Context, b Backend, calls []CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides map[common.Address]account, headerOverrides *HeaderOverrides, vmCfg vm.Config, timeout time.Duration, globalGasCap uint64) ([]CallBundleResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM calls bundle finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	header = headerOverrides.Apply(header)
+	if err := applyStateOverrides(state, overrides); err != nil {
+		return nil, err
+	}
+	for _, args := range calls {
+		var from common.Address
+		if args.From != nil {
+			from = *args.From
+		}
+		if override, ok := overrides[from]; ok && override.Balance != nil {
+			continue
+		}
+		state.SetBalance(from, math.MaxBig256)
+	}
+
+	// Setup context so it may be cancelled when the calls have completed
+	// or, in case of unmetered gas, setup a context with a timeout.
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	gasLeft := globalGasCap
+	results := make([]CallBundleResult, 0, len(calls))
+	for i, args := range calls {
+		if globalGasCap != 0 && gasLeft == 0 {
+			return nil, fmt.Errorf("call %d: gas cap %d is exhausted", i, globalGasCap)
+		}
+		// each call has a unique pseudo tx hash to collect its logs
+		txHash := common.BigToHash(big.NewInt(int64(i)))
+		state.Prepare(txHash, header.Hash, i)
+
+		msg := args.ToMessage(gasLeft)
+		evm, vmError, err := b.GetEVM(ctx, msg, state, header, &vmCfg)
+		if err != nil {
+			return nil, err
+		}
+		// Wait for the context to be done and cancel the evm
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				evm.Cancel()
+			case <-done:
+			}
+		}()
+		gp := new(evmcore.GasPool).AddGas(math.MaxUint64)
+		result, err := evmcore.ApplyMessage(evm, msg, gp)
+		close(done)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w (supplied gas %d)", i, err, msg.Gas())
+		}
+		// finalise the call to make the state changes observable by the next calls
+		state.Finalise(true)
+
+		res := CallBundleResult{
+			ReturnValue: result.Return(),
+			GasUsed:     hexutil.Uint64(result.UsedGas),
+			Logs:        state.GetLogs(txHash),
+		}
+		if res.Logs == nil {
+			res.Logs = []*types.Log{}
+		}
+		if len(result.Revert()) > 0 {
+			res.Error = newRevertError(result).Error()
+			res.Revert = result.Revert()
+		} else if result.Err != nil {
+			res.Error = result.Err.Error()
+		}
+		results = append(results, res)
+
+		if globalGasCap != 0 {
+			if result.UsedGas >= gasLeft {
+				gasLeft = 0
+			} else {
+				gasLeft -= result.UsedGas
+			}
+		}
+	}
+	return results, nil
+}
+
+// CallBundle executes the given transactions sequentially on the same state for the given block number,
+// each call observes the state changes made by the previous calls.
+// RPC gas cap is the total gas budget of the bundle.
+//
+// Additionally, the caller can specify a batch of contract for fields overriding,
+// and the block header fields overriding.
+//
+// Note, this function doesn't make and changes in the state/blockchain and is
+// useful to simulate sequences of dependent transactions.
+func (s *PublicBlockChainAPI) CallBundle(ctx context.Context, calls []CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]account, headerOverrides *HeaderOverrides) ([]CallBundleResult, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("no calls are specified")
+	}
+	var accounts map[common.Address]account
+	if overrides != nil {
+		accounts = *overrides
+	}
+	return DoCallBundle(ctx, s.b, calls, blockNrOrHash, accounts, headerOverrides, opera.DefaultVMConfig, 5*time.Second, s.b.RPCGasCap())
+}
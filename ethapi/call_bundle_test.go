@@ -0,0 +1,73 @@
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// callerBalanceCode returns the balance of the caller
+	callerBalanceCode = common.FromHex("0x333160005260206000f3")
+	// selfBalanceCode returns the balance of the contract
+	selfBalanceCode = common.FromHex("0x4760005260206000f3")
+)
+
+func TestPublicBlockChainAPI_CallBundle(t *testing.T) {
+	require := require.New(t)
+
+	b := newEvmBackend()
+	callerBalance := common.Address{0xcc}
+	b.state.SetCode(callerBalance, callerBalanceCode)
+	selfBalance := common.Address{0xcd}
+	b.state.SetCode(selfBalance, selfBalanceCode)
+	api := NewPublicBlockChainAPI(b)
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	sender := common.Address{1}
+	recipient := common.Address{2}
+	value := (*hexutil.Big)(big.NewInt(5))
+	balance := func(res CallBundleResult) *big.Int {
+		require.Empty(res.Error)
+		return new(big.Int).SetBytes(res.ReturnValue)
+	}
+
+	results, err := api.CallBundle(context.Background(), []CallArgs{
+		{From: &sender, To: &recipient, Value: value},
+		{From: &sender, To: &callerBalance},
+		{From: &sender, To: &recipient, Value: value},
+		{From: &sender, To: &callerBalance},
+		{From: &sender, To: &selfBalance, Value: value},
+		{From: &recipient, To: &selfBalance},
+	}, latest, nil, nil)
+	require.NoError(err)
+	require.Len(results, 6)
+	// the sender is funded once, the next calls observe the value sent by the previous ones
+	require.Equal(new(big.Int).Sub(math.MaxBig256, big.NewInt(5)), balance(results[1]))
+	require.Equal(new(big.Int).Sub(math.MaxBig256, big.NewInt(10)), balance(results[3]))
+	require.Equal(big.NewInt(5), balance(results[4]))
+	require.Equal(big.NewInt(5), balance(results[5]))
+
+	// the backend state isn't modified
+	require.Equal(new(big.Int), b.state.GetBalance(sender))
+	require.Equal(new(big.Int), b.state.GetBalance(selfBalance))
+
+	// the overridden balance of the sender isn't replaced by the funding
+	overridden := (*hexutil.Big)(big.NewInt(100))
+	results, err = api.CallBundle(context.Background(), []CallArgs{
+		{From: &sender, To: &recipient, Value: value},
+		{From: &sender, To: &callerBalance},
+	}, latest, &map[common.Address]account{sender: {Balance: &overridden}}, nil)
+	require.NoError(err)
+	require.Len(results, 2)
+	require.Equal(big.NewInt(95), balance(results[1]))
+
+	_, err = api.CallBundle(context.Background(), nil, latest, nil, nil)
+	require.Error(err)
+}
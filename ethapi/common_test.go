@@ -0,0 +1,53 @@
+package ethapi
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// evmBackend executes calls on top of a single in-memory state, the rest of Backend isn't implemented.
+type evmBackend struct {
+	Backend
+	state  *state.StateDB
+	header *evmcore.EvmHeader
+}
+
+func newEvmBackend() *evmBackend {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return &evmBackend{
+		state: statedb,
+		header: &evmcore.EvmHeader{
+			Number:   big.NewInt(100),
+			Time:     inter.FromUnix(1600000000),
+			GasLimit: math.MaxUint64,
+			Coinbase: common.Address{0xc0},
+		},
+	}
+}
+
+func (b *evmBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *evmcore.EvmHeader, error) {
+	return b.state.Copy(), b.header, nil
+}
+
+func (b *evmBackend) GetEVM(ctx context.Context, msg evmcore.Message, state *state.StateDB, header *evmcore.EvmHeader, vmConfig *vm.Config) (*vm.EVM, func() error, error) {
+	if vmConfig == nil {
+		vmConfig = &opera.DefaultVMConfig
+	}
+	context := evmcore.NewEVMContext(msg, header, nil, nil)
+	return vm.NewEVM(context, state, opera.FakeNetRules().EvmChainConfig(), *vmConfig), func() error { return nil }, nil
+}
+
+func (b *evmBackend) RPCGasCap() uint64 {
+	return 50000000
+}
@@ -1,14 +1,30 @@
 package ethapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
+	"time"
 
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/dagdump"
+)
+
+var (
+	// finalityScanLimit is the maximum number of the epoch events scanned by dag_getTransactionFinality
+	finalityScanLimit = 10000
+	// finalityScanTimeout is the maximum duration of the epoch events scan by dag_getTransactionFinality
+	finalityScanTimeout = 5 * time.Second
 )
 
 // PublicDAGChainAPI provides an API to access the directed acyclic graph chain.
@@ -59,13 +75,454 @@ func (s *PublicDAGChainAPI) GetHeads(ctx context.Context, epoch rpc.BlockNumber)
 	return eventIDsToHex(res), nil
 }
 
+// GetDecisiveEvents returns the events which have decided the block:
+// its Atropos, the root during processing of which the Atropos was elected,
+// and the roots whose votes were counted by the election.
+// * When blockNr is -1 the decisive events of the latest block are returned.
+func (s *PublicDAGChainAPI) GetDecisiveEvents(ctx context.Context, blockNr rpc.BlockNumber) (map[string]interface{}, error) {
+	atropos, decisive, roots, err := s.b.GetDecisiveEvents(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"atropos":  eventIDToHex(atropos),
+		"decisive": eventIDToHex(decisive),
+		"roots":    eventIDsToHex(roots),
+	}, nil
+}
+
+// GetBlock returns the DAG specifics of the block: its Atropos, the confirmed events,
+// the internal transactions and the indexes of the skipped transactions.
+// An error is returned if the events of the block are pruned.
+// If includeSkipped is true, the skipped transactions are returned along with the reasons why they were skipped,
+// the reason is null if the block cannot be re-executed.
+// * When blockNr is -1 the latest block is returned.
+func (s *PublicDAGChainAPI) GetBlock(ctx context.Context, blockNr rpc.BlockNumber, includeSkipped bool) (map[string]interface{}, error) {
+	number, block, err := s.b.GetDagBlock(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", blockNr)
+	}
+
+	events := make([]map[string]interface{}, len(block.Events))
+	for i, id := range block.Events {
+		events[i] = map[string]interface{}{
+			"id": eventIDToHex(id),
+		}
+		// the events may be pruned after the block was read
+		e, err := s.b.GetEventPayload(ctx, id.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			events[i]["creator"] = hexutil.Uint64(e.Creator())
+			events[i]["lamport"] = hexutil.Uint64(e.Lamport())
+			events[i]["txCount"] = hexutil.Uint64(len(e.Txs()))
+		}
+	}
+	skippedTxs := make([]hexutil.Uint64, len(block.SkippedTxs))
+	for i, txIdx := range block.SkippedTxs {
+		skippedTxs[i] = hexutil.Uint64(txIdx)
+	}
+	fields := map[string]interface{}{
+		"number":      hexutil.Uint64(number),
+		"hash":        common.Hash(block.Atropos),
+		"atropos":     eventIDToHex(block.Atropos),
+		"timestamp":   hexutil.Uint64(block.Time),
+		"stateRoot":   common.Hash(block.Root),
+		"events":      events,
+		"internalTxs": block.InternalTxs,
+		"txs":         block.Txs,
+		"skippedTxs":  skippedTxs,
+	}
+	if !includeSkipped {
+		return fields, nil
+	}
+
+	txs, reasons, err := s.b.GetSkippedTxs(ctx, number, true)
+	if err != nil {
+		return nil, err
+	}
+	skipped := make([]map[string]interface{}, len(txs))
+	for i, tx := range txs {
+		skipped[i] = map[string]interface{}{
+			"index":  skippedTxs[i],
+			"hash":   tx.Hash(),
+			"reason": nil,
+		}
+		if reasons != nil {
+			skipped[i]["reason"] = reasons[i].Error()
+		}
+	}
+	fields["skippedTransactions"] = skipped
+	return fields, nil
+}
+
+// GetTransactionFinality returns the lifecycle of the transaction: the events of the transaction epoch which include it,
+// the event which counted it, the Atropos and the block which finalized it, and the time to finality
+// (the block time minus the creation time of the counting event, in nanoseconds).
+// The counting event is found by the transactions index, the other events are found by a scan of the epoch events,
+// which is limited in the number of events and in time, eventsTruncated is true if the limit is reached.
+// An error is returned if the events of the transaction block are pruned.
+// Returns nil if the transaction isn't found.
+func (s *PublicDAGChainAPI) GetTransactionFinality(ctx context.Context, txHash common.Hash) (map[string]interface{}, error) {
+	position, err := s.b.GetTxPosition(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if position == nil {
+		return nil, nil
+	}
+	_, block, err := s.b.GetDagBlock(ctx, rpc.BlockNumber(position.Block))
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", position.Block)
+	}
+	var counted *inter.EventPayload
+	if !position.Event.IsZero() {
+		counted, err = s.b.GetEventPayload(ctx, position.Event.Hex())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the tx may be included by the events of other blocks of the epoch, but it's counted only once
+	epoch := block.Atropos.Epoch()
+	if !position.Event.IsZero() {
+		epoch = position.Event.Epoch()
+	}
+	scanCtx, cancel := context.WithTimeout(ctx, finalityScanTimeout)
+	defer cancel()
+	var (
+		events    = make([]map[string]interface{}, 0, 1)
+		scanned   = 0
+		truncated = false
+		found     = false
+	)
+	err = s.b.ForEachEpochEvent(scanCtx, rpc.BlockNumber(epoch), func(e *inter.EventPayload) bool {
+		if scanned >= finalityScanLimit || scanCtx.Err() != nil {
+			truncated = true
+			return false
+		}
+		scanned++
+		if offset := txOffset(e, txHash); offset >= 0 {
+			isCounted := e.ID() == position.Event
+			if isCounted {
+				found = true
+			}
+			events = append(events, s.finalityEvent(ctx, e, offset, isCounted))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !found && counted != nil {
+		// the counting event isn't reached by the scan
+		events = append(events, s.finalityEvent(ctx, counted, int(position.EventOffset), true))
+	} else if !found && !position.Event.IsZero() {
+		// the events are pruned after the block was read
+		events = append(events, map[string]interface{}{
+			"id":      eventIDToHex(position.Event),
+			"counted": true,
+		})
+	}
+
+	fields := map[string]interface{}{
+		"transactionHash": txHash,
+		"blockNumber":     hexutil.Uint64(position.Block),
+		"blockHash":       common.Hash(block.Atropos),
+		"atropos":         eventIDToHex(block.Atropos),
+		"blockTime":       hexutil.Uint64(block.Time),
+		"blockOffset":     hexutil.Uint64(position.BlockOffset),
+		"event":           nil,
+		"eventOffset":     nil,
+		"events":          events,
+		"eventsTruncated": truncated,
+		"timeToFinality":  nil,
+	}
+	if !position.Event.IsZero() {
+		fields["event"] = eventIDToHex(position.Event)
+		fields["eventOffset"] = hexutil.Uint64(position.EventOffset)
+	}
+	if counted != nil {
+		fields["creator"] = hexutil.Uint64(counted.Creator())
+		fields["timeToFinality"] = int64(block.Time) - int64(counted.CreationTime())
+	}
+	return fields, nil
+}
+
+// finalityEvent returns the fields of the event which includes the transaction at the offset.
+func (s *PublicDAGChainAPI) finalityEvent(ctx context.Context, e *inter.EventPayload, offset int, counted bool) map[string]interface{} {
+	fields := map[string]interface{}{
+		"id":           eventIDToHex(e.ID()),
+		"creator":      hexutil.Uint64(e.Creator()),
+		"creationTime": hexutil.Uint64(e.CreationTime()),
+		"medianTime":   hexutil.Uint64(e.MedianTime()),
+		"offset":       hexutil.Uint64(offset),
+		"counted":      counted,
+	}
+	// the arrival time is available only if the events local time index is enabled
+	if _, arrival, err := s.b.GetEventArrival(ctx, e.ID().Hex()); err == nil {
+		fields["arrivalTime"] = hexutil.Uint64(arrival)
+	}
+	return fields
+}
+
+// txOffset returns the index of the transaction in the event, -1 if the event doesn't include it.
+func txOffset(e *inter.EventPayload, txHash common.Hash) int {
+	for i, tx := range e.Txs() {
+		if tx.Hash() == txHash {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetEventArrival returns the local time of the event arrival and its delay relative to the event creation time.
+func (s *PublicDAGChainAPI) GetEventArrival(ctx context.Context, shortEventID string) (map[string]interface{}, error) {
+	e, arrival, err := s.b.GetEventArrival(ctx, shortEventID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("event %s not found", shortEventID)
+	}
+	return map[string]interface{}{
+		"id":           eventIDToHex(e.ID()),
+		"creator":      hexutil.Uint64(e.Creator()),
+		"creationTime": hexutil.Uint64(e.CreationTime()),
+		"arrivalTime":  hexutil.Uint64(arrival),
+		"delay":        int64(arrival) - int64(e.CreationTime()),
+	}, nil
+}
+
+// GetEventArrivalStats returns statistics of the delays between creation and local arrival of the epoch events
+// for every validator. Delays are in nanoseconds.
+// * When epoch is -2 the statistics for latest epoch is returned.
+// * When epoch is -1 the statistics for latest sealed epoch is returned.
+func (s *PublicDAGChainAPI) GetEventArrivalStats(ctx context.Context, epoch rpc.BlockNumber) ([]map[string]interface{}, error) {
+	delays := make(map[idx.ValidatorID][]int64)
+	err := s.b.ForEachEventArrival(ctx, epoch, func(e *inter.Event, arrival inter.Timestamp) bool {
+		delays[e.Creator()] = append(delays[e.Creator()], int64(arrival)-int64(e.CreationTime()))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	validators := make([]idx.ValidatorID, 0, len(delays))
+	for v := range delays {
+		validators = append(validators, v)
+	}
+	sort.Slice(validators, func(i, j int) bool {
+		return validators[i] < validators[j]
+	})
+
+	res := make([]map[string]interface{}, 0, len(validators))
+	for _, v := range validators {
+		vDelays := delays[v]
+		sort.Slice(vDelays, func(i, j int) bool {
+			return vDelays[i] < vDelays[j]
+		})
+		res = append(res, map[string]interface{}{
+			"validatorID": hexutil.Uint64(v),
+			"events":      hexutil.Uint64(len(vDelays)),
+			"minDelay":    vDelays[0],
+			"medianDelay": vDelays[len(vDelays)/2],
+			"maxDelay":    vDelays[len(vDelays)-1],
+		})
+	}
+	return res, nil
+}
+
+// maxEpochStatsRange is the maximum number of epochs returned by GetEpochStatsRange.
+const maxEpochStatsRange = 1000
+
+// GetEpochStats returns the statistics of a sealed epoch, including the statistics of every validator.
+// * When epoch is -1 the statistics for latest sealed epoch is returned.
+func (s *PublicDAGChainAPI) GetEpochStats(ctx context.Context, epoch rpc.BlockNumber) (map[string]interface{}, error) {
+	stats, err := s.b.GetEpochStats(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, errors.New("epoch statistics not found")
+	}
+	return RPCMarshalEpochStats(stats, true), nil
+}
+
+// GetEpochStatsRange returns the statistics of the sealed epochs in the range [from, to], without the validators.
+// The epochs whose statistics aren't stored are omitted.
+// * When to is -1 the range ends with the latest sealed epoch.
+func (s *PublicDAGChainAPI) GetEpochStatsRange(ctx context.Context, from rpc.BlockNumber, to rpc.BlockNumber) ([]map[string]interface{}, error) {
+	if to == rpc.LatestBlockNumber {
+		to = rpc.BlockNumber(s.b.CurrentEpoch(ctx)) - 1
+	}
+	if from < 0 || from > to {
+		return nil, fmt.Errorf("invalid epochs range [%d, %d]", from, to)
+	}
+	if to-from >= maxEpochStatsRange {
+		return nil, fmt.Errorf("epochs range exceeds %d", maxEpochStatsRange)
+	}
+	res := make([]map[string]interface{}, 0, to-from+1)
+	for epoch := from; epoch <= to; epoch++ {
+		stats, err := s.b.GetEpochStats(ctx, epoch)
+		if err != nil {
+			return nil, err
+		}
+		if stats != nil {
+			res = append(res, RPCMarshalEpochStats(stats, false))
+		}
+	}
+	return res, nil
+}
+
+// maxDagSubgraphEvents is the maximum number of events returned by GetSubgraph.
+const maxDagSubgraphEvents = 10000
+
+// DagSubgraphArgs limits the events returned by GetSubgraph.
+type DagSubgraphArgs struct {
+	FromLamport *hexutil.Uint64  `json:"fromLamport"`
+	ToLamport   *hexutil.Uint64  `json:"toLamport"`
+	Validators  []hexutil.Uint64 `json:"validators"`
+}
+
+// GetSubgraph returns the epoch events with their parents, creators, Lamport times, frames, root and Atropos markers,
+// limited by the Lamport range and the creators.
+// The format is either "json" (default) or "dot", the latter is a Graphviz DOT graph.
+// * When epoch is -2 the events of latest epoch are returned.
+// * When epoch is -1 the events of latest sealed epoch are returned.
+func (s *PublicDAGChainAPI) GetSubgraph(ctx context.Context, epoch rpc.BlockNumber, args *DagSubgraphArgs, format *string) (interface{}, error) {
+	if args == nil {
+		args = &DagSubgraphArgs{}
+	}
+	filter := dagdump.Filter{}
+	if args.FromLamport != nil {
+		filter.FromLamport = idx.Lamport(*args.FromLamport)
+	}
+	if args.ToLamport != nil {
+		filter.ToLamport = idx.Lamport(*args.ToLamport)
+	}
+	if args.Validators != nil {
+		filter.Validators = make(map[idx.ValidatorID]bool, len(args.Validators))
+		for _, id := range args.Validators {
+			filter.Validators[idx.ValidatorID(id)] = true
+		}
+	}
+
+	vertices, err := s.b.GetDagSubgraph(ctx, epoch, filter, maxDagSubgraphEvents)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if format == nil || *format == "json" {
+		if err := dagdump.WriteJSON(buf, vertices); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(buf.Bytes()), nil
+	}
+	if *format != "dot" {
+		return nil, fmt.Errorf("unknown format %s", *format)
+	}
+	if err := dagdump.WriteDOT(buf, vertices); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+// RPCMarshalEpochStats converts the epoch statistics to the RPC output.
+// The reward weights are calculated in the same way as the SFC contract does.
+func RPCMarshalEpochStats(stats *inter.EpochStats, inclValidators bool) map[string]interface{} {
+	totalBaseRewardWeight, totalTxRewardWeight := new(big.Int), new(big.Int)
+	validators := make([]map[string]interface{}, 0, len(stats.Validators))
+	for _, v := range stats.Validators {
+		baseRewardWeight, txRewardWeight := rewardWeights(v, stats.Duration())
+		totalBaseRewardWeight.Add(totalBaseRewardWeight, baseRewardWeight)
+		totalTxRewardWeight.Add(totalTxRewardWeight, txRewardWeight)
+		validators = append(validators, map[string]interface{}{
+			"validatorID":      hexutil.Uint64(v.ID),
+			"weight":           (*hexutil.Big)(v.Weight),
+			"uptime":           hexutil.Uint64(v.Uptime),
+			"missedBlocks":     hexutil.Uint64(v.MissedBlocks),
+			"missedPeriod":     hexutil.Uint64(v.MissedPeriod),
+			"originatedFee":    (*hexutil.Big)(v.OriginatedFee),
+			"baseRewardWeight": (*hexutil.Big)(baseRewardWeight),
+			"txRewardWeight":   (*hexutil.Big)(txRewardWeight),
+		})
+	}
+	fields := map[string]interface{}{
+		"epoch":                 hexutil.Uint64(stats.Epoch),
+		"start":                 hexutil.Uint64(stats.Start),
+		"end":                   hexutil.Uint64(stats.End),
+		"firstBlock":            hexutil.Uint64(stats.FirstBlock),
+		"lastBlock":             hexutil.Uint64(stats.LastBlock),
+		"gasUsed":               hexutil.Uint64(stats.GasUsed),
+		"txCount":               hexutil.Uint64(stats.TxCount),
+		"totalFee":              (*hexutil.Big)(stats.TotalFee),
+		"totalBaseRewardWeight": (*hexutil.Big)(totalBaseRewardWeight),
+		"totalTxRewardWeight":   (*hexutil.Big)(totalTxRewardWeight),
+	}
+	if inclValidators {
+		fields["validators"] = validators
+	}
+	return fields
+}
+
+// rewardWeights returns the base and tx reward weights of the validator:
+// baseRewardWeight = stake * uptime^2 / duration^2, txRewardWeight = originatedFee * uptime / duration.
+func rewardWeights(v inter.ValidatorEpochStats, duration inter.Timestamp) (*big.Int, *big.Int) {
+	if duration == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	uptime := v.Uptime
+	if uptime > duration {
+		uptime = duration
+	}
+	bigUptime := new(big.Int).SetUint64(uint64(uptime))
+	bigDuration := new(big.Int).SetUint64(uint64(duration))
+
+	baseRewardWeight := new(big.Int).Mul(v.Weight, bigUptime)
+	baseRewardWeight.Div(baseRewardWeight, bigDuration)
+	baseRewardWeight.Mul(baseRewardWeight, bigUptime)
+	baseRewardWeight.Div(baseRewardWeight, bigDuration)
+
+	txRewardWeight := new(big.Int).Mul(v.OriginatedFee, bigUptime)
+	txRewardWeight.Div(txRewardWeight, bigDuration)
+	return baseRewardWeight, txRewardWeight
+}
+
 // GetEpochStats returns epoch statistics.
 // * When epoch is -2 the statistics for latest epoch is returned.
 // * When epoch is -1 the statistics for latest sealed epoch is returned.
 func (s *PublicBlockChainAPI) GetEpochStats(ctx context.Context, requestedEpoch rpc.BlockNumber) (map[string]interface{}, error) {
-	log.Warn("GetEpochStats API call is deprecated. Consider retrieving data from SFC v3 contract.")
+	log.Warn("GetEpochStats API call is deprecated. Consider retrieving data from SFC v3 contract or dag_getEpochStats.")
+	stats, err := s.b.GetEpochStats(ctx, requestedEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if stats != nil {
+		fields := RPCMarshalEpochStats(stats, false)
+		return map[string]interface{}{
+			"epoch":                 fields["epoch"],
+			"start":                 fields["start"],
+			"end":                   fields["end"],
+			"totalFee":              fields["totalFee"],
+			"totalBaseRewardWeight": fields["totalBaseRewardWeight"],
+			"totalTxRewardWeight":   fields["totalTxRewardWeight"],
+		}, nil
+	}
+	// the statistics of the epochs sealed before they were stored
 	if requestedEpoch != rpc.LatestBlockNumber && requestedEpoch != rpc.BlockNumber(s.b.CurrentEpoch(ctx))-1 {
-		return nil, errors.New("getEpochStats API call doesn't support retrieving previous sealed epochs")
+		return nil, errors.New("epoch statistics not found")
 	}
 	start, end := s.b.SealedEpochTiming(ctx)
 	return map[string]interface{}{
@@ -0,0 +1,221 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// arrivalsBackend serves the local arrival times of events, the rest of Backend isn't implemented.
+type arrivalsBackend struct {
+	Backend
+	events   []*inter.Event
+	arrivals []inter.Timestamp
+}
+
+func (b *arrivalsBackend) ForEachEventArrival(ctx context.Context, epoch rpc.BlockNumber, onEvent func(e *inter.Event, arrival inter.Timestamp) bool) error {
+	for i, e := range b.events {
+		if !onEvent(e, b.arrivals[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+func (b *arrivalsBackend) add(creator idx.ValidatorID, seq idx.Event, created, arrived inter.Timestamp) {
+	me := inter.MutableEventPayload{}
+	me.SetEpoch(1)
+	me.SetCreator(creator)
+	me.SetSeq(seq)
+	me.SetCreationTime(created)
+	b.events = append(b.events, &me.Build().Event)
+	b.arrivals = append(b.arrivals, arrived)
+}
+
+func TestPublicDAGChainAPI_GetEventArrivalStats(t *testing.T) {
+	require := require.New(t)
+
+	b := new(arrivalsBackend)
+	// validator 2 has delays 30, 10, 50, 20 (unordered)
+	b.add(2, 1, 1000, 1030)
+	b.add(2, 2, 2000, 2010)
+	b.add(1, 1, 1000, 1005)
+	b.add(2, 3, 3000, 3050)
+	b.add(2, 4, 4000, 4020)
+	// validator 3 has a single event, which arrived before it was created according to the local clock
+	b.add(3, 1, 1000, 990)
+
+	api := NewPublicDAGChainAPI(b)
+	stats, err := api.GetEventArrivalStats(context.Background(), rpc.LatestBlockNumber)
+	require.NoError(err)
+	require.Equal([]map[string]interface{}{
+		{
+			"validatorID": hexutil.Uint64(1),
+			"events":      hexutil.Uint64(1),
+			"minDelay":    int64(5),
+			"medianDelay": int64(5),
+			"maxDelay":    int64(5),
+		},
+		{
+			"validatorID": hexutil.Uint64(2),
+			"events":      hexutil.Uint64(4),
+			"minDelay":    int64(10),
+			"medianDelay": int64(30),
+			"maxDelay":    int64(50),
+		},
+		{
+			"validatorID": hexutil.Uint64(3),
+			"events":      hexutil.Uint64(1),
+			"minDelay":    int64(-10),
+			"medianDelay": int64(-10),
+			"maxDelay":    int64(-10),
+		},
+	}, stats)
+
+	// no events
+	stats, err = NewPublicDAGChainAPI(new(arrivalsBackend)).GetEventArrivalStats(context.Background(), rpc.LatestBlockNumber)
+	require.NoError(err)
+	require.Empty(stats)
+}
+
+// finalityBackend serves the events of a single epoch and a single block, the rest of Backend isn't implemented.
+type finalityBackend struct {
+	Backend
+	epoch    idx.Epoch
+	events   []*inter.EventPayload
+	block    *inter.Block
+	position *TxPosition
+}
+
+func (b *finalityBackend) GetTxPosition(ctx context.Context, txHash common.Hash) (*TxPosition, error) {
+	return b.position, nil
+}
+
+func (b *finalityBackend) GetDagBlock(ctx context.Context, number rpc.BlockNumber) (idx.Block, *inter.Block, error) {
+	return b.position.Block, b.block, nil
+}
+
+func (b *finalityBackend) ForEachEpochEvent(ctx context.Context, epoch rpc.BlockNumber, onEvent func(event *inter.EventPayload) bool) error {
+	if idx.Epoch(epoch) != b.epoch {
+		return nil
+	}
+	for _, e := range b.events {
+		if !onEvent(e) {
+			break
+		}
+	}
+	return nil
+}
+
+func (b *finalityBackend) GetEventPayload(ctx context.Context, shortEventID string) (*inter.EventPayload, error) {
+	for _, e := range b.events {
+		if e.ID().Hex() == shortEventID {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *finalityBackend) GetEventArrival(ctx context.Context, shortEventID string) (*inter.Event, inter.Timestamp, error) {
+	return nil, 0, errors.New("events local time index is disabled")
+}
+
+func (b *finalityBackend) add(creator idx.ValidatorID, created inter.Timestamp, txs ...*types.Transaction) *inter.EventPayload {
+	me := inter.MutableEventPayload{}
+	me.SetEpoch(b.epoch)
+	me.SetCreator(creator)
+	me.SetSeq(1)
+	me.SetCreationTime(created)
+	me.SetTxs(txs)
+	e := me.Build()
+	b.events = append(b.events, e)
+	return e
+}
+
+func TestPublicDAGChainAPI_GetTransactionFinality(t *testing.T) {
+	require := require.New(t)
+
+	tx := types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	other := types.NewTransaction(1, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	b := &finalityBackend{epoch: 2}
+	// the tx is included by the event of an earlier block, and by 2 events of the finalizing block
+	early := b.add(1, 100, tx)
+	b.add(2, 150, other)
+	counted := b.add(3, 200, other, tx)
+	late := b.add(4, 300, tx)
+	atropos := b.add(5, 400)
+	b.block = &inter.Block{
+		Time:    1000,
+		Atropos: atropos.ID(),
+		Events:  hash.Events{counted.ID(), late.ID(), atropos.ID()},
+	}
+	b.position = &TxPosition{
+		Block:       10,
+		Event:       counted.ID(),
+		EventOffset: 1,
+	}
+
+	api := NewPublicDAGChainAPI(b)
+	res, err := api.GetTransactionFinality(context.Background(), tx.Hash())
+	require.NoError(err)
+	event := func(e *inter.EventPayload, offset uint64, isCounted bool) map[string]interface{} {
+		return map[string]interface{}{
+			"id":           eventIDToHex(e.ID()),
+			"creator":      hexutil.Uint64(e.Creator()),
+			"creationTime": hexutil.Uint64(e.CreationTime()),
+			"medianTime":   hexutil.Uint64(e.MedianTime()),
+			"offset":       hexutil.Uint64(offset),
+			"counted":      isCounted,
+		}
+	}
+	require.Equal([]map[string]interface{}{
+		event(early, 0, false),
+		event(counted, 1, true),
+		event(late, 0, false),
+	}, res["events"])
+	require.Equal(false, res["eventsTruncated"])
+	require.Equal(eventIDToHex(counted.ID()), res["event"])
+	require.Equal(hexutil.Uint64(3), res["creator"])
+	require.Equal(int64(800), res["timeToFinality"])
+
+	// the scan is limited, the counting event is found by the index anyway
+	defer func(limit int) {
+		finalityScanLimit = limit
+	}(finalityScanLimit)
+	finalityScanLimit = 2
+	res, err = api.GetTransactionFinality(context.Background(), tx.Hash())
+	require.NoError(err)
+	require.Equal([]map[string]interface{}{
+		event(early, 0, false),
+		event(counted, 1, true),
+	}, res["events"])
+	require.Equal(true, res["eventsTruncated"])
+	require.Equal(int64(800), res["timeToFinality"])
+
+	// the events of the epoch are pruned
+	b.events = nil
+	res, err = api.GetTransactionFinality(context.Background(), tx.Hash())
+	require.NoError(err)
+	require.Equal([]map[string]interface{}{
+		{"id": eventIDToHex(counted.ID()), "counted": true},
+	}, res["events"])
+	require.Nil(res["timeToFinality"])
+
+	// unknown tx
+	b.position = nil
+	res, err = api.GetTransactionFinality(context.Background(), tx.Hash())
+	require.NoError(err)
+	require.Nil(res)
+}
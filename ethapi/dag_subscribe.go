@@ -0,0 +1,207 @@
+package ethapi
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+const (
+	newEventsQueueSize  = 4096
+	newEpochsQueueSize  = 16
+	newAtroposQueueSize = 1024
+)
+
+// notificationQueue decouples the sender of the notifications from the subscriber connection.
+// The notifications are sent by a separate goroutine, so a slow subscriber doesn't block the feed,
+// which may be sent under the engine lock. The notifications are dropped if the queue is full,
+// the subscriber is notified about the dropped ones by a gap notification once the queue has room.
+type notificationQueue struct {
+	queue chan interface{}
+	quit  chan struct{}
+	// dropped is the number of the dropped notifications which aren't reported yet
+	dropped uint64
+}
+
+// NotificationsGap is sent in place of the notifications which are dropped because the subscriber lags behind.
+type NotificationsGap struct {
+	Dropped hexutil.Uint64 `json:"dropped"`
+}
+
+// notifierFunc returns the function which sends the notification to the RPC subscriber.
+func notifierFunc(notifier *rpc.Notifier, id rpc.ID) func(interface{}) error {
+	return func(data interface{}) error {
+		return notifier.Notify(id, data)
+	}
+}
+
+func newNotificationQueue(notify func(interface{}) error, size int) *notificationQueue {
+	q := &notificationQueue{
+		queue: make(chan interface{}, size),
+		quit:  make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case data := <-q.queue:
+				_ = notify(data)
+			case <-q.quit:
+				return
+			}
+		}
+	}()
+	return q
+}
+
+// push queues the notification, returns false if it's dropped because the subscriber lags behind.
+// The gap notification about the previously dropped notifications is queued first.
+// Not safe for concurrent use.
+func (q *notificationQueue) push(data interface{}) bool {
+	if q.dropped != 0 {
+		select {
+		case q.queue <- NotificationsGap{Dropped: hexutil.Uint64(q.dropped)}:
+			q.dropped = 0
+		default:
+			q.dropped++
+			return false
+		}
+	}
+	select {
+	case q.queue <- data:
+		return true
+	default:
+		q.dropped++
+		return false
+	}
+}
+
+// stop stops sending the queued notifications.
+func (q *notificationQueue) stop() {
+	close(q.quit)
+}
+
+// NewEvents sends a notification with the event header each time an event is connected,
+// either emitted locally or received from peers. The notifications are dropped if the subscriber lags behind,
+// the number of the dropped ones is sent in a gap notification.
+func (s *PublicDAGChainAPI) NewEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan *inter.EventPayload, 128)
+		eventsSub := s.b.SubscribeNewEventsNotify(events)
+		queue := newNotificationQueue(notifierFunc(notifier, rpcSub.ID), newEventsQueueSize)
+		defer queue.stop()
+
+		for {
+			select {
+			case e := <-events:
+				queue.push(RPCMarshalEventHeader(e))
+			case <-rpcSub.Err():
+				eventsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				eventsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// NewEpochs sends a notification each time an epoch is sealed,
+// along with the validators and the rules of the next epoch.
+func (s *PublicDAGChainAPI) NewEpochs(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		epochs := make(chan SealedEpochNotify, 4)
+		epochsSub := s.b.SubscribeSealedEpochsNotify(epochs)
+		queue := newNotificationQueue(notifierFunc(notifier, rpcSub.ID), newEpochsQueueSize)
+		defer queue.stop()
+
+		for {
+			select {
+			case n := <-epochs:
+				queue.push(RPCMarshalSealedEpoch(n))
+			case <-rpcSub.Err():
+				epochsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				epochsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// NewAtropos sends a notification each time a block is decided,
+// with the block index, its Atropos and the confirmed events. The skipped blocks aren't notified.
+func (s *PublicDAGChainAPI) NewAtropos(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		blocks := make(chan AtroposNotify, 16)
+		blocksSub := s.b.SubscribeNewAtroposNotify(blocks)
+		queue := newNotificationQueue(notifierFunc(notifier, rpcSub.ID), newAtroposQueueSize)
+		defer queue.stop()
+
+		for {
+			select {
+			case n := <-blocks:
+				queue.push(map[string]interface{}{
+					"number":    hexutil.Uint64(n.Block),
+					"hash":      common.Hash(n.Atropos),
+					"atropos":   eventIDToHex(n.Atropos),
+					"timestamp": hexutil.Uint64(n.Time),
+					"events":    eventIDsToHex(n.Events),
+				})
+			case <-rpcSub.Err():
+				blocksSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				blocksSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// RPCMarshalSealedEpoch converts the sealed epoch notification to the RPC output.
+func RPCMarshalSealedEpoch(n SealedEpochNotify) map[string]interface{} {
+	validators := make([]map[string]interface{}, 0, n.Validators.Len())
+	for _, id := range n.Validators.SortedIDs() {
+		validators = append(validators, map[string]interface{}{
+			"validatorID": hexutil.Uint64(id),
+			"weight":      hexutil.Uint64(n.Validators.Get(id)),
+		})
+	}
+	return map[string]interface{}{
+		"epoch":      hexutil.Uint64(n.Epoch),
+		"validators": validators,
+		"rules":      n.Rules,
+	}
+}
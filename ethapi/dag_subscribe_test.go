@@ -0,0 +1,99 @@
+package ethapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	notify "github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// eventsFeedBackend serves the connected events feed, the rest of Backend isn't implemented.
+type eventsFeedBackend struct {
+	Backend
+	feed       notify.Feed
+	subscribed chan struct{}
+}
+
+func (b *eventsFeedBackend) SubscribeNewEventsNotify(ch chan<- *inter.EventPayload) notify.Subscription {
+	sub := b.feed.Subscribe(ch)
+	b.subscribed <- struct{}{}
+	return sub
+}
+
+func TestNotificationQueue(t *testing.T) {
+	require := require.New(t)
+
+	const size = 4
+	started := make(chan interface{})
+	release := make(chan struct{})
+	q := newNotificationQueue(func(data interface{}) error {
+		started <- data
+		<-release
+		return nil
+	}, size)
+	defer q.stop()
+
+	// the subscriber is blocked by the first notification
+	require.True(q.push(0))
+	require.Equal(0, <-started)
+	for i := 1; i <= size; i++ {
+		require.True(q.push(i))
+	}
+	// the queue is full, the sender isn't blocked
+	require.False(q.push(size + 1))
+	require.False(q.push(size + 2))
+
+	close(release)
+	for i := 1; i <= size; i++ {
+		require.Equal(i, <-started)
+	}
+	// the dropped notifications are reported before the next one
+	require.True(q.push(size + 3))
+	require.Equal(NotificationsGap{Dropped: 2}, <-started)
+	require.Equal(size+3, <-started)
+	select {
+	case data := <-started:
+		t.Fatalf("unexpected notification %v", data)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestPublicDAGChainAPI_NewEvents(t *testing.T) {
+	require := require.New(t)
+
+	b := &eventsFeedBackend{subscribed: make(chan struct{}, 1)}
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.NoError(server.RegisterName("dag", NewPublicDAGChainAPI(b)))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan map[string]interface{})
+	sub, err := client.Subscribe(context.Background(), "dag", ch, "newEvents")
+	require.NoError(err)
+	defer sub.Unsubscribe()
+	<-b.subscribed
+
+	me := inter.MutableEventPayload{}
+	me.SetEpoch(1)
+	me.SetSeq(1)
+	me.SetCreator(2)
+	e := me.Build()
+	b.feed.Send(e)
+
+	select {
+	case header := <-ch:
+		require.Equal(hexutil.Encode(e.ID().Bytes()), header["id"])
+		require.Equal("0x2", header["creator"])
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event notification isn't received")
+	}
+}
@@ -0,0 +1,278 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+const (
+	// defaultTraceTimeout is the amount of time a single transaction can execute
+	// by default before being forcefully aborted.
+	defaultTraceTimeout = 5 * time.Second
+)
+
+// TraceConfig holds extra parameters to trace functions.
+type TraceConfig struct {
+	*vm.LogConfig
+	Tracer  *string
+	Timeout *string
+}
+
+// TraceCallConfig is the config for traceCall API. It holds one more
+// field to override the state and the block header for tracing.
+type TraceCallConfig struct {
+	TraceConfig
+	StateOverrides  *map[common.Address]account
+	HeaderOverrides *HeaderOverrides
+}
+
+// timeout returns the trace timeout requested by the config, or the default one.
+func (config *TraceConfig) timeout() (time.Duration, error) {
+	if config == nil || config.Timeout == nil {
+		return defaultTraceTimeout, nil
+	}
+	return time.ParseDuration(*config.Timeout)
+}
+
+// txTraceResult is the result of a single transaction trace.
+type txTraceResult struct {
+	TxHash   common.Hash `json:"txHash"`             // transaction hash
+	Internal bool        `json:"internal,omitempty"` // true for internal (unsigned) transactions
+	Result   interface{} `json:"result,omitempty"`   // Trace results produced by the tracer
+	Error    string      `json:"error,omitempty"`    // Trace failure produced by the tracer
+}
+
+// txTracer collects a trace of a single transaction execution.
+type txTracer struct {
+	tracer vm.Tracer
+	cancel context.CancelFunc
+}
+
+// newTxTracer creates a tracer according to the config.
+// If a JavaScript based tracer is requested, its execution time is limited by the timeout starting from now.
+func newTxTracer(ctx context.Context, config *TraceConfig) (*txTracer, error) {
+	if config == nil || config.Tracer == nil {
+		var logConfig *vm.LogConfig
+		if config != nil {
+			logConfig = config.LogConfig
+		}
+		return &txTracer{
+			tracer: vm.NewStructLogger(logConfig),
+			cancel: func() {},
+		}, nil
+	}
+
+	timeout, err := config.timeout()
+	if err != nil {
+		return nil, err
+	}
+	tracer, err := tracers.New(*config.Tracer)
+	if err != nil {
+		return nil, err
+	}
+	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+	go func() {
+		<-deadlineCtx.Done()
+		if deadlineCtx.Err() == context.DeadlineExceeded {
+			tracer.Stop(errors.New("execution timeout"))
+		}
+	}()
+	return &txTracer{
+		tracer: tracer,
+		cancel: cancel,
+	}, nil
+}
+
+// vmConfig returns VM config for the traced transaction execution.
+func (t *txTracer) vmConfig() vm.Config {
+	return tracingVMConfig(t.tracer)
+}
+
+// tracingVMConfig returns VM config with the tracer enabled.
+// Opera's VM config is extended rather than replaced to keep the stateful precompiled contracts.
+func tracingVMConfig(tracer vm.Tracer) vm.Config {
+	cfg := opera.DefaultVMConfig
+	cfg.Debug = true
+	cfg.Tracer = tracer
+	return cfg
+}
+
+// result returns the collected trace and releases the tracer resources.
+func (t *txTracer) result(gasUsed uint64, failed bool) (interface{}, error) {
+	defer t.cancel()
+
+	switch tracer := t.tracer.(type) {
+	case *vm.StructLogger:
+		return &ExecutionResult{
+			Gas:         gasUsed,
+			Failed:      failed,
+			ReturnValue: fmt.Sprintf("%x", tracer.Output()),
+			StructLogs:  FormatLogs(tracer.StructLogs()),
+		}, nil
+
+	case *tracers.Tracer:
+		return tracer.GetResult()
+
+	default:
+		return nil, fmt.Errorf("bad tracer type %T", tracer)
+	}
+}
+
+// TraceTransaction returns the structured logs created during the execution of EVM
+// and returns them as a JSON object.
+// Internal transactions may be traced too.
+func (api *PublicDebugAPI) TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (interface{}, error) {
+	tx, blockNumber, index, err := api.b.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s not found", hash.String())
+	}
+
+	var (
+		tracer   *txTracer
+		result   interface{}
+		traceErr error
+	)
+	// the tracer isn't released by its result if the replay fails
+	defer func() {
+		if tracer != nil {
+			tracer.cancel()
+		}
+	}()
+	err = api.b.ReplayBlock(ctx, rpc.BlockNumber(blockNumber), func(replayed ReplayedTx) vm.Config {
+		if uint64(replayed.Index) != index {
+			return opera.DefaultVMConfig
+		}
+		tracer, traceErr = newTxTracer(ctx, config)
+		if traceErr != nil {
+			return opera.DefaultVMConfig
+		}
+		return tracer.vmConfig()
+	}, func(replayed ReplayedTx, receipt *types.Receipt) bool {
+		if uint64(replayed.Index) != index {
+			return true
+		}
+		if tracer != nil {
+			result, traceErr = tracer.result(receipt.GasUsed, receipt.Status == types.ReceiptStatusFailed)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if traceErr != nil {
+		return nil, traceErr
+	}
+	if tracer == nil {
+		return nil, fmt.Errorf("transaction %s not found in block %d", hash.String(), blockNumber)
+	}
+	return result, nil
+}
+
+// TraceBlockByNumber returns the structured logs created during the execution of
+// EVM and returns them as a JSON object.
+// Internal transactions of the block are traced as well and marked as internal.
+func (api *PublicDebugAPI) TraceBlockByNumber(ctx context.Context, number rpc.BlockNumber, config *TraceConfig) ([]*txTraceResult, error) {
+	var (
+		results []*txTraceResult
+		tracer  *txTracer
+		current *txTraceResult
+	)
+	// the tracer isn't released by its result if the replay fails
+	defer func() {
+		if tracer != nil {
+			tracer.cancel()
+		}
+	}()
+	err := api.b.ReplayBlock(ctx, number, func(replayed ReplayedTx) vm.Config {
+		current = &txTraceResult{
+			TxHash:   replayed.Tx.Hash(),
+			Internal: replayed.Internal,
+		}
+		results = append(results, current)
+
+		var err error
+		tracer, err = newTxTracer(ctx, config)
+		if err != nil {
+			current.Error = err.Error()
+			return opera.DefaultVMConfig
+		}
+		return tracer.vmConfig()
+	}, func(replayed ReplayedTx, receipt *types.Receipt) bool {
+		if tracer == nil {
+			return true
+		}
+		res, err := tracer.result(receipt.GasUsed, receipt.Status == types.ReceiptStatusFailed)
+		if err != nil {
+			current.Error = err.Error()
+		} else {
+			current.Result = res
+		}
+		tracer = nil
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// TraceBlockByHash returns the structured logs created during the execution of
+// EVM and returns them as a JSON object.
+func (api *PublicDebugAPI) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceConfig) ([]*txTraceResult, error) {
+	header, err := api.b.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %s not found", hash.String())
+	}
+	return api.TraceBlockByNumber(ctx, rpc.BlockNumber(header.Number.Uint64()), config)
+}
+
+// TraceCall lets you trace a given eth_call. It collects the structured logs
+// created during the execution of EVM if the given transaction was added on
+// top of the provided block and returns them as a JSON object.
+// The state and the block header may be overridden.
+// The call execution is aborted after the trace timeout.
+func (api *PublicDebugAPI) TraceCall(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
+	var (
+		traceConfig     *TraceConfig
+		accounts        map[common.Address]account
+		headerOverrides *HeaderOverrides
+	)
+	if config != nil {
+		traceConfig = &config.TraceConfig
+		if config.StateOverrides != nil {
+			accounts = *config.StateOverrides
+		}
+		headerOverrides = config.HeaderOverrides
+	}
+
+	timeout, err := traceConfig.timeout()
+	if err != nil {
+		return nil, err
+	}
+	tracer, err := newTxTracer(ctx, traceConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer tracer.cancel()
+	result, err := DoCall(ctx, api.b, args, blockNrOrHash, accounts, headerOverrides, tracer.vmConfig(), timeout, api.b.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	return tracer.result(result.UsedGas, result.Failed())
+}
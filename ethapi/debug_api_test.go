@@ -0,0 +1,133 @@
+package ethapi
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+var (
+	// headerFieldsCode returns the block number, the timestamp and the coinbase
+	headerFieldsCode = common.FromHex("0x43600052426020524160405260606000f3")
+	// infiniteLoopCode jumps to itself until the gas is exhausted
+	infiniteLoopCode = common.FromHex("0x5b600056")
+)
+
+func TestHeaderOverrides_Apply(t *testing.T) {
+	require := require.New(t)
+
+	header := newEvmBackend().header
+	var nilOverrides *HeaderOverrides
+	require.Equal(header, nilOverrides.Apply(header))
+	require.Equal(header, (&HeaderOverrides{}).Apply(header))
+
+	number := hexutil.Big(*big.NewInt(200))
+	time := hexutil.Uint64(1700000000)
+	gasLimit := hexutil.Uint64(1000)
+	coinbase := common.Address{0xc1}
+	overridden := (&HeaderOverrides{
+		Number:   &number,
+		Time:     &time,
+		GasLimit: &gasLimit,
+		Coinbase: &coinbase,
+	}).Apply(header)
+	require.Equal(int64(200), overridden.Number.Int64())
+	require.Equal(inter.FromUnix(1700000000), overridden.Time)
+	require.Equal(uint64(1000), overridden.GasLimit)
+	require.Equal(coinbase, overridden.Coinbase)
+	// the original header isn't modified
+	require.Equal(int64(100), header.Number.Int64())
+	require.Equal(common.Address{0xc0}, header.Coinbase)
+}
+
+func TestPublicDebugAPI_TraceCall(t *testing.T) {
+	require := require.New(t)
+
+	b := newEvmBackend()
+	contract := common.Address{0xcc}
+	b.state.SetCode(contract, headerFieldsCode)
+	looping := common.Address{0x11}
+	b.state.SetCode(looping, infiniteLoopCode)
+	api := NewPublicDebugAPI(b)
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	headerFields := func(number int64, time uint64, coinbase common.Address) string {
+		var out [96]byte
+		big.NewInt(number).FillBytes(out[:32])
+		new(big.Int).SetUint64(time).FillBytes(out[32:64])
+		copy(out[64+12:], coinbase.Bytes())
+		return common.Bytes2Hex(out[:])
+	}
+
+	// struct logs of the call
+	res, err := api.TraceCall(context.Background(), CallArgs{To: &contract}, latest, nil)
+	require.NoError(err)
+	result := res.(*ExecutionResult)
+	require.False(result.Failed)
+	require.Equal(headerFields(100, 1600000000, common.Address{0xc0}), result.ReturnValue)
+	require.Len(result.StructLogs, 12)
+	require.Equal(vm.NUMBER.String(), result.StructLogs[0].Op)
+	require.Equal(vm.RETURN.String(), result.StructLogs[11].Op)
+
+	// overridden header
+	number := hexutil.Big(*big.NewInt(200))
+	time := hexutil.Uint64(1700000000)
+	coinbase := common.Address{0xc1}
+	res, err = api.TraceCall(context.Background(), CallArgs{To: &contract}, latest, &TraceCallConfig{
+		HeaderOverrides: &HeaderOverrides{
+			Number:   &number,
+			Time:     &time,
+			Coinbase: &coinbase,
+		},
+	})
+	require.NoError(err)
+	require.Equal(headerFields(200, 1700000000, coinbase), res.(*ExecutionResult).ReturnValue)
+
+	// overridden state
+	res, err = api.TraceCall(context.Background(), CallArgs{To: &looping}, latest, &TraceCallConfig{
+		StateOverrides: &map[common.Address]account{
+			looping: {Code: (*hexutil.Bytes)(&headerFieldsCode)},
+		},
+	})
+	require.NoError(err)
+	require.Equal(headerFields(100, 1600000000, common.Address{0xc0}), res.(*ExecutionResult).ReturnValue)
+
+	// the call is aborted after the requested timeout
+	timeout := "10ms"
+	_, err = api.TraceCall(context.Background(), CallArgs{To: &looping}, latest, &TraceCallConfig{
+		TraceConfig: TraceConfig{
+			LogConfig: &vm.LogConfig{DisableMemory: true, DisableStack: true, DisableStorage: true},
+			Timeout:   &timeout,
+		},
+	})
+	require.EqualError(err, "execution aborted (timeout = 10ms)")
+
+	// invalid timeout
+	timeout = "ten"
+	_, err = api.TraceCall(context.Background(), CallArgs{To: &contract}, latest, &TraceCallConfig{
+		TraceConfig: TraceConfig{Timeout: &timeout},
+	})
+	require.Error(err)
+}
+
+func TestTxTracer_result(t *testing.T) {
+	require := require.New(t)
+
+	cancelled := false
+	tracer := &txTracer{
+		tracer: vm.NewJSONLogger(nil, ioutil.Discard),
+		cancel: func() { cancelled = true },
+	}
+	_, err := tracer.result(21000, false)
+	require.EqualError(err, "bad tracer type *vm.JSONLogger")
+	require.True(cancelled)
+}
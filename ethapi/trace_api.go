@@ -0,0 +1,239 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/txtrace"
+)
+
+// PublicTxTraceAPI provides an API to access Parity-style transaction traces.
+// Traces of internal transactions are included and marked as internal.
+// The blocks are re-executed to trace them, so the API isn't exposed over HTTP and WebSocket
+// unless the trace namespace is enabled explicitly, the same as the debug API.
+type PublicTxTraceAPI struct {
+	b Backend
+}
+
+// NewPublicTxTraceAPI creates a new Parity-style trace API.
+func NewPublicTxTraceAPI(b Backend) *PublicTxTraceAPI {
+	return &PublicTxTraceAPI{b}
+}
+
+// TraceFilterLimits are the server limits of trace_filter.
+type TraceFilterLimits struct {
+	// RangeLimit is the maximum number of blocks in the range, zero means no limit
+	RangeLimit uint64
+	// ResultsLimit is the maximum number of returned traces, zero means no limit
+	ResultsLimit int
+	// Timeout is the maximum duration of a query, zero means no limit
+	Timeout time.Duration
+}
+
+// TraceFilterArgs represents the arguments of trace_filter.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *hexutil.Uint64  `json:"after"`
+	Count       *hexutil.Uint64  `json:"count"`
+}
+
+// Block returns traces of all the transactions of the block.
+func (s *PublicTxTraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]txtrace.ActionTrace, error) {
+	header, err := s.b.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	return s.traceBlock(ctx, header.Number.Uint64(), header.Hash, -1)
+}
+
+// Transaction returns traces of the transaction.
+func (s *PublicTxTraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]txtrace.ActionTrace, error) {
+	tx, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s not found", hash.String())
+	}
+	header, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %d not found", blockNumber)
+	}
+	return s.traceBlock(ctx, blockNumber, header.Hash, int(index))
+}
+
+// Filter returns traces of the blocks range which match the filter.
+// Addresses filter requires the trace index to be enabled.
+// The blocks range, the number of results and the query duration are limited by the server.
+func (s *PublicTxTraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]txtrace.ActionTrace, error) {
+	limits := s.b.TraceFilterLimits()
+	if limits.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+
+	latest := idx.Block(s.b.CurrentBlock().NumberU64())
+	from, to := latest, latest
+	if args.FromBlock != nil && *args.FromBlock >= 0 {
+		from = idx.Block(*args.FromBlock)
+	}
+	if args.ToBlock != nil && *args.ToBlock >= 0 {
+		to = idx.Block(*args.ToBlock)
+	}
+	if from > to {
+		return nil, errors.New("fromBlock is greater than toBlock")
+	}
+	if to > latest {
+		to = latest
+	}
+	if from == 0 {
+		// genesis block cannot be traced
+		from = 1
+	}
+	if limits.RangeLimit != 0 && to >= from && uint64(to-from) >= limits.RangeLimit {
+		return nil, fmt.Errorf("block range is greater than the limit of %d blocks", limits.RangeLimit)
+	}
+
+	var (
+		after  uint64
+		count  = ^uint64(0)
+		traces = make([]txtrace.ActionTrace, 0)
+	)
+	if args.After != nil {
+		after = uint64(*args.After)
+	}
+	if args.Count != nil {
+		count = uint64(*args.Count)
+	}
+	fromAddrs := addressesSet(args.FromAddress)
+	toAddrs := addressesSet(args.ToAddress)
+
+	filterBlock := func(n idx.Block) (bool, error) {
+		header, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(n))
+		if err != nil {
+			return false, err
+		}
+		if header == nil {
+			return true, nil
+		}
+		blockTraces, err := s.traceBlock(ctx, uint64(n), header.Hash, -1)
+		if err != nil {
+			return false, err
+		}
+		for _, trace := range blockTraces {
+			if len(fromAddrs) != 0 && !fromAddrs[trace.Sender()] {
+				continue
+			}
+			if len(toAddrs) != 0 && !toAddrs[trace.Recipient()] {
+				continue
+			}
+			if after != 0 {
+				after--
+				continue
+			}
+			if uint64(len(traces)) >= count {
+				return false, nil
+			}
+			if limits.ResultsLimit != 0 && len(traces) >= limits.ResultsLimit {
+				return false, fmt.Errorf("query returned more than %d results", limits.ResultsLimit)
+			}
+			traces = append(traces, trace)
+		}
+		return true, nil
+	}
+
+	if len(fromAddrs) == 0 && len(toAddrs) == 0 {
+		for n := from; n <= to; n++ {
+			if next, err := filterBlock(n); !next || err != nil {
+				return traces, err
+			}
+		}
+		return traces, nil
+	}
+
+	// use the index to find the blocks with the addresses
+	blocksSet := make(map[idx.Block]bool)
+	for _, addrs := range []map[common.Address]bool{fromAddrs, toAddrs} {
+		for addr := range addrs {
+			err := s.b.ForEachTraceAddressBlock(ctx, addr, from, to, func(n idx.Block) bool {
+				blocksSet[n] = true
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	blocks := make([]idx.Block, 0, len(blocksSet))
+	for n := range blocksSet {
+		blocks = append(blocks, n)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i] < blocks[j]
+	})
+	for _, n := range blocks {
+		if next, err := filterBlock(n); !next || err != nil {
+			return traces, err
+		}
+	}
+	return traces, nil
+}
+
+// traceBlock replays the block and returns traces of its transactions.
+// If txIndex isn't negative, then only the transaction with the index is traced.
+func (s *PublicTxTraceAPI) traceBlock(ctx context.Context, blockNumber uint64, blockHash common.Hash, txIndex int) ([]txtrace.ActionTrace, error) {
+	traces := make([]txtrace.ActionTrace, 0)
+	var tracer *txtrace.CallTracer
+	err := s.b.ReplayBlock(ctx, rpc.BlockNumber(blockNumber), func(tx ReplayedTx) vm.Config {
+		if txIndex >= 0 && tx.Index != txIndex {
+			return opera.DefaultVMConfig
+		}
+		tracer = txtrace.NewCallTracer()
+		return tracingVMConfig(tracer)
+	}, func(tx ReplayedTx, receipt *types.Receipt) bool {
+		if txIndex >= 0 && tx.Index != txIndex {
+			return true
+		}
+		traces = append(traces, txtrace.Flatten(tracer.Root(), txtrace.TxContext{
+			BlockHash:   blockHash,
+			BlockNumber: blockNumber,
+			TxHash:      tx.Tx.Hash(),
+			TxPosition:  uint64(tx.Index),
+			Internal:    tx.Internal,
+		})...)
+		return txIndex < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+func addressesSet(addrs []common.Address) map[common.Address]bool {
+	set := make(map[common.Address]bool, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = true
+	}
+	return set
+}
@@ -0,0 +1,73 @@
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+)
+
+// slowReplayBackend has the blocks whose replay doesn't end until the context is done,
+// the rest of Backend isn't implemented.
+type slowReplayBackend struct {
+	Backend
+	latest   int64
+	limits   TraceFilterLimits
+	replayed int
+}
+
+func (b *slowReplayBackend) CurrentBlock() *evmcore.EvmBlock {
+	return &evmcore.EvmBlock{EvmHeader: evmcore.EvmHeader{Number: big.NewInt(b.latest)}}
+}
+
+func (b *slowReplayBackend) TraceFilterLimits() TraceFilterLimits {
+	return b.limits
+}
+
+func (b *slowReplayBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*evmcore.EvmHeader, error) {
+	return &evmcore.EvmHeader{Number: big.NewInt(int64(number))}, nil
+}
+
+func (b *slowReplayBackend) ReplayBlock(ctx context.Context, number rpc.BlockNumber, onTxStart func(tx ReplayedTx) vm.Config, onTxEnd func(tx ReplayedTx, receipt *types.Receipt) bool) error {
+	b.replayed++
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestPublicTxTraceAPI_FilterLimits(t *testing.T) {
+	require := require.New(t)
+
+	b := &slowReplayBackend{
+		latest: 5000,
+		limits: TraceFilterLimits{
+			RangeLimit: 100,
+			Timeout:    10 * time.Millisecond,
+		},
+	}
+	api := NewPublicTxTraceAPI(b)
+	blockNumber := func(n int64) *rpc.BlockNumber {
+		bn := rpc.BlockNumber(n)
+		return &bn
+	}
+
+	// the whole history isn't replayed
+	_, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: blockNumber(0)})
+	require.EqualError(err, "block range is greater than the limit of 100 blocks")
+	_, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: blockNumber(4000), ToBlock: blockNumber(4100)})
+	require.EqualError(err, "block range is greater than the limit of 100 blocks")
+	require.Equal(0, b.replayed)
+
+	// the range within the limit is replayed until the deadline
+	start := time.Now()
+	_, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: blockNumber(4000), ToBlock: blockNumber(4099)})
+	require.Equal(context.DeadlineExceeded, err)
+	require.Equal(1, b.replayed)
+	require.Less(int64(time.Since(start)), int64(time.Second))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/inter/pos"
 	"github.com/Fantom-foundation/lachesis-base/lachesis"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
@@ -18,6 +19,8 @@ import (
 	"github.com/Fantom-foundation/go-opera/inter/drivertype"
 	"github.com/Fantom-foundation/go-opera/opera"
 	"github.com/Fantom-foundation/go-opera/opera/genesis"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/netinit"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/sfc"
 )
 
 // ApplyGenesis writes initial state.
@@ -74,6 +77,7 @@ func (s *Store) applyEpoch0Genesis(g opera.Genesis) (evmBlock *evmcore.EvmBlock,
 			}
 		}
 
+		s.SetBlockBloom(blockIdx, receiptsBloom(block.Receipts))
 		s.SetBlock(blockIdx, &inter.Block{
 			Time:        block.Time,
 			Atropos:     block.Atropos,
@@ -145,16 +149,33 @@ func (s *Store) applyEpoch1Genesis(blockProc BlockProc, g opera.Genesis) (err er
 	evmProcessor := blockProc.EVMModule.Start(blockCtx, statedb, evmStateReader, func(l *types.Log) {
 		txListener.OnNewLog(l)
 		sfcapi.OnNewLog(s.sfcapi, l)
-	}, es.Rules)
+	}, es.Rules, opera.DefaultVMConfig)
+
+	// A genesis may be a snapshot of an already initialized network (see ExportGenesis),
+	// its state is applied as is, without the network initialization and the epoch sealing transactions
+	snapshot := isGenesisSnapshot(statedb)
 
 	// Execute genesis-internal transactions
-	genesisInternalTxs := blockProc.GenesisTxTransactor.PopInternalTxs(blockCtx, bs, es, sealing, statedb)
-	evmProcessor.Execute(genesisInternalTxs, true)
+	genesisInternalTxs := types.Transactions{}
+	if !snapshot {
+		genesisInternalTxs = blockProc.GenesisTxTransactor.PopInternalTxs(blockCtx, bs, es, sealing, statedb)
+		evmProcessor.Execute(genesisInternalTxs, true)
+	}
 	bs = txListener.Finalize()
+	if snapshot {
+		bs.NextValidatorProfiles, err = snapshotValidatorProfiles(statedb, evmBlock0.Header(), evmStateReader, g)
+		if err != nil {
+			return err
+		}
+		txListener.Update(bs, es)
+	}
 
 	// Execute pre-internal transactions
-	preInternalTxs := blockProc.PreTxTransactor.PopInternalTxs(blockCtx, bs, es, sealing, statedb)
-	evmProcessor.Execute(preInternalTxs, true)
+	preInternalTxs := types.Transactions{}
+	if !snapshot {
+		preInternalTxs = blockProc.PreTxTransactor.PopInternalTxs(blockCtx, bs, es, sealing, statedb)
+		evmProcessor.Execute(preInternalTxs, true)
+	}
 	bs = txListener.Finalize()
 
 	// Seal epoch if requested
@@ -165,8 +186,11 @@ func (s *Store) applyEpoch1Genesis(blockProc BlockProc, g opera.Genesis) (err er
 	}
 
 	// Execute post-internal transactions
-	internalTxs := blockProc.PostTxTransactor.PopInternalTxs(blockCtx, bs, es, sealing, statedb)
-	evmProcessor.Execute(internalTxs, true)
+	internalTxs := types.Transactions{}
+	if !snapshot {
+		internalTxs = blockProc.PostTxTransactor.PopInternalTxs(blockCtx, bs, es, sealing, statedb)
+		evmProcessor.Execute(internalTxs, true)
+	}
 	evmBlock, skippedTxs, receipts := evmProcessor.Finalize()
 	for _, r := range receipts {
 		if r.Status == 0 {
@@ -181,6 +205,7 @@ func (s *Store) applyEpoch1Genesis(blockProc BlockProc, g opera.Genesis) (err er
 
 	bs.LastBlock = blockCtx
 	s.SetBlockEpochState(bs, es)
+	s.SetHistoryBlockEpochState(es.Epoch, bs, es)
 
 	prettyHash := func(root common.Hash, g opera.Genesis) hash.Event {
 		e := inter.MutableEventPayload{}
@@ -220,7 +245,8 @@ func (s *Store) applyEpoch1Genesis(blockProc BlockProc, g opera.Genesis) (err er
 		}
 	}
 
-	s.commitEVM()
+	s.commitEVM(true)
+	s.SetBlockBloom(blockCtx.Idx, types.CreateBloom(receipts))
 	s.SetBlock(blockCtx.Idx, block)
 	s.SetBlockIndex(genesisAtropos, blockCtx.Idx)
 	s.SetGenesisBlockIndex(blockCtx.Idx)
@@ -230,3 +256,33 @@ func (s *Store) applyEpoch1Genesis(blockProc BlockProc, g opera.Genesis) (err er
 
 	return nil
 }
+
+// isGenesisSnapshot returns true if the genesis state is a snapshot of an already initialized network.
+// The network initializer contract is self-destructed after the network initialization.
+func isGenesisSnapshot(statedb *state.StateDB) bool {
+	return len(statedb.GetCode(netinit.ContractAddress)) == 0 && len(statedb.GetCode(sfc.ContractAddress)) != 0
+}
+
+// snapshotValidatorProfiles reads the weights of the genesis validators from the SFC state.
+// Validators which aren't active or have no stake are skipped, the same way as the Driver does.
+func snapshotValidatorProfiles(statedb *state.StateDB, header *evmcore.EvmHeader, reader evmcore.DummyChain, g opera.Genesis) (blockproc.ValidatorProfiles, error) {
+	sfcState := newSfcStateReader(statedb, header, reader, g.Rules)
+	profiles := make(blockproc.ValidatorProfiles, len(g.Validators))
+	for _, v := range g.Validators {
+		sfcValidator, err := sfcState.Validator(v.ID)
+		if err != nil {
+			return nil, err
+		}
+		if sfcValidator.Status.Sign() != 0 || sfcValidator.ReceivedStake.Sign() == 0 {
+			continue
+		}
+		profiles[v.ID] = drivertype.Validator{
+			Weight: sfcValidator.ReceivedStake,
+			PubKey: v.PubKey,
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, errors.New("genesis snapshot has no active validators")
+	}
+	return profiles, nil
+}
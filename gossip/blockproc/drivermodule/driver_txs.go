@@ -12,7 +12,6 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
-	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/drivertype"
 	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
 	"github.com/Fantom-foundation/go-opera/opera"
@@ -109,13 +108,6 @@ func (p *DriverTxGenesisTransactor) PopInternalTxs(_ blockproc.BlockCtx, _ block
 	return internalTxs
 }
 
-func maxBlockIdx(a, b idx.Block) idx.Block {
-	if a > b {
-		return a
-	}
-	return b
-}
-
 func (p *DriverTxPreTransactor) PopInternalTxs(block blockproc.BlockCtx, bs blockproc.BlockState, es blockproc.EpochState, sealing bool, statedb *state.StateDB) types.Transactions {
 	buildTx := internalTxBuilder(statedb)
 	internalTxs := make(types.Transactions, 0, 8)
@@ -136,17 +128,7 @@ func (p *DriverTxPreTransactor) PopInternalTxs(block blockproc.BlockCtx, bs bloc
 		metrics := make([]drivercall.ValidatorEpochMetric, es.Validators.Len())
 		for oldValIdx := idx.Validator(0); oldValIdx < es.Validators.Len(); oldValIdx++ {
 			info := bs.ValidatorStates[oldValIdx]
-			// forgive downtime if below BlockMissedSlack
-			missed := opera.BlocksMissed{
-				BlocksNum: maxBlockIdx(block.Idx, info.LastBlock) - info.LastBlock,
-				Period:    inter.MaxTimestamp(block.Time, info.LastOnlineTime) - info.LastOnlineTime,
-			}
-			uptime := info.Uptime
-			if missed.BlocksNum <= es.Rules.Economy.BlockMissedSlack {
-				missed = opera.BlocksMissed{}
-				prevOnlineTime := inter.MaxTimestamp(info.LastOnlineTime, es.EpochStart)
-				uptime += inter.MaxTimestamp(block.Time, prevOnlineTime) - prevOnlineTime
-			}
+			uptime, missed := blockproc.ValidatorEpochUptime(block, es, info)
 			metrics[oldValIdx] = drivercall.ValidatorEpochMetric{
 				Missed:          missed,
 				Uptime:          uptime,
@@ -4,6 +4,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/inter"
@@ -28,6 +29,7 @@ type TxTransactor interface {
 type SealerProcessor interface {
 	EpochSealing() bool
 	SealEpoch() (BlockState, EpochState)
+	EpochStats() inter.EpochStats
 	Update(bs BlockState, es EpochState)
 }
 
@@ -50,5 +52,5 @@ type EVMProcessor interface {
 }
 
 type EVM interface {
-	Start(block BlockCtx, statedb *state.StateDB, reader evmcore.DummyChain, onNewLog func(*types.Log), net opera.Rules) EVMProcessor
+	Start(block BlockCtx, statedb *state.StateDB, reader evmcore.DummyChain, onNewLog func(*types.Log), net opera.Rules, vmCfg vm.Config) EVMProcessor
 }
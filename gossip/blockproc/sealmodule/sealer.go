@@ -8,6 +8,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/lachesis"
 
 	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
+	"github.com/Fantom-foundation/go-opera/inter"
 )
 
 type OperaEpochsSealerModule struct{}
@@ -41,6 +42,36 @@ func (p *OperaEpochsSealer) Update(bs blockproc.BlockState, es blockproc.EpochSt
 	p.bs, p.es = bs, es
 }
 
+// EpochStats returns the statistics of the validators of the epoch being sealed.
+// It's called before the epoch is sealed.
+func (s *OperaEpochsSealer) EpochStats() inter.EpochStats {
+	validators := make([]inter.ValidatorEpochStats, s.es.Validators.Len())
+	for valIdx := idx.Validator(0); valIdx < s.es.Validators.Len(); valIdx++ {
+		info := s.bs.ValidatorStates[valIdx]
+		valID := s.es.Validators.GetID(valIdx)
+		uptime, missed := blockproc.ValidatorEpochUptime(s.block, s.es, info)
+		validators[valIdx] = inter.ValidatorEpochStats{
+			ID:           valID,
+			Weight:       new(big.Int),
+			Uptime:       uptime,
+			MissedBlocks: missed.BlocksNum,
+			MissedPeriod: missed.Period,
+			// the total originated fee, the fee at the epoch start is subtracted when the stats are stored
+			OriginatedFee: new(big.Int).Set(info.Originated),
+		}
+		if profile, ok := s.es.ValidatorProfiles[valID]; ok && profile.Weight != nil {
+			validators[valIdx].Weight.Set(profile.Weight)
+		}
+	}
+	return inter.EpochStats{
+		Epoch:      s.es.Epoch,
+		Start:      s.es.EpochStart,
+		End:        s.block.Time,
+		LastBlock:  s.block.Idx,
+		Validators: validators,
+	}
+}
+
 // SealEpoch is called after pre-internal transactions are executed
 func (s *OperaEpochsSealer) SealEpoch() (blockproc.BlockState, blockproc.EpochState) {
 	// Select new validators
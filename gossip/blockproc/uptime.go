@@ -0,0 +1,31 @@
+package blockproc
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// ValidatorEpochUptime returns the uptime and the missed blocks of the validator in the epoch sealed by the block.
+// The downtime is forgiven if it's below BlockMissedSlack.
+func ValidatorEpochUptime(block BlockCtx, es EpochState, info ValidatorBlockState) (inter.Timestamp, opera.BlocksMissed) {
+	missed := opera.BlocksMissed{
+		BlocksNum: maxBlockIdx(block.Idx, info.LastBlock) - info.LastBlock,
+		Period:    inter.MaxTimestamp(block.Time, info.LastOnlineTime) - info.LastOnlineTime,
+	}
+	uptime := info.Uptime
+	if missed.BlocksNum <= es.Rules.Economy.BlockMissedSlack {
+		missed = opera.BlocksMissed{}
+		prevOnlineTime := inter.MaxTimestamp(info.LastOnlineTime, es.EpochStart)
+		uptime += inter.MaxTimestamp(block.Time, prevOnlineTime) - prevOnlineTime
+	}
+	return uptime, missed
+}
+
+func maxBlockIdx(a, b idx.Block) idx.Block {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,104 @@
+package gossip
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/bitutil"
+)
+
+const (
+	// bloomIndexingPeriod is the period of checking whether the next bloom bits section is complete.
+	bloomIndexingPeriod = time.Minute
+
+	// bloomServiceThreads is the number of goroutines serving the bloom bits retrievals of all the filters.
+	bloomServiceThreads = 16
+
+	// bloomFilterThreads is the number of goroutines multiplexing the retrievals of a filter onto the service goroutines.
+	bloomFilterThreads = 3
+
+	// bloomRetrievalBatch is the maximum number of bloom bits retrievals to serve in a single batch.
+	bloomRetrievalBatch = 16
+
+	// bloomRetrievalWait is the maximum time to wait for enough retrievals to fill a batch.
+	bloomRetrievalWait = time.Duration(0)
+)
+
+// bloomIndexingLoop indexes the bloom bits sections once all their blocks are processed.
+func (s *Service) bloomIndexingLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(bloomIndexingPeriod)
+	defer ticker.Stop()
+	for {
+		for s.indexNextBloomSection() {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// indexNextBloomSection indexes the next bloom bits section.
+// Returns false if the section isn't complete yet.
+func (s *Service) indexNextBloomSection() bool {
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+	if s.stopped {
+		return false
+	}
+
+	start := time.Now()
+	section := s.store.GetBloomSections()
+	if !s.store.IndexNextBloomSection() {
+		return false
+	}
+	s.Log.Debug("Indexed bloom bits section", "section", section, "t", time.Since(start))
+
+	if s.store.IsCommitNeeded(false) {
+		s.blockProcWg.Wait()
+		if err := s.store.Commit(); err != nil {
+			s.Log.Error("Failed to commit DBs", "err", err)
+			return false
+		}
+	}
+	return true
+}
+
+// startBloomHandlers starts the goroutines which serve the bloom bits retrievals of the filters.
+func (s *Service) startBloomHandlers() {
+	for i := 0; i < bloomServiceThreads; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for {
+				select {
+				case <-s.done:
+					return
+
+				case request := <-s.bloomRequests:
+					task := <-request
+					task.Bitsets = make([][]byte, len(task.Sections))
+					for i, section := range task.Sections {
+						compVector, err := s.store.GetBloomBits(task.Bit, section)
+						if err != nil {
+							task.Error = err
+							continue
+						}
+						if blob, err := bitutil.DecompressBytes(compVector, int(BloomBitsSectionSize/8)); err == nil {
+							task.Bitsets[i] = blob
+						} else {
+							task.Error = err
+						}
+					}
+					request <- task
+				}
+			}
+		}()
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/Fantom-foundation/go-opera/ethapi"
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
 	"github.com/Fantom-foundation/go-opera/gossip/blockproc/verwatcher"
@@ -25,10 +26,15 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/sfcapi"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/txtrace"
 )
 
 // GetConsensusCallbacks returns single (for Service) callback instance.
 func (s *Service) GetConsensusCallbacks() lachesis.ConsensusCallbacks {
+	var decisiveEvents func() *DecisiveEvents
+	if s.config.DecisiveEventsIndex {
+		decisiveEvents = s.collectDecisiveEvents
+	}
 	return lachesis.ConsensusCallbacks{
 		BeginBlock: consensusCallbackBeginBlockFn(
 			s.blockProcTasks,
@@ -37,9 +43,11 @@ func (s *Service) GetConsensusCallbacks() lachesis.ConsensusCallbacks {
 			s.store,
 			s.blockProcModules,
 			s.config.TxIndex,
+			s.config.TraceIndex,
 			&s.feed,
 			s.emitter,
 			s.verWatcher,
+			decisiveEvents,
 			nil,
 		),
 	}
@@ -55,9 +63,11 @@ func consensusCallbackBeginBlockFn(
 	store *Store,
 	blockProc BlockProc,
 	txIndex bool,
+	traceIndex bool,
 	feed *ServiceFeed,
 	emitter *emitter.Emitter,
 	verWatcher *verwatcher.VerWarcher,
+	decisiveEvents func() *DecisiveEvents,
 	onBlockEnd func(block *inter.Block, preInternalReceipts, internalReceipts, externalReceipts types.Receipts),
 ) lachesis.BeginBlockFn {
 	return func(cBlock *lachesis.Block) lachesis.BlockCallbacks {
@@ -81,6 +91,12 @@ func consensusCallbackBeginBlockFn(
 
 		eventProcessor := blockProc.EventsModule.Start(bs, es)
 
+		// collect the events which have decided the block while they are being processed
+		var decided *DecisiveEvents
+		if decisiveEvents != nil {
+			decided = decisiveEvents()
+		}
+
 		atroposTime := bs.LastBlock.Time + 1
 		atroposDegenerate := true
 		confirmedEvents := make(hash.OrderedEvents, 0, 3*es.Validators.Len())
@@ -145,7 +161,14 @@ func consensusCallbackBeginBlockFn(
 					}
 					sfcapi.OnNewLog(store.sfcapi, l)
 				}
-				evmProcessor := blockProc.EVMModule.Start(blockCtx, statedb, evmStateReader, onNewLogAll, es.Rules)
+				vmCfg := opera.DefaultVMConfig
+				var traceAddrs *txtrace.AddressesTracer
+				if traceIndex {
+					traceAddrs = txtrace.NewAddressesTracer()
+					vmCfg.Debug = true
+					vmCfg.Tracer = traceAddrs
+				}
+				evmProcessor := blockProc.EVMModule.Start(blockCtx, statedb, evmStateReader, onNewLogAll, es.Rules, vmCfg)
 
 				// Execute pre-internal transactions
 				preInternalTxs := blockProc.PreTxTransactor.PopInternalTxs(blockCtx, bs, es, sealing, statedb)
@@ -158,8 +181,11 @@ func consensusCallbackBeginBlockFn(
 				}
 
 				// Seal epoch if requested
+				blockEpoch := es.Epoch
+				var sealedStats inter.EpochStats
 				if sealing {
 					sealer.Update(bs, es)
+					sealedStats = sealer.EpochStats()
 					bs, es = sealer.SealEpoch() // TODO: refactor to not mutate the bs, it is unclear
 					store.SetBlockEpochState(bs, es)
 					newValidators = es.Validators
@@ -239,6 +265,7 @@ func consensusCallbackBeginBlockFn(
 						}
 						txListener.OnNewReceipt(evmBlock.Transactions[i], r, creator)
 					}
+					store.SetBlockBloom(blockCtx.Idx, types.CreateBloom(allReceipts))
 					bs = txListener.Finalize() // TODO: refactor to not mutate the bs
 					bs.FinalizedStateRoot = block.Root
 					// At this point, block state is finalized
@@ -261,14 +288,39 @@ func consensusCallbackBeginBlockFn(
 					for _, tx := range append(preInternalTxs, internalTxs...) {
 						store.evm.SetTx(tx.Hash(), tx)
 					}
+					if traceAddrs != nil {
+						store.evm.SetTraceAddresses(blockCtx.Idx, traceAddrs.Addresses())
+					}
 
 					store.SetBlock(blockCtx.Idx, block)
 					store.SetBlockIndex(block.Atropos, blockCtx.Idx)
+					if decided != nil {
+						store.SetDecisiveEvents(blockCtx.Idx, decided)
+					}
 					bs.LastBlock = blockCtx
 					store.SetBlockEpochState(bs, es)
+					// the sealing block is accounted in the sealed epoch
+					store.addEpochBlockStats(blockEpoch, blockCtx.Idx, evmBlock.GasUsed, len(evmBlock.Transactions))
+					if sealing {
+						store.sealEpochStats(sealedStats)
+						store.SetHistoryBlockEpochState(es.Epoch, bs, es)
+					}
 
 					// Notify about new block and txs
 					if feed != nil {
+						feed.newAtropos.Send(ethapi.AtroposNotify{
+							Block:   blockCtx.Idx,
+							Time:    blockCtx.Time,
+							Atropos: blockCtx.Atropos,
+							Events:  block.Events,
+						})
+						if sealing {
+							feed.newSealedEpoch.Send(ethapi.SealedEpochNotify{
+								Epoch:      blockEpoch,
+								Validators: es.Validators,
+								Rules:      es.Rules,
+							})
+						}
 						feed.newBlock.Send(evmcore.ChainHeadNotify{Block: evmBlock})
 						feed.newTxs.Send(core.NewTxsEvent{Txs: evmBlock.Transactions})
 						var logs []*types.Log
@@ -284,7 +336,8 @@ func consensusCallbackBeginBlockFn(
 						onBlockEnd(block, preInternalReceipts, internalReceipts, externalReceipts)
 					}
 
-					store.commitEVM()
+					// the states of the sealed epochs are never pruned
+					store.commitEVM(sealing)
 
 					log.Info("New block", "index", blockCtx.Idx, "atropos", block.Atropos, "gas_used",
 						evmBlock.GasUsed, "skipped_txs", len(block.SkippedTxs), "txs", len(evmBlock.Transactions), "t", time.Since(start))
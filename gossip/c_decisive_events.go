@@ -0,0 +1,76 @@
+package gossip
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+var errEventNotFound = errors.New("event not found")
+
+// collectDecisiveEvents returns events which have decided a block.
+// Must be called only from the consensus callbacks, i.e. during processing of an event by the consensus engine,
+// because the block is decided by the event which is being processed.
+// Returns nil if the events cannot be collected, the decisive events of the block aren't indexed then.
+func (s *Service) collectDecisiveEvents() *DecisiveEvents {
+	decisive := s.processingEvent
+	if decisive == nil {
+		return nil
+	}
+	de := &DecisiveEvents{
+		Decisive: decisive.ID(),
+	}
+	if decisive.Frame() <= 1 {
+		return de
+	}
+
+	// find roots of the previous frame which are observed by the decisive root
+	votersFrame := decisive.Frame() - 1
+	voters := make(hash.OrderedEvents, 0, s.store.GetValidators().Len())
+	visited := hash.EventsSet{}
+	stack := hash.EventsStack{}
+	stack.PushAll(decisive.Parents())
+	for next := stack.Pop(); next != nil; next = stack.Pop() {
+		if visited.Contains(*next) {
+			continue
+		}
+		visited.Add(*next)
+		e := s.store.GetEvent(*next)
+		if e == nil {
+			s.Log.Error("Failed to collect decisive events", "event", next.String(), "err", errEventNotFound)
+			return nil
+		}
+		if e.Frame() < votersFrame {
+			continue
+		}
+		if e.Frame() == votersFrame {
+			root, err := s.isRoot(e.SelfParent(), e.Frame())
+			if err != nil {
+				s.Log.Error("Failed to collect decisive events", "event", e.ID().String(), "err", err)
+				return nil
+			}
+			if root && s.dagIndexer.ForklessCause(decisive.ID(), e.ID()) {
+				voters = append(voters, e.ID())
+			}
+		}
+		stack.PushAll(e.Parents())
+	}
+	sort.Sort(voters)
+	de.Roots = hash.Events(voters)
+
+	return de
+}
+
+// isRoot returns true if event with the self-parent and frame is a root
+func (s *Service) isRoot(selfParent *hash.Event, frame idx.Frame) (bool, error) {
+	if selfParent == nil {
+		return true, nil
+	}
+	parent := s.store.GetEvent(*selfParent)
+	if parent == nil {
+		return false, errEventNotFound
+	}
+	return parent.Frame() < frame, nil
+}
@@ -0,0 +1,153 @@
+package gossip
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+	"github.com/Fantom-foundation/go-opera/vecmt"
+)
+
+// decisiveEventsTestDAG is the DAG of 4 validators with equal stakes, the quorum is 3 validators:
+//
+//	frame 1:  a1   b1   c1   d1
+//	frame 2:  a2   b2   c2   d2   (roots, each one observes all the roots of frame 1)
+//	frame 2:  a3   b3   c3        (a3 observes a2, b2, c2, d2; b3 and c3 observe a2, b2, c2)
+//	frame 3:  a4                  (observes a3, b3, c3)
+//
+// a2, b2 and c2 are forkless caused by a4, d2 isn't because b3 and c3 don't observe it.
+type decisiveEventsTestDAG struct {
+	store   *Store
+	svc     *Service
+	events  map[string]*inter.EventPayload
+	creator map[byte]idx.ValidatorID
+}
+
+func newDecisiveEventsTestDAG(t *testing.T) *decisiveEventsTestDAG {
+	genStore := makegenesis.FakeGenesisStore(genesisStakers, utils.ToFtm(genesisBalance), utils.ToFtm(genesisStake))
+	store := NewMemStore()
+	_, err := store.ApplyGenesis(DefaultBlockProc(genStore.GetGenesis()), genStore.GetGenesis())
+	require.NoError(t, err)
+
+	d := &decisiveEventsTestDAG{
+		store:   store,
+		events:  make(map[string]*inter.EventPayload),
+		creator: make(map[byte]idx.ValidatorID),
+	}
+	validators := pos.NewBuilder()
+	for i, name := range []byte("abcd") {
+		d.creator[name] = idx.ValidatorID(i + 1)
+		validators.Set(idx.ValidatorID(i+1), 1)
+	}
+	dagIndexer := vecmt.NewIndex(func(err error) { panic(err) }, vecmt.LiteConfig())
+	dagIndexer.Reset(validators.Build(), memorydb.New(), func(id hash.Event) dag.Event {
+		return store.GetEvent(id)
+	})
+	d.svc = &Service{
+		store:      store,
+		dagIndexer: dagIndexer,
+		Instance:   logger.MakeInstance(),
+	}
+
+	d.add("a1", 1)
+	d.add("b1", 1)
+	d.add("c1", 1)
+	d.add("d1", 1)
+	d.add("a2", 2, "a1", "b1", "c1", "d1")
+	d.add("b2", 2, "b1", "a1", "c1", "d1")
+	d.add("c2", 2, "c1", "a1", "b1", "d1")
+	d.add("d2", 2, "d1", "a1", "b1", "c1")
+	d.add("a3", 2, "a2", "b2", "c2", "d2")
+	d.add("b3", 2, "b2", "a2", "c2")
+	d.add("c3", 2, "c2", "a2", "b2")
+	d.add("a4", 3, "a3", "b3", "c3")
+	return d
+}
+
+// add indexes the event of the validator named by the first letter, the self-parent goes first.
+func (d *decisiveEventsTestDAG) add(name string, frame idx.Frame, parents ...string) {
+	me := &inter.MutableEventPayload{}
+	me.SetEpoch(d.store.GetEpoch())
+	me.SetCreator(d.creator[name[0]])
+	me.SetFrame(frame)
+	me.SetSeq(1)
+	me.SetLamport(1)
+	ids := make(hash.Events, 0, len(parents))
+	for i, p := range parents {
+		parent := d.events[p]
+		ids = append(ids, parent.ID())
+		if i == 0 {
+			me.SetSeq(parent.Seq() + 1)
+		}
+		if parent.Lamport() >= me.Lamport() {
+			me.SetLamport(parent.Lamport() + 1)
+		}
+	}
+	me.SetParents(ids)
+	me.SetCreationTime(inter.Timestamp(me.Lamport()))
+	e := me.Build()
+	d.store.SetEvent(e)
+	if err := d.svc.dagIndexer.Add(e); err != nil {
+		panic(err)
+	}
+	d.svc.dagIndexer.Flush()
+	d.events[name] = e
+}
+
+func (d *decisiveEventsTestDAG) ids(names ...string) hash.Events {
+	ids := make(hash.OrderedEvents, len(names))
+	for i, name := range names {
+		ids[i] = d.events[name].ID()
+	}
+	sort.Sort(ids)
+	return hash.Events(ids)
+}
+
+func TestService_collectDecisiveEvents(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	d := newDecisiveEventsTestDAG(t)
+	defer d.store.Close()
+
+	// called outside of the consensus callbacks
+	require.Nil(d.svc.collectDecisiveEvents())
+
+	// the roots of frame 1 have no voters
+	d.svc.processingEvent = d.events["a1"]
+	require.Equal(&DecisiveEvents{Decisive: d.events["a1"].ID()}, d.svc.collectDecisiveEvents())
+
+	// the non-roots a3, b3, c3 and the not forkless caused root d2 aren't voters
+	d.svc.processingEvent = d.events["a4"]
+	de := d.svc.collectDecisiveEvents()
+	require.NotNil(de)
+	require.Equal(d.events["a4"].ID(), de.Decisive)
+	require.Equal(d.ids("a2", "b2", "c2"), de.Roots)
+	for _, root := range de.Roots {
+		require.True(d.svc.dagIndexer.ForklessCause(de.Decisive, root))
+	}
+	require.False(d.svc.dagIndexer.ForklessCause(de.Decisive, d.events["d2"].ID()))
+}
+
+func TestService_collectDecisiveEventsWithoutSelfParent(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	d := newDecisiveEventsTestDAG(t)
+	defer d.store.Close()
+
+	// the self-parent of a voters frame event is missing
+	d.store.DelEvent(d.events["c2"].ID())
+	d.svc.processingEvent = d.events["a4"]
+	require.Nil(d.svc.collectDecisiveEvents())
+}
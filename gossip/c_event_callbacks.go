@@ -8,6 +8,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/gossip/dagprocessor"
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/Fantom-foundation/go-opera/eventcheck"
@@ -84,6 +85,10 @@ func (s *Service) processSavedEvent(e *inter.EventPayload, es *blockproc.EpochSt
 	}
 
 	// aBFT processing
+	s.processingEvent = e
+	defer func() {
+		s.processingEvent = nil
+	}()
 	return s.engine.Process(e)
 }
 
@@ -104,8 +109,9 @@ func (s *Service) saveAndProcessEvent(e *inter.EventPayload, es *blockproc.Epoch
 	return nil
 }
 
-// processEvent extends the engine.Process with gossip-specific actions on each event processing
-func (s *Service) processEvent(e *inter.EventPayload) error {
+// processEvent extends the engine.Process with gossip-specific actions on each event processing.
+// arrival is the local time when the event was received from a peer or emitted.
+func (s *Service) processEvent(e *inter.EventPayload, arrival inter.Timestamp) error {
 	// s.engineMu is locked here
 	if s.stopped {
 		return errStopped
@@ -136,6 +142,9 @@ func (s *Service) processEvent(e *inter.EventPayload) error {
 	if err != nil {
 		return err
 	}
+	if s.config.EventLocalTimeIndex {
+		s.store.SetEventLocalTime(e.ID(), arrival)
+	}
 
 	newEpoch := s.store.GetEpoch()
 
@@ -148,19 +157,10 @@ func (s *Service) processEvent(e *inter.EventPayload) error {
 	s.store.SetLastEvent(oldEpoch, e.Creator(), e.ID())
 
 	s.emitter.OnEventConnected(e)
+	s.feed.newEvent.Send(e)
 
 	if newEpoch != oldEpoch {
-		// reset dag indexer
-		s.store.resetEpochStore(newEpoch)
-		es := s.store.getEpochStore(newEpoch)
-		s.dagIndexer.Reset(s.store.GetValidators(), es.table.DagIndex, func(id hash.Event) dag.Event {
-			return s.store.GetEvent(id)
-		})
-		// notify event checkers about new validation data
-		s.gasPowerCheckReader.Ctx.Store(NewGasPowerContext(s.store, s.store.GetValidators(), newEpoch, s.store.GetRules().Economy)) // read gaspower check data from disk
-		s.heavyCheckReader.Addrs.Store(NewEpochPubKeys(s.store, newEpoch))
-		// notify about new epoch
-		s.emitter.OnNewEpoch(s.store.GetValidators(), newEpoch)
+		s.switchEpochTo(newEpoch)
 		s.feed.newEpoch.Send(newEpoch)
 	}
 
@@ -171,6 +171,21 @@ func (s *Service) processEvent(e *inter.EventPayload) error {
 	return nil
 }
 
+// switchEpochTo resets the epoch-dependent data after the epoch was sealed.
+func (s *Service) switchEpochTo(newEpoch idx.Epoch) {
+	// reset dag indexer
+	s.store.resetEpochStore(newEpoch)
+	es := s.store.getEpochStore(newEpoch)
+	s.dagIndexer.Reset(s.store.GetValidators(), es.table.DagIndex, func(id hash.Event) dag.Event {
+		return s.store.GetEvent(id)
+	})
+	// notify event checkers about new validation data
+	s.gasPowerCheckReader.Ctx.Store(NewGasPowerContext(s.store, s.store.GetValidators(), newEpoch, s.store.GetRules().Economy)) // read gaspower check data from disk
+	s.heavyCheckReader.Addrs.Store(NewEpochPubKeys(s.store, newEpoch))
+	// notify about new epoch
+	s.emitter.OnNewEpoch(s.store.GetValidators(), newEpoch)
+}
+
 type uniqueID struct {
 	counter *big.Int
 }
@@ -125,6 +125,8 @@ func (env *testEnv) consensusCallbackBeginBlockFn(
 		env.store,
 		env.blockProcModules,
 		txIndex,
+		false,
+		nil,
 		nil,
 		nil,
 		nil,
@@ -138,6 +140,7 @@ func (env *testEnv) ApplyBlock(spent time.Duration, txs ...*types.Transaction) (
 	env.lastBlockTime = env.lastBlockTime.Add(spent)
 
 	eBuilder := inter.MutableEventPayload{}
+	eBuilder.SetEpoch(env.store.GetEpoch())
 	eBuilder.SetMedianTime(inter.Timestamp(env.lastBlockTime.UnixNano()))
 	eBuilder.SetTxs(txs)
 	event := eBuilder.Build()
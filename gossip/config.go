@@ -9,6 +9,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/gossip/dagstream/streamseeder"
 	"github.com/Fantom-foundation/lachesis-base/gossip/itemsfetcher"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 
 	"github.com/Fantom-foundation/go-opera/eventcheck/heavycheck"
@@ -16,6 +17,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/blockproc/verwatcher"
 	"github.com/Fantom-foundation/go-opera/gossip/emitter"
 	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/gossip/filters"
 	"github.com/Fantom-foundation/go-opera/gossip/gasprice"
 )
 
@@ -45,7 +47,52 @@ type (
 		MaxInitialTxHashesSend   int
 		MaxRandomTxHashesSend    int
 		RandomTxHashesSendPeriod time.Duration
+
+		SnapshotSync SnapshotSyncConfig
+	}
+
+	// SnapshotSyncConfig is config for bootstrapping a fresh node from the EVM state of a recently sealed epoch.
+	// The snapshot is trusted if enough peers report it, so the options which select the peers are security-sensitive.
+	// The SFC API (sfc_* methods) isn't available on a node bootstrapped from a snapshot.
+	SnapshotSyncConfig struct {
+		Enabled bool
+		// MinEpochsLag is the minimum lag behind the peers to download a snapshot instead of the events
+		MinEpochsLag idx.Epoch
+		// Quorum is the minimum number of peers which have to report the same snapshot
+		Quorum int
+		// TrustedPeersOnly limits the snapshot sources to the trusted and static peers configured by the operator.
+		// If it's disabled, any peers are counted, and the snapshot may be forged by an attacker who controls a quorum of the peers
+		TrustedPeersOnly bool
+		// PeersWaitTimeout is the time to wait for the peers before falling back to the events sync
+		PeersWaitTimeout time.Duration
+		RequestTimeout   time.Duration
+		// MaxStateNodesRequest is the maximum number of the state nodes per request
+		MaxStateNodesRequest int
+		// MaxParallelRequests is the maximum number of the state nodes requests in flight
+		MaxParallelRequests int
+		// BloomSize is the size of the bloom filter of the existing state nodes, in MiB
+		BloomSize uint64
+	}
+
+	// EventsPruningConfig is config for deleting the events of the old epochs.
+	// The blocks, transactions and receipts of the pruned epochs are kept.
+	EventsPruningConfig struct {
+		// KeepEpochs is the number of the latest sealed epochs whose events are kept, zero disables the pruning
+		KeepEpochs idx.Epoch
+		// Period is the interval between the pruning rounds
+		Period time.Duration
 	}
+
+	// TraceFilterConfig is config of the trace_filter limits.
+	TraceFilterConfig struct {
+		// RangeLimit is the maximum number of blocks in the range, zero means no limit
+		RangeLimit uint64
+		// ResultsLimit is the maximum number of returned traces, zero means no limit
+		ResultsLimit int
+		// Timeout is the maximum duration of a query, zero means no limit
+		Timeout time.Duration
+	}
+
 	// Config for the gossip service.
 	Config struct {
 		Emitter emitter.Config
@@ -54,6 +101,12 @@ type (
 		TxIndex             bool // Whether to enable indexing transactions and receipts or not
 		DecisiveEventsIndex bool // Whether to enable indexing events which decide blocks or not
 		EventLocalTimeIndex bool // Whether to enable indexing arrival time of events or not
+		// TraceIndex enables indexing addresses of call traces for trace_filter.
+		// The blocks are processed with the EVM tracer then, which slows down the blocks processing.
+		TraceIndex bool
+
+		// Retention policy of the events
+		EventsPruning EventsPruningConfig
 
 		// Protocol options
 		Protocol ProtocolConfig
@@ -63,6 +116,12 @@ type (
 		// Gas Price Oracle options
 		GPO gasprice.Config
 
+		// Limits of the logs filtering API
+		Filter filters.Config
+
+		// Limits of the trace_filter API
+		TraceFilter TraceFilterConfig
+
 		VersionWatcher verwatcher.Config
 
 		// Enables tracking of SHA3 preimages in the VM
@@ -112,6 +171,7 @@ func DefaultConfig() Config {
 
 		TxIndex:             true,
 		DecisiveEventsIndex: false,
+		TraceIndex:          false,
 
 		HeavyCheck: heavycheck.DefaultConfig(),
 
@@ -153,6 +213,22 @@ func DefaultConfig() Config {
 			MaxInitialTxHashesSend:   20000,
 			MaxRandomTxHashesSend:    128,
 			RandomTxHashesSendPeriod: 20 * time.Second,
+			SnapshotSync: SnapshotSyncConfig{
+				Enabled:              false,
+				MinEpochsLag:         10,
+				Quorum:               3,
+				TrustedPeersOnly:     true,
+				PeersWaitTimeout:     1 * time.Minute,
+				RequestTimeout:       10 * time.Second,
+				MaxStateNodesRequest: 384,
+				MaxParallelRequests:  16,
+				BloomSize:            512,
+			},
+		},
+
+		EventsPruning: EventsPruningConfig{
+			KeepEpochs: 0,
+			Period:     1 * time.Minute,
 		},
 
 		GPO: gasprice.Config{
@@ -161,6 +237,14 @@ func DefaultConfig() Config {
 			MaxPrice:   gasprice.DefaultMaxPrice,
 		},
 
+		Filter: filters.DefaultConfig(),
+		// the range limit is lower than the one of eth_getLogs, because every block of the range may be replayed
+		TraceFilter: TraceFilterConfig{
+			RangeLimit:   1000,
+			ResultsLimit: 10000,
+			Timeout:      time.Minute,
+		},
+
 		VersionWatcher: verwatcher.Config{
 			ShutDownIfNotUpgraded:     false,
 			WarningIfNotUpgradedEvery: 5 * time.Second,
@@ -196,6 +280,20 @@ func (c *Config) Validate() error {
 	if c.Protocol.Processor.EventsBufferLimit.Size < protocolMaxMsgSize {
 		return fmt.Errorf("EventsBufferLimit.Size has to be at least %d", protocolMaxMsgSize)
 	}
+	if c.Protocol.SnapshotSync.Enabled {
+		if c.Protocol.SnapshotSync.Quorum < 1 {
+			return fmt.Errorf("SnapshotSync.Quorum has to be at least 1")
+		}
+		if c.Protocol.SnapshotSync.MaxStateNodesRequest < 1 || c.Protocol.SnapshotSync.MaxStateNodesRequest > hardLimitItems {
+			return fmt.Errorf("SnapshotSync.MaxStateNodesRequest has to be in range [1, %d]", hardLimitItems)
+		}
+		if c.Protocol.SnapshotSync.MaxParallelRequests < 1 {
+			return fmt.Errorf("SnapshotSync.MaxParallelRequests has to be at least 1")
+		}
+	}
+	if c.EventsPruning.KeepEpochs != 0 && c.EventsPruning.Period <= 0 {
+		return fmt.Errorf("EventsPruning.Period has to be positive")
+	}
 
 	return nil
 }
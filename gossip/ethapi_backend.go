@@ -12,8 +12,8 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/inter/pos"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -26,9 +26,11 @@ import (
 	"github.com/Fantom-foundation/go-opera/ethapi"
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
 	"github.com/Fantom-foundation/go-opera/gossip/gasprice"
 	"github.com/Fantom-foundation/go-opera/gossip/sfcapi"
 	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/dagdump"
 	"github.com/Fantom-foundation/go-opera/inter/drivertype"
 	"github.com/Fantom-foundation/go-opera/opera"
 	"github.com/Fantom-foundation/go-opera/topicsdb"
@@ -201,6 +203,69 @@ func (b *EthAPIBackend) GetHeads(ctx context.Context, epoch rpc.BlockNumber) (he
 	return
 }
 
+// GetDecisiveEvents returns the Atropos of a block and the events which have decided the block.
+func (b *EthAPIBackend) GetDecisiveEvents(ctx context.Context, number rpc.BlockNumber) (atropos hash.Event, decisive hash.Event, roots hash.Events, err error) {
+	if !b.svc.config.DecisiveEventsIndex {
+		err = errors.New("decisive events index is disabled (enable DecisiveEventsIndex and re-process the DAG)")
+		return
+	}
+	if number == rpc.PendingBlockNumber || number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(b.svc.store.GetLatestBlockIndex())
+	}
+
+	block := b.svc.store.GetBlock(idx.Block(number))
+	if block == nil {
+		err = errors.New("block not found")
+		return
+	}
+	de := b.svc.store.GetDecisiveEvents(idx.Block(number))
+	if de == nil {
+		err = errors.New("decisive events of the block weren't indexed")
+		return
+	}
+
+	return block.Atropos, de.Decisive, de.Roots, nil
+}
+
+// GetDagBlock returns the block with its DAG specifics.
+// ErrEventsPruned is returned if the events of the block are pruned.
+// * When blockNr is -1 the latest block is returned.
+func (b *EthAPIBackend) GetDagBlock(ctx context.Context, number rpc.BlockNumber) (idx.Block, *inter.Block, error) {
+	if number == rpc.PendingBlockNumber || number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(b.svc.store.GetLatestBlockIndex())
+	}
+	if number < 0 {
+		return 0, nil, errors.New("block number is not in range")
+	}
+	block := b.svc.store.GetBlock(idx.Block(number))
+	if block != nil && b.svc.store.isBlockUnlinked(idx.Block(number), block) {
+		return 0, nil, ErrEventsPruned
+	}
+	return idx.Block(number), block, nil
+}
+
+// GetSkippedTxs returns the skipped transactions of the block.
+// If explain is true, the block is re-executed to find the reasons why the transactions were skipped,
+// the reasons are nil if the state of the previous block isn't available anymore.
+func (b *EthAPIBackend) GetSkippedTxs(ctx context.Context, number idx.Block, explain bool) (types.Transactions, []error, error) {
+	block := b.svc.store.GetBlock(number)
+	if block == nil {
+		return nil, nil, errors.New("block not found")
+	}
+	txs, err := b.svc.store.GetSkippedTxs(number, block)
+	if err != nil || !explain {
+		return txs, nil, err
+	}
+	reasons, err := b.svc.store.ExplainSkippedTxs(number, b.svc.blockProcModules.EVMModule)
+	if err == evmstore.ErrHistoricalStateUnavailable {
+		return txs, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return txs, reasons, nil
+}
+
 func (b *EthAPIBackend) epochWithDefault(ctx context.Context, epoch rpc.BlockNumber) (requested idx.Epoch, err error) {
 	current := b.svc.store.GetEpoch()
 
@@ -230,6 +295,61 @@ func (b *EthAPIBackend) ForEachEpochEvent(ctx context.Context, epoch rpc.BlockNu
 	return nil
 }
 
+// GetDagSubgraph returns the epoch events accepted by the filter.
+// * When epoch is -2 the latest epoch is used.
+// * When epoch is -1 the latest sealed epoch is used.
+func (b *EthAPIBackend) GetDagSubgraph(ctx context.Context, epoch rpc.BlockNumber, filter dagdump.Filter, limit int) ([]dagdump.Vertex, error) {
+	requested, err := b.epochWithDefault(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+	return dagdump.Collect(b.svc.store, requested, filter, limit)
+}
+
+// GetEventArrival returns the event header and the local time of the event arrival.
+func (b *EthAPIBackend) GetEventArrival(ctx context.Context, shortEventID string) (*inter.Event, inter.Timestamp, error) {
+	if !b.svc.config.EventLocalTimeIndex {
+		return nil, 0, errors.New("event local time index is disabled (enable EventLocalTimeIndex and re-process the DAG)")
+	}
+	e, err := b.GetEvent(ctx, shortEventID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if e == nil {
+		return nil, 0, nil
+	}
+	arrival := b.svc.store.GetEventLocalTime(e.ID())
+	if arrival == nil {
+		return nil, 0, errors.New("arrival time of the event wasn't indexed")
+	}
+	return e, *arrival, nil
+}
+
+// ForEachEventArrival iterates the local times of arrival of the epoch events.
+// * When epoch is -2 the latest epoch is iterated.
+// * When epoch is -1 the latest sealed epoch is iterated.
+func (b *EthAPIBackend) ForEachEventArrival(ctx context.Context, epoch rpc.BlockNumber, onEvent func(e *inter.Event, arrival inter.Timestamp) bool) error {
+	if !b.svc.config.EventLocalTimeIndex {
+		return errors.New("event local time index is disabled (enable EventLocalTimeIndex and re-process the DAG)")
+	}
+	requested, err := b.epochWithDefault(ctx, epoch)
+	if err != nil {
+		return err
+	}
+
+	b.svc.store.ForEachEventLocalTime(requested, func(id hash.Event, arrival inter.Timestamp) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		e := b.svc.store.GetEvent(id)
+		if e == nil {
+			return true
+		}
+		return onEvent(e, arrival)
+	})
+	return ctx.Err()
+}
+
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, h common.Hash) (*evmcore.EvmBlock, error) {
 	index := b.svc.store.GetBlockIndex(hash.Event(h))
 	if index == nil {
@@ -295,13 +415,15 @@ func (b *EthAPIBackend) GetTd(_ common.Hash) *big.Int {
 	return big.NewInt(0)
 }
 
-func (b *EthAPIBackend) GetEVM(ctx context.Context, msg evmcore.Message, state *state.StateDB, header *evmcore.EvmHeader) (*vm.EVM, func() error, error) {
-	state.SetBalance(msg.From(), math.MaxBig256)
+func (b *EthAPIBackend) GetEVM(ctx context.Context, msg evmcore.Message, state *state.StateDB, header *evmcore.EvmHeader, vmConfig *vm.Config) (*vm.EVM, func() error, error) {
 	vmError := func() error { return nil }
 
+	if vmConfig == nil {
+		vmConfig = &opera.DefaultVMConfig
+	}
 	context := evmcore.NewEVMContext(msg, header, b.state, nil)
 	config := b.ChainConfig()
-	return vm.NewEVM(context, state, config, opera.DefaultVMConfig), vmError, nil
+	return vm.NewEVM(context, state, config, *vmConfig), vmError, nil
 }
 
 func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
@@ -325,6 +447,18 @@ func (b *EthAPIBackend) SubscribeNewBlockEvent(ch chan<- evmcore.ChainHeadNotify
 	return b.svc.feed.SubscribeNewBlock(ch)
 }
 
+func (b *EthAPIBackend) SubscribeNewEventsNotify(ch chan<- *inter.EventPayload) notify.Subscription {
+	return b.svc.feed.SubscribeNewEvent(ch)
+}
+
+func (b *EthAPIBackend) SubscribeSealedEpochsNotify(ch chan<- ethapi.SealedEpochNotify) notify.Subscription {
+	return b.svc.feed.SubscribeNewSealedEpoch(ch)
+}
+
+func (b *EthAPIBackend) SubscribeNewAtroposNotify(ch chan<- ethapi.AtroposNotify) notify.Subscription {
+	return b.svc.feed.SubscribeNewAtropos(ch)
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.svc.txpool.Pending()
 	if err != nil {
@@ -341,6 +475,24 @@ func (b *EthAPIBackend) GetPoolTransaction(hash common.Hash) *types.Transaction
 	return b.svc.txpool.Get(hash)
 }
 
+// GetTxPosition returns the positions of the transaction in the block and in the event which counted it,
+// nil if the transaction isn't indexed.
+func (b *EthAPIBackend) GetTxPosition(ctx context.Context, txHash common.Hash) (*ethapi.TxPosition, error) {
+	if !b.svc.config.TxIndex {
+		return nil, errors.New("transactions index is disabled (enable TxIndex and re-process the DAG)")
+	}
+	position := b.svc.store.evm.GetTxPosition(txHash)
+	if position == nil {
+		return nil, nil
+	}
+	return &ethapi.TxPosition{
+		Block:       position.Block,
+		Event:       position.Event,
+		EventOffset: position.EventOffset,
+		BlockOffset: position.BlockOffset,
+	}, nil
+}
+
 func (b *EthAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, uint64, uint64, error) {
 	if !b.svc.config.TxIndex {
 		return nil, 0, 0, errors.New("transactions index is disabled (enable TxIndex and re-process the DAG)")
@@ -432,6 +584,14 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.svc.config.RPCTxFeeCap
 }
 
+func (b *EthAPIBackend) TraceFilterLimits() ethapi.TraceFilterLimits {
+	return ethapi.TraceFilterLimits{
+		RangeLimit:   b.svc.config.TraceFilter.RangeLimit,
+		ResultsLimit: b.svc.config.TraceFilter.ResultsLimit,
+		Timeout:      b.svc.config.TraceFilter.Timeout,
+	}
+}
+
 func (b *EthAPIBackend) EvmLogIndex() *topicsdb.Index {
 	return b.svc.store.evm.EvmLogs()
 }
@@ -505,20 +665,40 @@ func (b *EthAPIBackend) GetDowntime(ctx context.Context, stakerID idx.ValidatorI
 	return missedBlocks, missedTime, nil
 }
 
+// sfcAPI returns the SFC API index, which is unavailable if the node is bootstrapped from a snapshot.
+func (b *EthAPIBackend) sfcAPI() (*sfcapi.Store, error) {
+	if n := b.svc.store.GetSnapshotBlockIndex(); n != nil {
+		return nil, fmt.Errorf("SFC API isn't available, the node is bootstrapped from the snapshot of block %d", *n)
+	}
+	return b.svc.store.sfcapi, nil
+}
+
 func (b *EthAPIBackend) GetDelegationClaimedRewards(ctx context.Context, id sfcapi.DelegationID) (*big.Int, error) {
-	return b.svc.store.sfcapi.GetDelegationClaimedRewards(id), nil
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return nil, err
+	}
+	return sfc.GetDelegationClaimedRewards(id), nil
 }
 
 func (b *EthAPIBackend) GetStakerClaimedRewards(ctx context.Context, stakerID idx.ValidatorID) (*big.Int, error) {
-	staker := b.svc.store.sfcapi.GetSfcStaker(stakerID)
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return nil, err
+	}
+	staker := sfc.GetSfcStaker(stakerID)
 	if staker == nil {
 		return nil, nil
 	}
-	return b.svc.store.sfcapi.GetDelegationClaimedRewards(sfcapi.DelegationID{staker.Address, stakerID}), nil
+	return sfc.GetDelegationClaimedRewards(sfcapi.DelegationID{staker.Address, stakerID}), nil
 }
 
 func (b *EthAPIBackend) GetStakerDelegationsClaimedRewards(ctx context.Context, stakerID idx.ValidatorID) (*big.Int, error) {
-	return b.svc.store.sfcapi.GetStakerDelegationsClaimedRewards(stakerID), nil
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return nil, err
+	}
+	return sfc.GetStakerDelegationsClaimedRewards(stakerID), nil
 }
 
 func (b *EthAPIBackend) extendStaker(stakerID idx.ValidatorID, staker *sfcapi.SfcStaker, bs blockproc.BlockState, es blockproc.EpochState) *sfcapi.SfcStaker {
@@ -549,7 +729,11 @@ func (b *EthAPIBackend) extendStaker(stakerID idx.ValidatorID, staker *sfcapi.Sf
 }
 
 func (b *EthAPIBackend) GetStaker(ctx context.Context, stakerID idx.ValidatorID) (*sfcapi.SfcStaker, error) {
-	staker := b.svc.store.sfcapi.GetSfcStaker(stakerID)
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return nil, err
+	}
+	staker := sfc.GetSfcStaker(stakerID)
 	if staker == nil {
 		return nil, nil
 	}
@@ -559,8 +743,12 @@ func (b *EthAPIBackend) GetStaker(ctx context.Context, stakerID idx.ValidatorID)
 }
 
 func (b *EthAPIBackend) GetStakerID(ctx context.Context, addr common.Address) (idx.ValidatorID, error) {
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return 0, err
+	}
 	var found *idx.ValidatorID
-	b.svc.store.sfcapi.ForEachSfcStaker(func(id sfcapi.SfcStakerAndID) {
+	sfc.ForEachSfcStaker(func(id sfcapi.SfcStakerAndID) {
 		if id.Staker.Address == addr {
 			found = &id.StakerID
 		}
@@ -572,10 +760,14 @@ func (b *EthAPIBackend) GetStakerID(ctx context.Context, addr common.Address) (i
 }
 
 func (b *EthAPIBackend) GetStakers(ctx context.Context) ([]sfcapi.SfcStakerAndID, error) {
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return nil, err
+	}
 	stakers := make([]sfcapi.SfcStakerAndID, 0, 200)
 	// Note: loads bs and es atomically to avoid a race condition
 	bs, es := b.svc.store.GetBlockEpochState()
-	b.svc.store.sfcapi.ForEachSfcStaker(func(it sfcapi.SfcStakerAndID) {
+	sfc.ForEachSfcStaker(func(it sfcapi.SfcStakerAndID) {
 		it.Staker = b.extendStaker(it.StakerID, it.Staker, bs, es)
 		stakers = append(stakers, it)
 	})
@@ -583,8 +775,12 @@ func (b *EthAPIBackend) GetStakers(ctx context.Context) ([]sfcapi.SfcStakerAndID
 }
 
 func (b *EthAPIBackend) GetDelegationsOf(ctx context.Context, stakerID idx.ValidatorID) ([]sfcapi.SfcDelegationAndID, error) {
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return nil, err
+	}
 	delegations := make([]sfcapi.SfcDelegationAndID, 0, 200)
-	b.svc.store.sfcapi.ForEachSfcDelegation(func(it sfcapi.SfcDelegationAndID) {
+	sfc.ForEachSfcDelegation(func(it sfcapi.SfcDelegationAndID) {
 		if it.ID.StakerID == stakerID {
 			delegations = append(delegations, it)
 		}
@@ -593,17 +789,61 @@ func (b *EthAPIBackend) GetDelegationsOf(ctx context.Context, stakerID idx.Valid
 }
 
 func (b *EthAPIBackend) GetDelegationsByAddress(ctx context.Context, addr common.Address) ([]sfcapi.SfcDelegationAndID, error) {
-	return b.svc.store.sfcapi.GetSfcDelegationsByAddr(addr, 1000), nil
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return nil, err
+	}
+	return sfc.GetSfcDelegationsByAddr(addr, 1000), nil
 }
 
 func (b *EthAPIBackend) GetDelegation(ctx context.Context, id sfcapi.DelegationID) (*sfcapi.SfcDelegation, error) {
-	return b.svc.store.sfcapi.GetSfcDelegation(id), nil
+	sfc, err := b.sfcAPI()
+	if err != nil {
+		return nil, err
+	}
+	return sfc.GetSfcDelegation(id), nil
 }
 
 func (b *EthAPIBackend) CalcLogsBloom() bool {
 	return b.svc.config.RPCLogsBloom
 }
 
+// GetBlockBloom returns the stored logs bloom of the block, nil if it's not stored.
+func (b *EthAPIBackend) GetBlockBloom(ctx context.Context, number rpc.BlockNumber) (*types.Bloom, error) {
+	if number == rpc.PendingBlockNumber || number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(b.svc.store.GetLatestBlockIndex())
+	}
+	if number < 0 {
+		return nil, nil
+	}
+	return b.svc.store.GetBlockBloom(idx.Block(number)), nil
+}
+
+// BloomStatus returns the section size and the number of the indexed sections of the bloom bits index.
+func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
+	return BloomBitsSectionSize, b.svc.store.GetBloomSections()
+}
+
+// ServiceFilter serves the bloom bits retrievals of the filter session.
+func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
+	for i := 0; i < bloomFilterThreads; i++ {
+		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.svc.bloomRequests)
+	}
+}
+
+// GetEpochStats returns the statistics of the sealed epoch, nil if they aren't stored.
+// * When epoch is -1 the statistics of the latest sealed epoch are returned.
+func (b *EthAPIBackend) GetEpochStats(ctx context.Context, epoch rpc.BlockNumber) (*inter.EpochStats, error) {
+	requested, err := b.epochWithDefault(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+	if requested >= b.svc.store.GetEpoch() {
+		return nil, errors.New("epoch isn't sealed yet")
+	}
+	return b.svc.store.GetEpochStats(requested), nil
+}
+
 func (b *EthAPIBackend) SealedEpochTiming(ctx context.Context) (start inter.Timestamp, end inter.Timestamp) {
 	es := b.svc.store.GetEpochState()
 	return es.PrevEpochStart, es.EpochStart
@@ -0,0 +1,79 @@
+package gossip
+
+import (
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+)
+
+// eventsPruningLoop periodically deletes the events of the epochs which are out of the retention policy.
+func (s *Service) eventsPruningLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.EventsPruning.Period)
+	defer ticker.Stop()
+	for {
+		for s.pruneNextEpochEvents() {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// pruneNextEpochEvents deletes the events of the lowest epoch which is out of the retention policy.
+// Returns false if there's no such epoch or the last block of the epoch isn't processed yet.
+func (s *Service) pruneNextEpochEvents() bool {
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+	if s.stopped {
+		return false
+	}
+
+	epoch := s.store.GetLowestEventsEpoch()
+	if epoch == 0 {
+		epoch = 1
+	}
+	if epoch+s.config.EventsPruning.KeepEpochs >= s.store.GetEpoch() {
+		return false
+	}
+
+	start := time.Now()
+	pruned := s.store.PruneEpochEvents(epoch, s.eventsReferencedByState())
+	if pruned {
+		s.Log.Debug("Pruned epoch events", "epoch", epoch, "t", time.Since(start))
+	}
+
+	if s.store.IsCommitNeeded(false) {
+		s.blockProcWg.Wait()
+		if err := s.store.Commit(); err != nil {
+			s.Log.Error("Failed to commit DBs", "err", err)
+			return false
+		}
+	}
+	return pruned
+}
+
+// eventsReferencedByState returns the last events of the validators, which are required
+// to calculate the gas power of the next events even if they belong to the old epochs.
+func (s *Service) eventsReferencedByState() hash.EventsSet {
+	bs, es := s.store.GetBlockEpochState()
+	keep := hash.NewEventsSet()
+	for _, v := range bs.ValidatorStates {
+		if v.LastEvent != hash.ZeroEvent {
+			keep.Add(v.LastEvent)
+		}
+	}
+	for _, v := range es.ValidatorStates {
+		if v.PrevEpochEvent != hash.ZeroEvent {
+			keep.Add(v.PrevEpochEvent)
+		}
+	}
+	return keep
+}
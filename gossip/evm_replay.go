@@ -0,0 +1,119 @@
+package gossip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// ReplayBlock re-executes transactions of a finalized block on top of the parent block state,
+// in the same way as the blocks processing does, using the network rules of the block epoch.
+// The internal and the external transactions are executed in the same parts as during the blocks processing,
+// so the receipts are equal to the stored ones. The skipped transactions aren't reported.
+// onTxStart is called before each transaction and returns VM config to execute the transaction with.
+// onTxEnd is called after each transaction, the replay stops if it returns false.
+func (b *EthAPIBackend) ReplayBlock(ctx context.Context, number rpc.BlockNumber, onTxStart func(tx ethapi.ReplayedTx) vm.Config, onTxEnd func(tx ethapi.ReplayedTx, receipt *types.Receipt) bool) error {
+	if number == rpc.PendingBlockNumber || number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(b.svc.store.GetLatestBlockIndex())
+	}
+	n := idx.Block(number)
+	block := b.svc.store.GetBlock(n)
+	if block == nil {
+		return errors.New("block not found")
+	}
+	if n == 0 {
+		return errors.New("genesis block cannot be replayed")
+	}
+	statedb, _, err := b.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(n-1)))
+	if err != nil {
+		return err
+	}
+	rules, err := b.svc.store.GetBlockRules(n, block)
+	if err != nil {
+		return err
+	}
+	internalTxs, txs, err := b.svc.store.getBlockTxsForExecution(n, block)
+	if err != nil {
+		return err
+	}
+
+	header := b.state.GetDagHeader(block.Atropos, n)
+	chainCfg := rules.EvmChainConfig()
+	position := 0
+	// replayPart executes the txs like evmcore.StateProcessor does, skipped are the indexes of the skipped txs
+	replayPart := func(txs types.Transactions, internal bool, skipped map[int]bool) (bool, error) {
+		gp := new(evmcore.GasPool).AddGas(header.GasLimit)
+		usedGas := uint64(0)
+		for i, tx := range txs {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			replayed := ethapi.ReplayedTx{
+				Index:    position,
+				Tx:       tx,
+				Internal: internal,
+			}
+			vmCfg := opera.DefaultVMConfig
+			if !skipped[i] {
+				vmCfg = onTxStart(replayed)
+			}
+			statedb.Prepare(tx.Hash(), header.Hash, i)
+			receipt, _, skip, err := evmcore.ApplyTransaction(chainCfg, b.state, nil, gp, statedb, header, tx, &usedGas, vmCfg, internal, func(*types.Log, *state.StateDB) {})
+			if skip != skipped[i] {
+				if skip {
+					return false, fmt.Errorf("transaction %s is skipped during the replay of block %d: %v", tx.Hash().String(), n, err)
+				}
+				return false, fmt.Errorf("transaction %s isn't skipped during the replay of block %d", tx.Hash().String(), n)
+			}
+			if skip {
+				continue
+			}
+			if err != nil {
+				return false, fmt.Errorf("transaction %s of block %d failed: %v", tx.Hash().String(), n, err)
+			}
+			position++
+			if !onTxEnd(replayed, receipt) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	for _, part := range splitInternalTxs(internalTxs, b.svc.store.evm.GetReceipts(n)) {
+		if next, err := replayPart(part, true, nil); !next || err != nil {
+			return err
+		}
+	}
+	// the skipped txs are counted from the first tx of the first event
+	skipped := make(map[int]bool, len(block.SkippedTxs))
+	for _, i := range block.SkippedTxs {
+		skipped[len(block.Txs)+int(i)] = true
+	}
+	_, err = replayPart(txs, false, skipped)
+	return err
+}
+
+// ForEachTraceAddressBlock iterates the blocks in the range [from, to], in call traces of which the address appears.
+func (b *EthAPIBackend) ForEachTraceAddressBlock(ctx context.Context, addr common.Address, from, to idx.Block, onBlock func(n idx.Block) bool) error {
+	if !b.svc.config.TraceIndex {
+		return errors.New("trace index is disabled (enable TraceIndex and re-process the DAG)")
+	}
+	b.svc.store.evm.ForEachTraceAddressBlock(addr, from, to, func(n idx.Block) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		return onBlock(n)
+	})
+	return ctx.Err()
+}
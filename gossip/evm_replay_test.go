@@ -0,0 +1,96 @@
+package gossip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestEthAPIBackendReplayBlock(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv()
+	defer env.Close()
+
+	// the sender 100 isn't funded by the genesis, so its tx is skipped
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 2, utils.ToFtm(10)), env.Transfer(100, 1, utils.ToFtm(1)), env.Transfer(2, 3, utils.ToFtm(1)))
+	env.ApplyBlock(nextEpoch, env.Transfer(3, 1, utils.ToFtm(1)))
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 3, utils.ToFtm(1)))
+	env.blockProcWg.Wait()
+
+	b := &EthAPIBackend{
+		svc: &Service{
+			store:            env.store,
+			blockProcModules: env.blockProcModules,
+		},
+		state: env.GetEvmStateReader(),
+	}
+
+	replay := func(n idx.Block) (txs []ethapi.ReplayedTx, receipts types.Receipts) {
+		err := b.ReplayBlock(context.Background(), rpc.BlockNumber(n), func(tx ethapi.ReplayedTx) vm.Config {
+			return opera.DefaultVMConfig
+		}, func(tx ethapi.ReplayedTx, receipt *types.Receipt) bool {
+			txs = append(txs, tx)
+			receipts = append(receipts, receipt)
+			return true
+		})
+		require.NoError(err)
+		return
+	}
+
+	latest := env.store.GetLatestBlockIndex()
+	// the genesis funding is random, so any of the blocks may have no txs
+	nonEmpty := idx.Block(0)
+	for n := latest - 2; n <= latest; n++ {
+		block := env.store.GetBlock(n)
+		stored := env.store.evm.GetReceipts(n)
+		txs, receipts := replay(n)
+		require.Equal(len(stored), len(receipts), n)
+		if len(receipts) != 0 {
+			nonEmpty = n
+		}
+
+		internalTxs, externalTxs, err := env.store.getBlockTxsForExecution(n, block)
+		require.NoError(err)
+		require.Equal(len(internalTxs)+len(externalTxs)-len(block.SkippedTxs), len(txs))
+		for i, tx := range txs {
+			require.Equal(i, tx.Index)
+			require.Equal(i < len(internalTxs), tx.Internal)
+			require.Equal(stored[i].Status, receipts[i].Status, i)
+			require.Equal(stored[i].CumulativeGasUsed, receipts[i].CumulativeGasUsed, i)
+			require.Equal(len(stored[i].Logs), len(receipts[i].Logs), i)
+			for j, l := range receipts[i].Logs {
+				require.Equal(stored[i].Logs[j].Address, l.Address)
+				require.Equal(stored[i].Logs[j].Topics, l.Topics)
+				require.Equal(stored[i].Logs[j].Data, l.Data)
+			}
+		}
+	}
+
+	// stopped replay
+	if nonEmpty != 0 {
+		calls := 0
+		err := b.ReplayBlock(context.Background(), rpc.BlockNumber(nonEmpty), func(tx ethapi.ReplayedTx) vm.Config {
+			return opera.DefaultVMConfig
+		}, func(tx ethapi.ReplayedTx, receipt *types.Receipt) bool {
+			calls++
+			return false
+		})
+		require.NoError(err)
+		require.Equal(1, calls)
+	}
+
+	require.Error(b.ReplayBlock(context.Background(), 0, nil, nil))
+	require.Error(b.ReplayBlock(context.Background(), rpc.BlockNumber(latest+1), nil, nil))
+}
@@ -0,0 +1,107 @@
+package gossip
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+var errTxNotSkipped = errors.New("transaction isn't skipped when the block is re-executed")
+
+// GetSkippedTxs returns the skipped transactions of the block in the order of the block SkippedTxs.
+func (s *Store) GetSkippedTxs(n idx.Block, block *inter.Block) (types.Transactions, error) {
+	skipped := make(types.Transactions, 0, len(block.SkippedTxs))
+	if len(block.SkippedTxs) == 0 {
+		return skipped, nil
+	}
+	_, txs, err := s.getBlockTxsForExecution(n, block)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range block.SkippedTxs {
+		if int(i) >= len(txs) {
+			return nil, fmt.Errorf("skipped tx %d of block %d not found", i, n)
+		}
+		skipped = append(skipped, txs[i])
+	}
+	return skipped, nil
+}
+
+// ExplainSkippedTxs re-executes the block on top of the state of the previous block, in the same way as
+// VerifyBlocks does, and returns the reasons why the skipped transactions were skipped,
+// in the order of the block SkippedTxs. The state of the previous block must be available,
+// evmstore.ErrHistoricalStateUnavailable is returned otherwise.
+func (s *Store) ExplainSkippedTxs(n idx.Block, evmModule blockproc.EVM) ([]error, error) {
+	block := s.GetBlock(n)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", n)
+	}
+	if len(block.SkippedTxs) == 0 {
+		return []error{}, nil
+	}
+	parent := s.GetBlock(n - 1)
+	if parent == nil {
+		return nil, fmt.Errorf("block %d not found", n-1)
+	}
+	internalTxs, txs, err := s.getBlockTxsForExecution(n, block)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.GetBlockRules(n, block)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := s.evm.StateDB(parent.Root)
+	if err == evmstore.ErrHistoricalStateUnavailable {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the state of block %d: %v", n-1, err)
+	}
+
+	reader := &EvmStateReader{store: s}
+	blockCtx := blockproc.BlockCtx{
+		Idx:     n,
+		Time:    block.Time,
+		Atropos: block.Atropos,
+	}
+	header := reader.GetDagHeader(block.Atropos, n)
+	evmProcessor := evmModule.Start(blockCtx, statedb, reader, func(*types.Log) {}, rules, opera.DefaultVMConfig)
+	for _, internal := range splitInternalTxs(internalTxs, s.evm.GetReceipts(n)) {
+		evmProcessor.Execute(internal, true)
+	}
+	// the skipped txs are counted from the first non-internal tx
+	reasons := make([]error, 0, len(block.SkippedTxs))
+	for i, tx := range txs {
+		if len(reasons) < len(block.SkippedTxs) && block.SkippedTxs[len(reasons)] == uint32(i) {
+			reasons = append(reasons, skippedTxReason(rules, reader, header, statedb.Copy(), tx))
+		}
+		if len(reasons) == len(block.SkippedTxs) {
+			break
+		}
+		evmProcessor.Execute(types.Transactions{tx}, false)
+	}
+	return reasons, nil
+}
+
+// skippedTxReason applies the transaction to a copy of the state and returns the error which makes it skipped.
+func skippedTxReason(rules opera.Rules, reader evmcore.DummyChain, header *evmcore.EvmHeader, statedb *state.StateDB, tx *types.Transaction) error {
+	gp := new(evmcore.GasPool).AddGas(header.GasLimit)
+	usedGas := uint64(0)
+	statedb.Prepare(tx.Hash(), header.Hash, 0)
+	_, _, skip, err := evmcore.ApplyTransaction(rules.EvmChainConfig(), reader, nil, gp, statedb, header, tx, &usedGas,
+		opera.DefaultVMConfig, false, func(*types.Log, *state.StateDB) {})
+	if !skip || err == nil {
+		return errTxNotSkipped
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package gossip
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip/blockproc/evmmodule"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStoreExplainSkippedTxs(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv()
+	defer env.Close()
+
+	// the sender isn't funded by the genesis
+	const sender = 100
+	skipped := env.Transfer(sender, 1, utils.ToFtm(1))
+	env.ApplyBlock(sameEpoch, skipped)
+	env.blockProcWg.Wait()
+	n := env.store.GetLatestBlockIndex()
+	block := env.store.GetBlock(n)
+	require.Equal([]uint32{0}, block.SkippedTxs)
+
+	txs, err := env.store.GetSkippedTxs(n, block)
+	require.NoError(err)
+	require.Len(txs, 1)
+	require.Equal(skipped.Hash(), txs[0].Hash())
+
+	reasons, err := env.store.ExplainSkippedTxs(n, evmmodule.New())
+	require.NoError(err)
+	require.Equal([]error{evmcore.ErrInsufficientFunds}, reasons)
+
+	// no skipped txs
+	env.ApplyBlock(sameEpoch)
+	env.blockProcWg.Wait()
+	reasons, err = env.store.ExplainSkippedTxs(n+1, evmmodule.New())
+	require.NoError(err)
+	require.Empty(reasons)
+
+	// the block of a sealed epoch is re-executed with the rules of its epoch
+	epoch := env.store.GetEpoch()
+	env.ApplyBlock(nextEpoch)
+	env.blockProcWg.Wait()
+	b := &EthAPIBackend{
+		svc: &Service{
+			store:            env.store,
+			blockProcModules: env.blockProcModules,
+		},
+		state: env.GetEvmStateReader(),
+	}
+	txs, reasons, err = b.GetSkippedTxs(context.Background(), n, true)
+	require.NoError(err)
+	require.Len(txs, 1)
+	require.Equal([]error{evmcore.ErrInsufficientFunds}, reasons)
+
+	// the state of the previous block is pruned
+	parent := env.store.GetBlock(n - 1)
+	pruned := *parent
+	pruned.Root = hash.Hash{1}
+	env.store.SetBlock(n-1, &pruned)
+	_, err = env.store.ExplainSkippedTxs(n, evmmodule.New())
+	require.Equal(evmstore.ErrHistoricalStateUnavailable, err)
+	txs, reasons, err = b.GetSkippedTxs(context.Background(), n, true)
+	require.NoError(err)
+	require.Len(txs, 1)
+	require.Nil(reasons)
+	env.store.SetBlock(n-1, parent)
+
+	// the other errors are returned
+	require.NoError(env.store.table.BlockEpochStateHistory.Delete(epoch.Bytes()))
+	_, _, err = b.GetSkippedTxs(context.Background(), n, true)
+	require.EqualError(err, fmt.Sprintf("rules of epoch %d of block %d not found", epoch, n))
+}
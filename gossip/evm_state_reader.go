@@ -89,16 +89,22 @@ func (r *EvmStateReader) getBlock(h hash.Event, n idx.Block, readTxs bool) *evmc
 			}
 			transactions = append(transactions, tx)
 		}
+		// the skipped txs are counted from the first of Txs
+		txCount := uint32(0)
+		skipCount := 0
 		for _, txid := range block.Txs {
 			tx := r.store.evm.GetTx(txid)
 			if tx == nil {
 				log.Crit("Tx not found", "tx", txid.String())
 				continue
 			}
-			transactions = append(transactions, tx)
+			if skipCount < len(block.SkippedTxs) && block.SkippedTxs[skipCount] == txCount {
+				skipCount++
+			} else {
+				transactions = append(transactions, tx)
+			}
+			txCount++
 		}
-		txCount := uint32(0)
-		skipCount := 0
 		for _, id := range block.Events {
 			e := r.store.GetEventPayload(id)
 			if e == nil {
@@ -122,7 +128,10 @@ func (r *EvmStateReader) getBlock(h hash.Event, n idx.Block, readTxs bool) *evmc
 
 	var prev hash.Event
 	if n != 0 {
-		prev = r.store.GetBlock(n - 1).Atropos
+		// blocks prior to a genesis snapshot are missing
+		if prevBlock := r.store.GetBlock(n - 1); prevBlock != nil {
+			prev = prevBlock.Atropos
+		}
 	}
 	evmHeader := evmcore.ToEvmHeader(block, n, prev)
 	evmBlock := &evmcore.EvmBlock{
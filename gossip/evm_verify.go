@@ -0,0 +1,208 @@
+package gossip
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// BlockMismatchError describes the first difference of a re-executed block from the stored one.
+type BlockMismatchError struct {
+	Block idx.Block
+	// TxIndex is the block position of the first divergent transaction, -1 if it's unknown or the transaction is skipped
+	TxIndex int
+	// Tx is the first divergent transaction, zero if it's unknown
+	Tx     common.Hash
+	Reason string
+}
+
+func (e *BlockMismatchError) Error() string {
+	at := ""
+	if e.TxIndex >= 0 {
+		at += fmt.Sprintf(" at tx %d", e.TxIndex)
+	}
+	if e.Tx != (common.Hash{}) {
+		at += fmt.Sprintf(" (%s)", e.Tx.String())
+	}
+	return fmt.Sprintf("block %d mismatch%s: %s", e.Block, at, e.Reason)
+}
+
+// VerifyBlocks re-executes the blocks in the range [from, to] on top of the state of the block from-1,
+// using the same EVM module and the rules of the same epoch as the blocks processing did, and compares the resulting skipped transactions,
+// receipts and state roots with the stored ones. The receipts are compared only if they are indexed.
+// *BlockMismatchError is returned for the first divergent block.
+// onBlock is called after each verified block, the verification stops if it returns false.
+func (s *Store) VerifyBlocks(from, to idx.Block, evmModule blockproc.EVM, onBlock func(n idx.Block) bool) error {
+	if genesis := s.GetGenesisBlockIndex(); genesis != nil && from <= *genesis {
+		return fmt.Errorf("genesis blocks cannot be verified, the first block to verify is %d", *genesis+1)
+	}
+	reader := &EvmStateReader{store: s}
+	var (
+		rules      opera.Rules
+		rulesEpoch idx.Epoch
+		prevRoot   hash.Hash
+	)
+	for n := from; n <= to; n++ {
+		block := s.GetBlock(n)
+		if block == nil {
+			return fmt.Errorf("block %d not found", n)
+		}
+		parent := s.GetBlock(n - 1)
+		if parent == nil {
+			return fmt.Errorf("block %d not found", n-1)
+		}
+		internalTxs, txs, err := s.getBlockTxsForExecution(n, block)
+		if err != nil {
+			return err
+		}
+		if epoch := block.Atropos.Epoch(); epoch != rulesEpoch {
+			rules, err = s.GetBlockRules(n, block)
+			if err != nil {
+				return err
+			}
+			rulesEpoch = epoch
+		}
+
+		statedb, err := s.evm.StateDB(parent.Root)
+		if err != nil {
+			return fmt.Errorf("failed to open the state of block %d: %v", n-1, err)
+		}
+		blockCtx := blockproc.BlockCtx{
+			Idx:     n,
+			Time:    block.Time,
+			Atropos: block.Atropos,
+		}
+		stored := s.evm.GetReceipts(n)
+		evmProcessor := evmModule.Start(blockCtx, statedb, reader, func(*types.Log) {}, rules, opera.DefaultVMConfig)
+		for _, internal := range splitInternalTxs(internalTxs, stored) {
+			evmProcessor.Execute(internal, true)
+		}
+		evmProcessor.Execute(txs, false)
+		evmBlock, skippedTxs, receipts := evmProcessor.Finalize()
+
+		if err := compareExecutedBlock(n, block, txs, evmBlock.Transactions, skippedTxs, receipts, stored); err != nil {
+			return err
+		}
+		if hash.Hash(evmBlock.Root) != block.Root {
+			return &BlockMismatchError{
+				Block:   n,
+				TxIndex: -1,
+				Reason:  fmt.Sprintf("state root %s != stored %s", evmBlock.Root.String(), block.Root.String()),
+			}
+		}
+
+		// the re-executed states are kept in memory until the next block is executed
+		if n != from && prevRoot != block.Root {
+			s.evm.DereferenceState(prevRoot)
+		}
+		prevRoot = block.Root
+		if onBlock != nil && !onBlock(n) {
+			break
+		}
+	}
+	return nil
+}
+
+// getBlockTxsForExecution returns the internal transactions and the transactions of the block events
+// in the original order, including the skipped ones.
+func (s *Store) getBlockTxsForExecution(n idx.Block, block *inter.Block) (internalTxs, txs types.Transactions, err error) {
+	for _, id := range block.InternalTxs {
+		tx := s.evm.GetTx(id)
+		if tx == nil {
+			return nil, nil, fmt.Errorf("internal tx %s of block %d not found", id.String(), n)
+		}
+		internalTxs = append(internalTxs, tx)
+	}
+	// the blocks with pruned events list the transactions explicitly
+	for _, id := range block.Txs {
+		tx := s.evm.GetTx(id)
+		if tx == nil {
+			return nil, nil, fmt.Errorf("tx %s of block %d not found", id.String(), n)
+		}
+		txs = append(txs, tx)
+	}
+	for _, id := range block.Events {
+		e := s.GetEventPayload(id)
+		if e == nil {
+			return nil, nil, fmt.Errorf("event %s of block %d not found", id.String(), n)
+		}
+		txs = append(txs, e.Txs()...)
+	}
+	return internalTxs, txs, nil
+}
+
+// splitInternalTxs splits the internal transactions into the pre-internal and post-internal ones.
+// They were executed separately, the cumulative gas of the stored receipts is reset at the beginning of each part.
+// The split doesn't affect the state, so all the transactions are returned as one part if the receipts aren't indexed.
+func splitInternalTxs(internalTxs types.Transactions, stored types.Receipts) []types.Transactions {
+	parts := make([]types.Transactions, 0, 2)
+	start := 0
+	for i := 1; i < len(internalTxs) && i < len(stored); i++ {
+		if stored[i].CumulativeGasUsed <= stored[i-1].CumulativeGasUsed {
+			parts = append(parts, internalTxs[start:i])
+			start = i
+		}
+	}
+	return append(parts, internalTxs[start:])
+}
+
+// compareExecutedBlock compares the results of the block re-execution with the stored block and receipts.
+// txs are the transactions of the block events, executed are the not skipped block transactions.
+func compareExecutedBlock(n idx.Block, block *inter.Block, txs, executed types.Transactions, skippedTxs []uint32, receipts, stored types.Receipts) error {
+	mismatch := func(i int, reason string, args ...interface{}) *BlockMismatchError {
+		err := &BlockMismatchError{
+			Block:   n,
+			TxIndex: i,
+			Reason:  fmt.Sprintf(reason, args...),
+		}
+		if i >= 0 && i < len(executed) {
+			err.Tx = executed[i].Hash()
+		}
+		return err
+	}
+
+	for i := 0; i < len(skippedTxs) || i < len(block.SkippedTxs); i++ {
+		if i < len(skippedTxs) && i < len(block.SkippedTxs) && skippedTxs[i] == block.SkippedTxs[i] {
+			continue
+		}
+		// the first tx which is skipped only in one of the lists
+		first := ^uint32(0)
+		if i < len(skippedTxs) {
+			first = skippedTxs[i]
+		}
+		if i < len(block.SkippedTxs) && block.SkippedTxs[i] < first {
+			first = block.SkippedTxs[i]
+		}
+		err := mismatch(-1, "skipped txs %v != stored %v", skippedTxs, block.SkippedTxs)
+		if int(first) < len(txs) {
+			err.Tx = txs[first].Hash()
+		}
+		return err
+	}
+	if stored == nil {
+		// receipts aren't indexed
+		return nil
+	}
+	for i := 0; i < len(receipts) && i < len(stored); i++ {
+		a, _ := rlp.EncodeToBytes((*types.ReceiptForStorage)(receipts[i]))
+		b, _ := rlp.EncodeToBytes((*types.ReceiptForStorage)(stored[i]))
+		if !bytes.Equal(a, b) {
+			return mismatch(i, "receipt (status=%d, gas used=%d, logs=%d) != stored (status=%d, gas used=%d, logs=%d)",
+				receipts[i].Status, receipts[i].GasUsed, len(receipts[i].Logs),
+				stored[i].Status, stored[i].GasUsed, len(stored[i].Logs))
+		}
+	}
+	if len(receipts) != len(stored) {
+		return mismatch(-1, "%d receipts != stored %d", len(receipts), len(stored))
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package gossip
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
+	"github.com/Fantom-foundation/go-opera/gossip/blockproc/evmmodule"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStoreVerifyBlocks(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv()
+	defer env.Close()
+
+	from := env.store.GetLatestBlockIndex() + 1
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 2, utils.ToFtm(100)), env.Transfer(2, 3, utils.ToFtm(10)))
+	env.ApplyBlock(nextEpoch)
+	dup := env.Transfer(1, 3, utils.ToFtm(1))
+	env.ApplyBlock(sameEpoch, dup, dup)
+	env.blockProcWg.Wait()
+	to := env.store.GetLatestBlockIndex()
+
+	verify := func() *BlockMismatchError {
+		verified := idx.Block(0)
+		err := env.store.VerifyBlocks(from, to, evmmodule.New(), func(n idx.Block) bool {
+			require.Equal(from+verified, n)
+			verified++
+			return true
+		})
+		if err == nil {
+			require.Equal(to-from+1, verified)
+			return nil
+		}
+		var mismatch *BlockMismatchError
+		require.True(errors.As(err, &mismatch), err)
+		return mismatch
+	}
+	require.Nil(verify())
+
+	// corrupted skipped txs, the duplicated tx is skipped at least once
+	block := env.store.GetBlock(to)
+	corrupted := *block
+	if len(block.SkippedTxs) == 0 {
+		corrupted.SkippedTxs = []uint32{0}
+	} else {
+		corrupted.SkippedTxs = nil
+	}
+	env.store.SetBlock(to, &corrupted)
+	mismatch := verify()
+	require.NotNil(mismatch)
+	require.Equal(to, mismatch.Block)
+	require.Equal(dup.Hash(), mismatch.Tx)
+	env.store.SetBlock(to, block)
+
+	// corrupted receipt of the epoch sealing
+	sealing := from + 1
+	block = env.store.GetBlock(sealing)
+	require.NotEmpty(block.InternalTxs)
+	receipts := env.store.evm.GetReceipts(sealing)
+	corruptedReceipts := make(types.Receipts, len(receipts))
+	copy(corruptedReceipts, receipts)
+	r := *receipts[0]
+	r.Status = types.ReceiptStatusFailed
+	corruptedReceipts[0] = &r
+	env.store.evm.SetReceipts(sealing, corruptedReceipts)
+	mismatch = verify()
+	require.NotNil(mismatch)
+	require.Equal(sealing, mismatch.Block)
+	require.Equal(0, mismatch.TxIndex)
+	require.Equal(block.InternalTxs[0], mismatch.Tx)
+	env.store.evm.SetReceipts(sealing, receipts)
+
+	// corrupted state root
+	block = env.store.GetBlock(from)
+	corrupted = *block
+	corrupted.Root = hash.Hash{1}
+	env.store.SetBlock(from, &corrupted)
+	mismatch = verify()
+	require.NotNil(mismatch)
+	require.Equal(from, mismatch.Block)
+	require.Equal(-1, mismatch.TxIndex)
+	env.store.SetBlock(from, block)
+
+	require.Nil(verify())
+}
+
+// rulesRecorder records the rules which the blocks are executed with.
+type rulesRecorder struct {
+	blockproc.EVM
+	rules map[idx.Block]opera.Rules
+}
+
+func (r *rulesRecorder) Start(block blockproc.BlockCtx, statedb *state.StateDB, reader evmcore.DummyChain, onNewLog func(*types.Log), net opera.Rules, vmCfg vm.Config) blockproc.EVMProcessor {
+	r.rules[block.Idx] = net
+	return r.EVM.Start(block, statedb, reader, onNewLog, net, vmCfg)
+}
+
+func TestStoreVerifyBlocksRules(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv()
+	defer env.Close()
+
+	from := env.store.GetLatestBlockIndex() + 1
+	prevEpoch := env.store.GetEpoch()
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 2, utils.ToFtm(1)))
+	env.ApplyBlock(nextEpoch)
+	env.ApplyBlock(sameEpoch, env.Transfer(2, 3, utils.ToFtm(1)))
+	env.blockProcWg.Wait()
+	to := env.store.GetLatestBlockIndex()
+
+	// the rules of the previous epoch differ from the current ones
+	bs, es := env.store.GetHistoryBlockEpochState(prevEpoch)
+	require.NotNil(es)
+	es.Rules.Name = "previous"
+	env.store.SetHistoryBlockEpochState(prevEpoch, *bs, *es)
+
+	recorder := &rulesRecorder{EVM: evmmodule.New(), rules: map[idx.Block]opera.Rules{}}
+	require.NoError(env.store.VerifyBlocks(from, to, recorder, nil))
+	current := env.store.GetRules()
+	for n := from; n <= to; n++ {
+		if env.store.GetBlock(n).Atropos.Epoch() == prevEpoch {
+			require.Equal("previous", recorder.rules[n].Name, n)
+		} else {
+			require.Equal(current.Name, recorder.rules[n].Name, n)
+		}
+	}
+	require.Equal("previous", recorder.rules[from].Name)
+	require.Equal(current.Name, recorder.rules[to].Name)
+
+	// the rules of the block epoch are unknown
+	require.NoError(env.store.table.BlockEpochStateHistory.Delete(prevEpoch.Bytes()))
+	require.EqualError(env.store.VerifyBlocks(from, to, evmmodule.New(), nil), fmt.Sprintf("rules of epoch %d of block %d not found", prevEpoch, from))
+}
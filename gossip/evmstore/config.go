@@ -13,12 +13,32 @@ type (
 		TxPositions int
 		EvmDatabase int
 	}
+	// StateGCConfig is a config for the EVM state pruning of non-archive nodes.
+	StateGCConfig struct {
+		// Enabled prunes the old states (gcmode full), all the states are kept otherwise (gcmode archive).
+		// The states of the recent blocks and of the sealed epochs are always kept.
+		Enabled bool
+		// TriesInMemory is the number of the recent blocks which states are kept
+		TriesInMemory uint64
+		// DirtyLimit is the memory allowance of the non-flushed trie nodes (size in bytes)
+		DirtyLimit int
+	}
 	// StoreConfig is a config for store db.
 	StoreConfig struct {
-		Cache StoreCacheConfig
+		Cache   StoreCacheConfig
+		StateGC StateGCConfig
 	}
 )
 
+// DefaultStateGCConfig is the pruning config, it's disabled by default.
+func DefaultStateGCConfig() StateGCConfig {
+	return StateGCConfig{
+		Enabled:       false,
+		TriesInMemory: 128,
+		DirtyLimit:    256 * opt.MiB,
+	}
+}
+
 // DefaultStoreConfig for product.
 func DefaultStoreConfig() StoreConfig {
 	return StoreConfig{
@@ -28,6 +48,7 @@ func DefaultStoreConfig() StoreConfig {
 			TxPositions:    5000,
 			EvmDatabase:    16 * opt.MiB,
 		},
+		DefaultStateGCConfig(),
 	}
 }
 
@@ -39,5 +60,10 @@ func LiteStoreConfig() StoreConfig {
 			ReceiptsBlocks: 100,
 			TxPositions:    500,
 		},
+		StateGCConfig{
+			Enabled:       false,
+			TriesInMemory: 16,
+			DirtyLimit:    16 * opt.MiB,
+		},
 	}
 }
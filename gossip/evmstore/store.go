@@ -1,18 +1,22 @@
 package evmstore
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/kvdb"
 	"github.com/Fantom-foundation/lachesis-base/kvdb/nokeyiserr"
 	"github.com/Fantom-foundation/lachesis-base/kvdb/table"
 	"github.com/Fantom-foundation/lachesis-base/utils/wlru"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 
 	"github.com/Fantom-foundation/go-opera/logger"
@@ -21,6 +25,9 @@ import (
 	"github.com/Fantom-foundation/go-opera/utils/rlpstore"
 )
 
+// ErrHistoricalStateUnavailable is returned if the requested EVM state isn't found, e.g. it was pruned.
+var ErrHistoricalStateUnavailable = errors.New("historical state unavailable")
+
 // Store is a node persistent storage working over physical key-value database.
 type Store struct {
 	cfg StoreConfig
@@ -31,6 +38,7 @@ type Store struct {
 		Receipts    kvdb.Store `table:"r"`
 		TxPositions kvdb.Store `table:"x"`
 		Txs         kvdb.Store `table:"X"`
+		TraceAddrs  kvdb.Store `table:"T"`
 
 		Evm      ethdb.Database
 		EvmState state.Database
@@ -46,6 +54,9 @@ type Store struct {
 		Inc sync.Mutex
 	}
 
+	// triegc is the queue of the recent states to dereference, prioritized by block index
+	triegc *prque.Prque
+
 	rlp rlpstore.Helper
 
 	logger.Instance
@@ -58,6 +69,7 @@ func NewStore(mainDB kvdb.Store, cfg StoreConfig) *Store {
 		mainDB:   mainDB,
 		Instance: logger.MakeInstance(),
 		rlp:      rlpstore.Helper{logger.MakeInstance()},
+		triegc:   prque.New(nil),
 	}
 
 	table.MigrateTables(&s.table, s.mainDB)
@@ -78,8 +90,47 @@ func (s *Store) initCache() {
 }
 
 // Commit changes.
-func (s *Store) Commit(root hash.Hash) error {
-	// Flush trie on the DB
+// If the state pruning is disabled or flush is true, the state of the block is flushed on the DB.
+// Otherwise, it's kept in memory until the block is older than TriesInMemory blocks, unless it's flushed
+// by Cap or Flush.
+func (s *Store) Commit(block idx.Block, root hash.Hash, flush bool) error {
+	triedb := s.table.EvmState.TrieDB()
+	if !s.cfg.StateGC.Enabled || flush {
+		// Flush trie on the DB
+		err := triedb.Commit(common.Hash(root), false, nil)
+		if err != nil {
+			s.Log.Error("Failed to flush trie DB into main DB", "err", err)
+			return err
+		}
+	}
+	if !s.cfg.StateGC.Enabled {
+		return nil
+	}
+
+	// reference the recent state and dereference the outdated states
+	triedb.Reference(common.Hash(root), common.Hash{})
+	s.triegc.Push(common.Hash(root), -int64(block))
+	if uint64(block) <= s.cfg.StateGC.TriesInMemory {
+		return nil
+	}
+	chosen := uint64(block) - s.cfg.StateGC.TriesInMemory
+	for !s.triegc.Empty() {
+		root, number := s.triegc.Pop()
+		if uint64(-number) > chosen {
+			s.triegc.Push(root, number)
+			break
+		}
+		triedb.Dereference(root.(common.Hash))
+	}
+	return nil
+}
+
+// Flush writes the state on the DB, the state is kept after a restart.
+// It's required only if the state pruning is enabled, otherwise the states are flushed by Commit.
+func (s *Store) Flush(root hash.Hash) error {
+	if !s.cfg.StateGC.Enabled {
+		return nil
+	}
 	err := s.table.EvmState.TrieDB().Commit(common.Hash(root), false, nil)
 	if err != nil {
 		s.Log.Error("Failed to flush trie DB into main DB", "err", err)
@@ -87,7 +138,13 @@ func (s *Store) Commit(root hash.Hash) error {
 	return err
 }
 
+// Cap flushes the oldest trie nodes on the DB if the memory allowance is exceeded.
+// If the state pruning is enabled, the allowance is StateGC.DirtyLimit instead of max.
 func (s *Store) Cap(max, min int) {
+	if s.cfg.StateGC.Enabled {
+		max = s.cfg.StateGC.DirtyLimit
+		min = max - ethdb.IdealBatchSize
+	}
 	maxSize := common.StorageSize(max)
 	minSize := common.StorageSize(min)
 	size, preimagesSize := s.table.EvmState.TrieDB().Size()
@@ -96,9 +153,20 @@ func (s *Store) Cap(max, min int) {
 	}
 }
 
+// DereferenceState releases the in-memory trie nodes of the state which aren't referenced by other states.
+// The state must not be referenced by the state pruning.
+func (s *Store) DereferenceState(root hash.Hash) {
+	s.table.EvmState.TrieDB().Dereference(common.Hash(root))
+}
+
 // StateDB returns state database.
+// ErrHistoricalStateUnavailable is returned if the state isn't found, e.g. it was pruned.
 func (s *Store) StateDB(from hash.Hash) (*state.StateDB, error) {
-	return state.New(common.Hash(from), s.table.EvmState, nil)
+	statedb, err := state.New(common.Hash(from), s.table.EvmState, nil)
+	if missing, ok := err.(*trie.MissingNodeError); ok && missing.NodeHash == common.Hash(from) {
+		return nil, ErrHistoricalStateUnavailable
+	}
+	return statedb, err
 }
 
 // IndexLogs indexes EVM logs
@@ -1,10 +1,16 @@
 package evmstore
 
 import (
+	"math/big"
+	"testing"
 	"time"
 
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/kvdb"
 	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
 )
 
 func cachedStore() *Store {
@@ -28,3 +34,56 @@ func withDelay(db kvdb.DropableStore) kvdb.DropableStore {
 
 	return db
 }
+
+func TestStateGC(t *testing.T) {
+	require := require.New(t)
+
+	commitBlocks := func(s *Store, blocks int, sealed idx.Block) []hash.Hash {
+		roots := make([]hash.Hash, 0, blocks)
+		root := hash.Zero
+		for n := idx.Block(1); n <= idx.Block(blocks); n++ {
+			statedb, err := s.StateDB(root)
+			require.NoError(err)
+			statedb.SetBalance(common.BigToAddress(big.NewInt(int64(n))), big.NewInt(int64(n)))
+			statedb.SetNonce(common.Address{1}, uint64(n))
+			statedb.SetState(common.Address{1}, common.BigToHash(big.NewInt(int64(n))), common.Hash{1})
+			r, err := statedb.Commit(true)
+			require.NoError(err)
+			root = hash.Hash(r)
+			require.NoError(s.Commit(n, root, n == sealed))
+			roots = append(roots, root)
+		}
+		return roots
+	}
+
+	// archive mode keeps all the states
+	archive := NewStore(memorydb.New(), LiteStoreConfig())
+	for _, root := range commitBlocks(archive, 40, 0) {
+		_, err := archive.StateDB(root)
+		require.NoError(err)
+	}
+
+	// full mode keeps only the recent and the flushed states
+	cfg := LiteStoreConfig()
+	cfg.StateGC.Enabled = true
+	cfg.StateGC.TriesInMemory = 16
+	full := NewStore(memorydb.New(), cfg)
+	const sealed = 10
+	roots := commitBlocks(full, 40, sealed)
+	for i, root := range roots {
+		n := i + 1
+		_, err := full.StateDB(root)
+		if n == sealed || n > 40-16 {
+			require.NoError(err, n)
+		} else {
+			require.Equal(ErrHistoricalStateUnavailable, err, n)
+		}
+	}
+
+	// the flushed state is complete
+	require.NoError(full.Flush(roots[len(roots)-1]))
+	statedb, err := full.StateDB(roots[len(roots)-1])
+	require.NoError(err)
+	require.Equal(big.NewInt(1), statedb.GetBalance(common.BigToAddress(big.NewInt(1))))
+	require.Equal(common.Hash{1}, statedb.GetState(common.Address{1}, common.BigToHash(big.NewInt(1))))
+}
@@ -0,0 +1,28 @@
+package evmstore
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SetTraceAddresses indexes the addresses which appear in the call traces of the block.
+func (s *Store) SetTraceAddresses(n idx.Block, addrs []common.Address) {
+	for _, addr := range addrs {
+		key := append(addr.Bytes(), n.Bytes()...)
+		if err := s.table.TraceAddrs.Put(key, []byte{}); err != nil {
+			s.Log.Crit("Failed to put key-value", "err", err)
+		}
+	}
+}
+
+// ForEachTraceAddressBlock iterates the blocks in the range [from, to], in call traces of which the address appears.
+func (s *Store) ForEachTraceAddressBlock(addr common.Address, from, to idx.Block, onBlock func(n idx.Block) bool) {
+	it := s.table.TraceAddrs.NewIterator(addr.Bytes(), from.Bytes())
+	defer it.Release()
+	for it.Next() {
+		n := idx.BytesToBlock(it.Key()[common.AddressLength:])
+		if n > to || !onBlock(n) {
+			return
+		}
+	}
+}
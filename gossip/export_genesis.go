@@ -0,0 +1,173 @@
+package gossip
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+
+	"github.com/Fantom-foundation/go-opera/gossip/sfcapi"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera/genesis"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/gpos"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+)
+
+// ExportGenesis writes a snapshot of the network right after the epoch was sealed into the genesis store.
+// The genesis contains the raw EVM state of the last block of the epoch, the block itself,
+// the validators of the next epoch and the delegations which are read from the SFC state.
+// A network started from the genesis continues from the next epoch.
+func (s *Store) ExportGenesis(sealedEpoch idx.Epoch, genStore *genesisstore.Store) error {
+	bs, es := s.GetHistoryBlockEpochState(sealedEpoch + 1)
+	if bs == nil || es == nil {
+		return fmt.Errorf("epoch %d state isn't found", sealedEpoch)
+	}
+	block := s.GetFullBlock(bs.LastBlock.Idx)
+	if block == nil {
+		return fmt.Errorf("block %d isn't found", bs.LastBlock.Idx)
+	}
+	statedb, err := s.evm.StateDB(bs.FinalizedStateRoot)
+	if err != nil {
+		return err
+	}
+
+	// EVM state
+	items, err := s.exportRawEvmState(statedb, genStore)
+	if err != nil {
+		return err
+	}
+	s.Log.Info("Exported EVM state", "root", bs.FinalizedStateRoot.String(), "items", items)
+
+	// the last block of the epoch
+	internalTxsNum := len(block.Block.InternalTxs)
+	genStore.SetBlock(block.Number, genesis.Block{
+		Time:        block.Block.Time,
+		Atropos:     block.Block.Atropos,
+		Txs:         block.Txs[internalTxsNum:],
+		InternalTxs: block.Txs[:internalTxsNum],
+		Root:        block.Block.Root,
+		Receipts:    block.Receipts,
+	})
+
+	// validators and delegations
+	sfcState := newSfcStateReader(statedb, &block.Header, &EvmStateReader{store: s}, es.Rules)
+	validators := make(gpos.Validators, 0, len(es.ValidatorProfiles))
+	for _, v := range es.ValidatorProfiles.SortedArray() {
+		sfcValidator, err := sfcState.Validator(v.ValidatorID)
+		if err != nil {
+			return err
+		}
+		validators = append(validators, gpos.Validator{
+			ID:               v.ValidatorID,
+			Address:          sfcValidator.Auth,
+			PubKey:           v.Validator.PubKey,
+			CreationTime:     inter.FromUnix(sfcValidator.CreatedTime.Int64()),
+			CreationEpoch:    idx.Epoch(sfcValidator.CreatedEpoch.Uint64()),
+			DeactivatedTime:  inter.FromUnix(sfcValidator.DeactivatedTime.Int64()),
+			DeactivatedEpoch: idx.Epoch(sfcValidator.DeactivatedEpoch.Uint64()),
+			Status:           sfcValidator.Status.Uint64(),
+		})
+	}
+	if len(validators) == 0 {
+		return errors.New("no validators")
+	}
+	delegations := 0
+	var delegationsErr error
+	s.sfcapi.ForEachSfcDelegation(func(it sfcapi.SfcDelegationAndID) {
+		if delegationsErr != nil {
+			return
+		}
+		var d genesis.Delegation
+		d, delegationsErr = readSfcDelegation(sfcState, it.ID.Delegator, it.ID.StakerID)
+		if delegationsErr != nil || d.Stake.Sign() == 0 {
+			return
+		}
+		genStore.SetDelegation(it.ID.Delegator, it.ID.StakerID, d)
+		delegations++
+	})
+	if delegationsErr != nil {
+		return delegationsErr
+	}
+
+	totalSupply, err := sfcState.TotalSupply()
+	if err != nil {
+		return err
+	}
+	owner, err := sfcState.Owner()
+	if err != nil {
+		return err
+	}
+
+	var extra []byte
+	if h := s.GetGenesisHash(); h != nil {
+		extra = h.Bytes()
+	}
+	genStore.SetRules(es.Rules)
+	genStore.SetMetadata(genesisstore.Metadata{
+		Validators:    validators,
+		FirstEpoch:    sealedEpoch + 1,
+		Time:          block.Block.Time + 1,
+		PrevEpochTime: es.EpochStart,
+		ExtraData:     extra,
+		DriverOwner:   owner,
+		TotalSupply:   totalSupply,
+	})
+	s.Log.Info("Exported genesis", "epoch", sealedEpoch, "block", block.Number, "validators", len(validators), "delegations", delegations)
+
+	return nil
+}
+
+// exportRawEvmState copies all the trie nodes and contract codes of the state into the genesis store.
+func (s *Store) exportRawEvmState(statedb *state.StateDB, genStore *genesisstore.Store) (items int, err error) {
+	db := s.evm.EvmTable()
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+		if it.Hash == (common.Hash{}) {
+			// embedded node
+			continue
+		}
+		blob := rawdb.ReadTrieNode(db, it.Hash)
+		if len(blob) == 0 {
+			blob = rawdb.ReadCode(db, it.Hash)
+		}
+		if len(blob) == 0 {
+			return items, fmt.Errorf("missing state item %s", it.Hash.String())
+		}
+		genStore.SetRawEvmItem(common.CopyBytes(it.Hash[:]), blob)
+		items++
+		if items%1000000 == 0 {
+			s.Log.Info("Exporting EVM state", "items", items)
+		}
+	}
+	return items, it.Error
+}
+
+// readSfcDelegation reads a delegation from the SFC state.
+func readSfcDelegation(sfcState *sfcStateReader, delegator common.Address, to idx.ValidatorID) (genesis.Delegation, error) {
+	stake, err := sfcState.Stake(delegator, to)
+	if err != nil {
+		return genesis.Delegation{}, err
+	}
+	lockup, err := sfcState.Lockup(delegator, to)
+	if err != nil {
+		return genesis.Delegation{}, err
+	}
+	rewards, err := sfcState.RewardsStash(delegator, to)
+	if err != nil {
+		return genesis.Delegation{}, err
+	}
+	return genesis.Delegation{
+		Stake:           stake,
+		Rewards:         rewards,
+		LockedStake:     lockup.LockedStake,
+		LockupFromEpoch: idx.Epoch(lockup.FromEpoch.Uint64()),
+		// lockup timestamps are passed to SFC as is, see drivercall.SetGenesisDelegation
+		LockupEndTime:      inter.Timestamp(lockup.EndTime.Uint64()),
+		LockupDuration:     inter.Timestamp(lockup.Duration.Uint64()),
+		EarlyUnlockPenalty: new(big.Int),
+	}, nil
+}
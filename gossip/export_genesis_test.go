@@ -0,0 +1,49 @@
+package gossip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestExportGenesis(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv()
+	defer env.Close()
+
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 2, utils.ToFtm(100)))
+	env.ApplyBlock(nextEpoch)
+	// wait for the EVM state commit
+	env.blockProcWg.Wait()
+	sealedEpoch := env.store.GetEpoch() - 1
+	bs, es := env.store.GetHistoryBlockEpochState(sealedEpoch + 1)
+	require.NotNil(bs)
+	require.NotNil(es)
+
+	genStore := genesisstore.NewMemStore()
+	require.NoError(env.store.ExportGenesis(sealedEpoch, genStore))
+	g := genStore.GetGenesis()
+	require.Equal(sealedEpoch+1, g.FirstEpoch)
+	require.Equal(genesisStakers, len(g.Validators))
+
+	store := NewMemStore()
+	defer store.Close()
+	_, err := store.ApplyGenesis(DefaultBlockProc(g), g)
+	require.NoError(err)
+
+	// the network continues from the next epoch with the same validators
+	require.Equal(sealedEpoch+1, store.GetEpoch())
+	require.Equal(bs.LastBlock.Idx+1, store.GetLatestBlockIndex())
+	validators, _ := store.GetEpochValidators()
+	require.Equal(es.Validators.SortedIDs(), validators.SortedIDs())
+	require.Equal(es.Validators.SortedWeights(), validators.SortedWeights())
+
+	// the EVM state is applied as is
+	require.Equal(bs.FinalizedStateRoot, store.GetBlockState().FinalizedStateRoot)
+}
@@ -37,6 +37,9 @@ var (
 	deadline = 5 * time.Minute // consider a filter inactive if it has not been polled for within deadline
 )
 
+// defaultLogsPageSize is the page size of eth_getLogsPaged if neither the request nor the results limit sets it
+const defaultLogsPageSize = 1000
+
 // filter is a helper struct that holds meta information over the filter type
 // and associated subscription in the event system.
 type filter struct {
@@ -52,6 +55,7 @@ type filter struct {
 // information related to the Ethereum protocol such als blocks, transactions and logs.
 type PublicFilterAPI struct {
 	backend   Backend
+	config    Config
 	chainDb   ethdb.Database
 	events    *EventSystem
 	filtersMu sync.Mutex
@@ -59,9 +63,10 @@ type PublicFilterAPI struct {
 }
 
 // NewPublicFilterAPI returns a new PublicFilterAPI instance.
-func NewPublicFilterAPI(backend Backend) *PublicFilterAPI {
+func NewPublicFilterAPI(backend Backend, config Config) *PublicFilterAPI {
 	api := &PublicFilterAPI{
 		backend: backend,
+		config:  config,
 		chainDb: backend.ChainDb(),
 		events:  NewEventSystem(backend),
 		filters: make(map[rpc.ID]*filter),
@@ -320,6 +325,37 @@ func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getlogs
 func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+	ctx, cancel := api.withTimeout(ctx)
+	defer cancel()
+
+	// Run the filter and return all the logs
+	logs, err := api.newFilter(crit).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return returnLogs(logs), err
+}
+
+// GetLogsPaged returns a page of the logs matching the given argument that are stored within the state,
+// and the cursor of the next page if there are more logs. The logs which follow the cursor are returned,
+// or the first ones if the cursor is nil. The page size is limited by the server.
+// A timed out query returns the logs found so far and the cursor of the last scanned block.
+func (api *PublicFilterAPI) GetLogsPaged(ctx context.Context, crit FilterCriteria, cursor *LogsCursor, limit *hexutil.Uint) (*LogsPage, error) {
+	ctx, cancel := api.withTimeout(ctx)
+	defer cancel()
+
+	size := api.config.ResultsLimit
+	if limit != nil && (size == 0 || int(*limit) < size) {
+		size = int(*limit)
+	}
+	if size == 0 {
+		size = defaultLogsPageSize
+	}
+	return api.newFilter(crit).LogsPage(ctx, cursor, size)
+}
+
+// newFilter creates the filter of the criteria limited by the API config.
+func (api *PublicFilterAPI) newFilter(crit FilterCriteria) *Filter {
 	var filter *Filter
 	if crit.BlockHash != nil {
 		// Block filter requested, construct a single-shot filter
@@ -337,12 +373,17 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
 	}
-	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
-	if err != nil {
-		return nil, err
+	filter.rangeLimit = api.config.RangeLimit
+	filter.resultsLimit = api.config.ResultsLimit
+	return filter
+}
+
+// withTimeout limits the duration of the query if the timeout is configured.
+func (api *PublicFilterAPI) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if api.config.Timeout == 0 {
+		return context.WithCancel(ctx)
 	}
-	return returnLogs(logs), err
+	return context.WithTimeout(ctx, api.config.Timeout)
 }
 
 // UninstallFilter removes the filter with the given filter id.
@@ -375,25 +416,11 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 		return nil, fmt.Errorf("filter not found")
 	}
 
-	var filter *Filter
-	if f.crit.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.backend, *f.crit.BlockHash, f.crit.Addresses, f.crit.Topics)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if f.crit.FromBlock != nil {
-			begin = f.crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if f.crit.ToBlock != nil {
-			end = f.crit.ToBlock.Int64()
-		}
-		// Construct the range filter
-		filter = NewRangeFilter(api.backend, begin, end, f.crit.Addresses, f.crit.Topics)
-	}
+	ctx, cancel := api.withTimeout(ctx)
+	defer cancel()
+
 	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	logs, err := api.newFilter(f.crit).Logs(ctx)
 	if err != nil {
 		return nil, err
 	}
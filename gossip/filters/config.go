@@ -0,0 +1,25 @@
+package filters
+
+import (
+	"time"
+)
+
+// Config is the configuration of the logs filtering API.
+type Config struct {
+	// RangeLimit is the maximum number of blocks in the range of eth_getLogs and eth_getFilterLogs, zero means no limit
+	RangeLimit uint64
+	// ResultsLimit is the maximum number of logs returned by eth_getLogs and eth_getFilterLogs,
+	// and the maximum page size of eth_getLogsPaged, zero means no limit
+	ResultsLimit int
+	// Timeout is the maximum duration of a logs query, zero means no limit
+	Timeout time.Duration
+}
+
+// DefaultConfig returns the default configurations for the logs filtering API.
+func DefaultConfig() Config {
+	return Config{
+		RangeLimit:   100000,
+		ResultsLimit: 10000,
+		Timeout:      time.Minute,
+	}
+}
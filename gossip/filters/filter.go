@@ -19,11 +19,16 @@ package filters
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	notify "github.com/ethereum/go-ethereum/event"
@@ -45,6 +50,10 @@ type Backend interface {
 	SubscribeLogsEvent(ch chan<- []*types.Log) notify.Subscription
 
 	EvmLogIndex() *topicsdb.Index
+
+	GetBlockBloom(ctx context.Context, blockNr rpc.BlockNumber) (*types.Bloom, error)
+	BloomStatus() (uint64, uint64)
+	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
 // Filter can be used to retrieve and filter logs.
@@ -57,14 +66,41 @@ type Filter struct {
 
 	block      common.Hash // Block hash if filtering a single block
 	begin, end int64       // Range interval if filtering multiple blocks
+
+	matcher *bloombits.Matcher
+
+	rangeLimit   uint64 // Maximum number of blocks in the range, zero if unlimited
+	resultsLimit int    // Maximum number of the found logs, zero if unlimited
+	results      int    // Number of the logs found by the current query
 }
 
 // NewRangeFilter creates a new filter which inspects the blocks to
 // figure out whether a particular block is interesting or not.
 func NewRangeFilter(backend Backend, begin, end int64, addresses []common.Address, topics [][]common.Hash) *Filter {
+	// Flatten the address and topic filter clauses into a single bloombits filter
+	// system. Since the bloombits are not positional, nil topics are permitted,
+	// which get flattened into a nil byte slice.
+	var filters [][][]byte
+	if len(addresses) > 0 {
+		filter := make([][]byte, len(addresses))
+		for i, address := range addresses {
+			filter[i] = address.Bytes()
+		}
+		filters = append(filters, filter)
+	}
+	for _, topicList := range topics {
+		filter := make([][]byte, len(topicList))
+		for i, topic := range topicList {
+			filter[i] = topic.Bytes()
+		}
+		filters = append(filters, filter)
+	}
+	size, _ := backend.BloomStatus()
+
 	// Create a generic filter and convert it into a range filter
 	filter := newFilter(backend, addresses, topics)
 
+	filter.matcher = bloombits.NewMatcher(size, filters)
 	filter.begin = begin
 	filter.end = end
 
@@ -121,12 +157,215 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
+	if f.rangeLimit != 0 && f.begin >= 0 && end >= uint64(f.begin) && end-uint64(f.begin) >= f.rangeLimit {
+		return nil, fmt.Errorf("block range is greater than the limit of %d blocks", f.rangeLimit)
+	}
+	f.results = 0
 
-	if isEmpty(f.topics) {
+	if !isEmpty(f.topics) {
+		return f.indexedLogs(ctx, int64(end))
+	}
+	if len(f.addresses) == 0 || f.begin < 0 {
 		return f.unindexedLogs(ctx, int64(end))
 	}
 
-	return f.indexedLogs(ctx, int64(end))
+	// Gather all indexed logs, and finish with non indexed ones
+	var logs []*types.Log
+	size, sections := f.backend.BloomStatus()
+	if indexed := sections * size; indexed > uint64(f.begin) {
+		var err error
+		if indexed > end {
+			logs, err = f.bloomIndexedLogs(ctx, end)
+		} else {
+			logs, err = f.bloomIndexedLogs(ctx, indexed-1)
+		}
+		if err != nil {
+			return logs, err
+		}
+	}
+	rest, err := f.unindexedLogs(ctx, int64(end))
+	logs = append(logs, rest...)
+	return logs, err
+}
+
+// logsPageChunk is the number of blocks which are scanned at once by LogsPage, the timeout is checked between the chunks.
+var logsPageChunk = uint64(1000)
+
+// LogsCursor is the position of a log in the logs index, the next page starts after it.
+type LogsCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	TxIndex     hexutil.Uint   `json:"transactionIndex"`
+	LogIndex    hexutil.Uint   `json:"logIndex"`
+	// BlockEnd is set if the cursor points to the end of the block instead of a log,
+	// i.e. the block is scanned completely
+	BlockEnd bool `json:"blockEnd,omitempty"`
+}
+
+// LogsPage is a page of the logs matching the filter criteria.
+type LogsPage struct {
+	Logs []*types.Log `json:"logs"`
+	// Next is the cursor of the next page, nil if there are no more logs
+	Next *LogsCursor `json:"next"`
+}
+
+// LogsPage returns up to limit logs matching the filter criteria which follow the cursor, or the first ones
+// if the cursor is nil. The logs index is iterated in the order of its IDs from the cursor block, and the logs
+// of a block are returned in the order of their block positions.
+// If the query is timed out, the page holds the logs of the completely scanned blocks and the cursor points
+// to the end of the last of them, so the next page continues the scan even if no logs were found.
+func (f *Filter) LogsPage(ctx context.Context, cursor *LogsCursor, limit int) (*LogsPage, error) {
+	if f.block != common.Hash(hash.Zero) {
+		header, err := f.backend.HeaderByHash(ctx, f.block)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, errors.New("unknown block")
+		}
+		f.begin, f.end = header.Number.Int64(), header.Number.Int64()
+	}
+	header, _ := f.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil {
+		return &LogsPage{Logs: []*types.Log{}}, nil
+	}
+	head := header.Number.Uint64()
+	begin, end := uint64(f.begin), uint64(f.end)
+	if f.begin < 0 {
+		begin = head
+	}
+	if f.end < 0 {
+		end = head
+	}
+
+	addresses := make([]common.Hash, len(f.addresses))
+	for i, addr := range f.addresses {
+		addresses[i] = addr.Hash()
+	}
+	pattern := append([][]common.Hash{addresses}, f.topics...)
+	if isEmpty(pattern) {
+		return nil, errors.New("paged logs query requires addresses or topics")
+	}
+	if cursor != nil && cursor.BlockEnd && uint64(cursor.BlockNumber) >= begin {
+		begin = uint64(cursor.BlockNumber) + 1
+		cursor = nil
+	} else if cursor != nil && uint64(cursor.BlockNumber) >= begin {
+		begin = uint64(cursor.BlockNumber)
+	} else {
+		cursor = nil
+	}
+
+	var (
+		logs      = make([]*types.Log, 0, limit)
+		blockLogs []*types.Log
+		more      bool
+		// scanned is the last block whose logs are processed completely
+		scanned = int64(begin) - 1
+	)
+	flush := func() {
+		if len(blockLogs) == 0 {
+			return
+		}
+		sort.Slice(blockLogs, func(i, j int) bool {
+			return blockLogs[i].Index < blockLogs[j].Index
+		})
+		for _, l := range blockLogs {
+			if cursor != nil && l.BlockNumber == uint64(cursor.BlockNumber) && l.Index <= uint(cursor.LogIndex) {
+				continue
+			}
+			if len(logs) >= limit {
+				more = true
+				break
+			}
+			logs = append(logs, l)
+		}
+		if !more {
+			scanned = int64(blockLogs[0].BlockNumber)
+		}
+		blockLogs = blockLogs[:0]
+	}
+	onLog := func(l *types.Log) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if len(blockLogs) != 0 && blockLogs[0].BlockNumber != l.BlockNumber {
+			flush()
+			if len(logs) >= limit {
+				more = true
+				return false
+			}
+		}
+		blockLogs = append(blockLogs, l)
+		return true
+	}
+	for from := begin; from <= end && !more && ctx.Err() == nil; from += logsPageChunk {
+		to := from + logsPageChunk - 1
+		if to > end {
+			to = end
+		}
+		err := f.backend.EvmLogIndex().ForEachInBlocks(idx.Block(from), idx.Block(to), pattern, onLog)
+		if err != nil {
+			return nil, err
+		}
+		if more || ctx.Err() != nil {
+			// the logs of the interrupted block are incomplete
+			break
+		}
+		flush()
+		if !more {
+			scanned = int64(to)
+		}
+	}
+	interrupted := !more && ctx.Err() != nil && scanned < int64(end)
+	if interrupted && scanned < int64(begin) {
+		return nil, ctx.Err()
+	}
+	if err := f.setTxIndexes(ctx, logs); err != nil {
+		return nil, err
+	}
+
+	page := &LogsPage{Logs: logs}
+	if interrupted {
+		page.Next = &LogsCursor{
+			BlockNumber: hexutil.Uint64(scanned),
+			BlockEnd:    true,
+		}
+	} else if more {
+		last := logs[len(logs)-1]
+		page.Next = &LogsCursor{
+			BlockNumber: hexutil.Uint64(last.BlockNumber),
+			TxIndex:     hexutil.Uint(last.TxIndex),
+			LogIndex:    hexutil.Uint(last.Index),
+		}
+	}
+	return page, nil
+}
+
+// setTxIndexes sets the block positions of the logs transactions, which aren't stored in the logs index.
+// The position is found by the log index in the block, as the receipts hold the logs in the order of the transactions.
+func (f *Filter) setTxIndexes(ctx context.Context, logs []*types.Log) error {
+	var (
+		block    common.Hash
+		receipts types.Receipts
+	)
+	for _, l := range logs {
+		if receipts == nil || l.BlockHash != block {
+			var err error
+			receipts, err = f.backend.GetReceipts(ctx, l.BlockHash)
+			if err != nil {
+				return err
+			}
+			block = l.BlockHash
+		}
+		first := uint(0)
+		for i, r := range receipts {
+			if l.Index < first+uint(len(r.Logs)) {
+				l.TxIndex = uint(i)
+				break
+			}
+			first += uint(len(r.Logs))
+		}
+	}
+	return nil
 }
 
 // indexedLogs returns the logs matching the filter criteria based on topics index.
@@ -140,26 +379,95 @@ func (f *Filter) indexedLogs(ctx context.Context, end int64) (logs []*types.Log,
 	pattern[0] = addresses
 	pattern = append(pattern, f.topics...)
 
-	err = f.backend.EvmLogIndex().ForEach(pattern, func(l *types.Log) bool {
-		logs = append(logs, l)
-		return true
+	from := idx.Block(0)
+	if f.begin > 0 {
+		from = idx.Block(f.begin)
+	}
+	var limitErr error
+	err = f.backend.EvmLogIndex().ForEachInBlocks(from, idx.Block(end), pattern, func(l *types.Log) bool {
+		logs, limitErr = f.appendLogs(ctx, logs, l)
+		return limitErr == nil
 	})
 	if err != nil {
 		return
 	}
+	if limitErr != nil {
+		return logs, limitErr
+	}
 
 	logs = filterLogs(logs, big.NewInt(f.begin), big.NewInt(end), nil, nil)
 	return
 }
 
-// indexedLogs returns the logs matching the filter criteria based on raw block
-// iteration.
+// bloomIndexedLogs returns the logs matching the filter criteria based on the
+// sectioned bloom bits index.
+func (f *Filter) bloomIndexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+	// Create a matcher session and request servicing from the backend
+	matches := make(chan uint64, 64)
+
+	session, err := f.matcher.Start(ctx, uint64(f.begin), end, matches)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	f.backend.ServiceFilter(ctx, session)
+
+	// Iterate over the matches until exhausted or context closed
+	var logs []*types.Log
+
+	for {
+		select {
+		case number, ok := <-matches:
+			// Abort if all matches have been fulfilled
+			if !ok {
+				err := session.Error()
+				if err == nil {
+					f.begin = int64(end) + 1
+				}
+				return logs, err
+			}
+			f.begin = int64(number) + 1
+
+			// Retrieve the suggested block and pull any truly matching logs
+			header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if header == nil || err != nil {
+				return logs, err
+			}
+			found, err := f.checkMatches(ctx, header)
+			if err != nil {
+				return logs, err
+			}
+			logs, err = f.appendLogs(ctx, logs, found...)
+			if err != nil {
+				return logs, err
+			}
+
+		case <-ctx.Done():
+			return logs, ctx.Err()
+		}
+	}
+}
+
+// unindexedLogs returns the logs matching the filter criteria based on raw block
+// iteration, skipping the blocks whose stored blooms don't match the filter.
 func (f *Filter) unindexedLogs(ctx context.Context, end int64) (logs []*types.Log, err error) {
 	var (
 		header *evmcore.EvmHeader
+		bloom  *types.Bloom
 		found  []*types.Log
 	)
 	for ; f.begin <= end; f.begin++ {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		bloom, err = f.backend.GetBlockBloom(ctx, rpc.BlockNumber(f.begin))
+		if err != nil {
+			return
+		}
+		if bloom != nil && !bloomFilter(*bloom, f.addresses, f.topics) {
+			continue
+		}
 		header, err = f.backend.HeaderByNumber(ctx, rpc.BlockNumber(f.begin))
 		if header == nil || err != nil {
 			return
@@ -168,11 +476,23 @@ func (f *Filter) unindexedLogs(ctx context.Context, end int64) (logs []*types.Lo
 		if err != nil {
 			return
 		}
-		logs = append(logs, found...)
+		logs, err = f.appendLogs(ctx, logs, found...)
+		if err != nil {
+			return
+		}
 	}
 	return
 }
 
+// appendLogs appends the found logs, and fails if the results limit is exceeded or the query is timed out.
+func (f *Filter) appendLogs(ctx context.Context, logs []*types.Log, found ...*types.Log) ([]*types.Log, error) {
+	f.results += len(found)
+	if f.resultsLimit != 0 && f.results > f.resultsLimit {
+		return logs, fmt.Errorf("query returned more than %d results", f.resultsLimit)
+	}
+	return append(logs, found...), ctx.Err()
+}
+
 // blockLogs returns the logs matching the filter criteria within a single block.
 func (f *Filter) blockLogs(ctx context.Context, header *evmcore.EvmHeader) (logs []*types.Log, err error) {
 	found, err := f.checkMatches(ctx, header)
@@ -217,6 +537,35 @@ func (f *Filter) checkMatches(ctx context.Context, header *evmcore.EvmHeader) (l
 	return nil, nil
 }
 
+func bloomFilter(bloom types.Bloom, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		var included bool
+		for _, addr := range addresses {
+			if types.BloomLookup(bloom, addr) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, sub := range topics {
+		included := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if types.BloomLookup(bloom, topic) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return true
+}
+
 func includes(addresses []common.Address, a common.Address) bool {
 	for _, addr := range addresses {
 		if addr == a {
@@ -25,7 +25,9 @@ import (
 
 	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -46,6 +48,7 @@ type testBackend struct {
 	blocksFeed *notify.Feed
 	txsFeed    *notify.Feed
 	logsFeed   *notify.Feed
+	sections   uint64
 }
 
 func newTestBackend() *testBackend {
@@ -135,6 +138,45 @@ func (b *testBackend) EvmLogIndex() *topicsdb.Index {
 	return b.logIndex
 }
 
+func (b *testBackend) GetBlockBloom(ctx context.Context, blockNr rpc.BlockNumber) (*types.Bloom, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	h := rawdb.ReadHeader(b.db, header.Hash, header.Number.Uint64())
+	return &h.Bloom, nil
+}
+
+func (b *testBackend) BloomStatus() (uint64, uint64) {
+	return params.BloomBitsBlocks, b.sections
+}
+
+func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
+	requests := make(chan chan *bloombits.Retrieval)
+
+	go session.Multiplex(16, 0, requests)
+	go func() {
+		for {
+			// Wait for a service request or a shutdown
+			select {
+			case <-ctx.Done():
+				return
+
+			case request := <-requests:
+				task := <-request
+
+				task.Bitsets = make([][]byte, len(task.Sections))
+				for i, section := range task.Sections {
+					head := rawdb.ReadCanonicalHash(b.db, (section+1)*params.BloomBitsBlocks-1)
+					compVector, _ := rawdb.ReadBloomBits(b.db, task.Bit, section, head)
+					task.Bitsets[i], _ = bitutil.DecompressBytes(compVector, int(params.BloomBitsBlocks/8))
+				}
+				request <- task
+			}
+		}
+	}()
+}
+
 // TestBlockSubscription tests if a block subscription returns block hashes for posted chain notify.
 // It creates multiple subscriptions:
 // - one at the start and should receive all posted chain events and a second (blockHashes)
@@ -145,7 +187,7 @@ func TestBlockSubscription(t *testing.T) {
 
 	var (
 		backend = newTestBackend()
-		api     = NewPublicFilterAPI(backend)
+		api     = NewPublicFilterAPI(backend, DefaultConfig())
 
 		net         = makegenesis.FakeGenesisStore(5, big.NewInt(0), big.NewInt(1)).GetGenesis()
 		statedb, _  = state.New(common.Hash{}, state.NewDatabase(backend.db), nil)
@@ -207,7 +249,7 @@ func TestPendingTxFilter(t *testing.T) {
 
 	var (
 		backend = newTestBackend()
-		api     = NewPublicFilterAPI(backend)
+		api     = NewPublicFilterAPI(backend, DefaultConfig())
 
 		transactions = []*types.Transaction{
 			types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
@@ -261,7 +303,7 @@ func TestPendingTxFilter(t *testing.T) {
 func TestLogFilterCreation(t *testing.T) {
 	var (
 		backend = newTestBackend()
-		api     = NewPublicFilterAPI(backend)
+		api     = NewPublicFilterAPI(backend, DefaultConfig())
 
 		testCases = []struct {
 			crit    FilterCriteria
@@ -304,7 +346,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 
 	var (
 		backend = newTestBackend()
-		api     = NewPublicFilterAPI(backend)
+		api     = NewPublicFilterAPI(backend, DefaultConfig())
 	)
 
 	// different situations where log filter creation should fail.
@@ -325,7 +367,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 func TestInvalidGetLogsRequest(t *testing.T) {
 	var (
 		backend   = newTestBackend()
-		api       = NewPublicFilterAPI(backend)
+		api       = NewPublicFilterAPI(backend, DefaultConfig())
 		blockHash = common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111")
 	)
 
@@ -349,7 +391,7 @@ func TestLogFilter(t *testing.T) {
 
 	var (
 		backend = newTestBackend()
-		api     = NewPublicFilterAPI(backend)
+		api     = NewPublicFilterAPI(backend, DefaultConfig())
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
 		secondAddr     = common.HexToAddress("0x2222222222222222222222222222222222222222")
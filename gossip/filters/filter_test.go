@@ -21,12 +21,16 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/Fantom-foundation/lachesis-base/kvdb/table"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -258,3 +262,215 @@ func TestFilters(t *testing.T) {
 	}
 
 }
+
+func TestFiltersBloomBits(t *testing.T) {
+	var (
+		backend = newTestBackend()
+		addr1   = common.BytesToAddress([]byte("jeff"))
+		addr2   = common.BytesToAddress([]byte("ethereum"))
+	)
+
+	// the first section is indexed, the last blocks are filtered with the blocks blooms
+	genesis := core.GenesisBlockForTesting(backend.db, addr1, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), backend.db, int(params.BloomBitsBlocks)+10, func(i int, gen *core.BlockGen) {
+		switch i {
+		case 33, 3000, int(params.BloomBitsBlocks) + 5:
+			gen.AddUncheckedReceipt(makeReceipt(addr1))
+			gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil))
+		case 34:
+			gen.AddUncheckedReceipt(makeReceipt(addr2))
+			gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x2"), big.NewInt(1), 1, big.NewInt(1), nil))
+		}
+	})
+	gen, err := bloombits.NewGenerator(uint(params.BloomBitsBlocks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range append([]*types.Block{genesis}, chain[:params.BloomBitsBlocks-1]...) {
+		if err := gen.AddBloom(uint(block.NumberU64()), block.Bloom()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	head := chain[params.BloomBitsBlocks-2].Hash()
+	for bit := uint(0); bit < types.BloomBitLength; bit++ {
+		bits, err := gen.Bitset(bit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rawdb.WriteBloomBits(backend.db, bit, 0, head, bitutil.CompressBytes(bits))
+	}
+	backend.sections = 1
+	for i, block := range chain {
+		rawdb.WriteBlock(backend.db, block)
+		rawdb.WriteCanonicalHash(backend.db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(backend.db, block.Hash())
+		rawdb.WriteReceipts(backend.db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+
+	for _, tt := range []struct {
+		begin, end int64
+		addresses  []common.Address
+		want       int
+	}{
+		{0, -1, []common.Address{addr1}, 3},
+		{0, -1, []common.Address{addr1, addr2}, 4},
+		{36, 3001, []common.Address{addr1, addr2}, 1},
+		{int64(params.BloomBitsBlocks), -1, []common.Address{addr1}, 1},
+		{0, -1, []common.Address{common.BytesToAddress([]byte("failmenow"))}, 0},
+	} {
+		logs, err := NewRangeFilter(backend, tt.begin, tt.end, tt.addresses, nil).Logs(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != tt.want {
+			t.Errorf("range [%d, %d]: expected %d logs, got %d", tt.begin, tt.end, tt.want, len(logs))
+		}
+	}
+}
+
+func TestLogsPaged(t *testing.T) {
+	var (
+		backend = newTestBackend()
+		addr    = common.BytesToAddress([]byte("jeff"))
+		topic   = common.BytesToHash([]byte("topic"))
+	)
+
+	// block 2 has 3 logs of 2 txs, blocks 5 and 7 have 1 and 2 logs
+	genesis := core.GenesisBlockForTesting(backend.db, addr, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), backend.db, 10, func(i int, gen *core.BlockGen) {
+		txs := map[int][]int{1: {2, 1}, 4: {1}, 6: {2}}[i]
+		for j, logs := range txs {
+			receipt := types.NewReceipt(nil, false, 0)
+			for k := 0; k < logs; k++ {
+				receipt.Logs = append(receipt.Logs, &types.Log{Address: addr, Topics: []common.Hash{topic}})
+			}
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(uint64(i*10+j), common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil))
+		}
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(backend.db, block)
+		rawdb.WriteCanonicalHash(backend.db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(backend.db, block.Hash())
+		rawdb.WriteReceipts(backend.db, block.Hash(), block.NumberU64(), receipts[i])
+		logIndex := uint(0)
+		for j, r := range receipts[i] {
+			for _, l := range r.Logs {
+				l.BlockNumber = block.NumberU64()
+				l.BlockHash = block.Hash()
+				l.TxHash = block.Transactions()[j].Hash()
+				l.Index = logIndex
+				logIndex++
+			}
+			backend.logIndex.MustPush(r.Logs...)
+		}
+	}
+
+	api := NewPublicFilterAPI(backend, Config{RangeLimit: 6, ResultsLimit: 4})
+	crit := FilterCriteria{
+		FromBlock: big.NewInt(0),
+		Addresses: []common.Address{addr},
+	}
+
+	// the limits of eth_getLogs
+	if _, err := api.GetLogs(context.Background(), crit); err == nil {
+		t.Error("expected the range limit error")
+	}
+	crit.ToBlock = big.NewInt(4)
+	if logs, err := api.GetLogs(context.Background(), crit); err != nil || len(logs) != 3 {
+		t.Errorf("expected 3 logs, got %d (%v)", len(logs), err)
+	}
+	crit.FromBlock, crit.ToBlock = big.NewInt(2), big.NewInt(7)
+	if _, err := api.GetLogs(context.Background(), crit); err == nil {
+		t.Error("expected the results limit error")
+	}
+
+	// the pages don't depend on the range limit
+	crit.FromBlock, crit.ToBlock = big.NewInt(0), nil
+	limit := hexutil.Uint(2)
+	var (
+		cursor *LogsCursor
+		got    [][3]uint64
+		pages  int
+	)
+	for {
+		page, err := api.GetLogsPaged(context.Background(), crit, cursor, &limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range page.Logs {
+			got = append(got, [3]uint64{l.BlockNumber, uint64(l.TxIndex), uint64(l.Index)})
+		}
+		pages++
+		if page.Next == nil {
+			break
+		}
+		cursor = page.Next
+	}
+	want := [][3]uint64{{2, 0, 0}, {2, 0, 1}, {2, 1, 2}, {5, 0, 0}, {7, 0, 0}, {7, 0, 1}}
+	if !reflect.DeepEqual(got, want) || pages != 3 {
+		t.Errorf("expected %v in 3 pages, got %v in %d pages", want, got, pages)
+	}
+
+	// the page size is limited by the server
+	limit = 100
+	page, err := api.GetLogsPaged(context.Background(), crit, nil, &limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Logs) != 4 || page.Next == nil || *page.Next != (LogsCursor{BlockNumber: 5}) {
+		t.Errorf("expected 4 logs and the cursor of block 5, got %d logs and %v", len(page.Logs), page.Next)
+	}
+
+	// the timed out page continues from the last scanned block, even if no logs are found before the timeout
+	defer func(chunk uint64) {
+		logsPageChunk = chunk
+	}(logsPageChunk)
+	logsPageChunk = 1
+	limit = 2
+	emptyPages := 0
+	for checks := 1; checks <= 30; checks++ {
+		ctx := &expiringContext{Context: context.Background(), checks: checks}
+		page, err := api.newFilter(crit).LogsPage(ctx, nil, int(limit))
+		if err != nil {
+			// nothing is scanned before the timeout
+			continue
+		}
+		got = nil
+		for {
+			for _, l := range page.Logs {
+				got = append(got, [3]uint64{l.BlockNumber, uint64(l.TxIndex), uint64(l.Index)})
+			}
+			if page.Next == nil {
+				break
+			}
+			if page.Next.BlockEnd && len(page.Logs) == 0 {
+				emptyPages++
+			}
+			page, err = api.GetLogsPaged(context.Background(), crit, page.Next, &limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("timeout after %d checks: expected %v, got %v", checks, want, got)
+		}
+	}
+	if emptyPages == 0 {
+		t.Error("expected the cursor of an empty timed out page")
+	}
+}
+
+// expiringContext is expired after its error is checked the given number of times.
+type expiringContext struct {
+	context.Context
+	checks int
+}
+
+func (ctx *expiringContext) Err() error {
+	if ctx.checks <= 0 {
+		return context.DeadlineExceeded
+	}
+	ctx.checks--
+	return nil
+}
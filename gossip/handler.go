@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rlp"
+	lru "github.com/hashicorp/golang-lru"
 
 	"github.com/Fantom-foundation/go-opera/eventcheck"
 	"github.com/Fantom-foundation/go-opera/eventcheck/parentlesscheck"
@@ -43,6 +44,9 @@ const (
 	// txChanSize is the size of channel listening to NewTxsNotify.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+
+	// arrivalsCacheSize is the number of the received events whose local arrival time is remembered until the processing.
+	arrivalsCacheSize = 16384
 )
 
 func errResp(code errCode, format string, v ...interface{}) error {
@@ -86,12 +90,14 @@ type ProtocolManager struct {
 	txFetcher  *itemsfetcher.Fetcher
 	processor  *dagprocessor.Processor
 	checkers   *eventcheck.Checkers
+	snapsync   *snapshotSyncer
 
 	msgSemaphore *datasemaphore.DataSemaphore
 
 	store        *Store
-	processEvent func(*inter.EventPayload) error
+	processEvent func(*inter.EventPayload, inter.Timestamp) error
 	engineMu     sync.Locker
+	arrivals     *lru.Cache // event ID -> local arrival time
 
 	notifier             dagNotifier
 	emittedEventsCh      chan *inter.EventPayload
@@ -123,7 +129,7 @@ func NewProtocolManager(
 	engineMu sync.Locker,
 	checkers *eventcheck.Checkers,
 	s *Store,
-	processEvent func(*inter.EventPayload) error,
+	processEvent func(*inter.EventPayload, inter.Timestamp) error,
 	serverPool *serverPool,
 ) (
 	*ProtocolManager,
@@ -135,6 +141,7 @@ func NewProtocolManager(
 			"processingNum", processing.Num, "processingSize", processing.Size,
 			"releasingNum", releasing.Num, "releasingSize", releasing.Size)
 	}
+	arrivals, _ := lru.New(arrivalsCacheSize)
 	// Create the protocol manager with the base fields
 	pm := &ProtocolManager{
 		config:               config,
@@ -143,6 +150,7 @@ func NewProtocolManager(
 		msgSemaphore:         datasemaphore.New(config.Protocol.MsgsSemaphoreLimit, warningFn),
 		store:                s,
 		processEvent:         processEvent,
+		arrivals:             arrivals,
 		checkers:             checkers,
 		peers:                newPeerSet(),
 		serverPool:           serverPool,
@@ -185,13 +193,17 @@ func NewProtocolManager(
 			return p.RequestEventsStream(r)
 		},
 		Suspend: func(_ string) bool {
-			return pm.dagFetcher.Overloaded() || pm.processor.Overloaded()
+			return pm.dagFetcher.Overloaded() || pm.processor.Overloaded() || pm.isSnapshotSyncing()
 		},
 		PeerEpoch: func(peer string) idx.Epoch {
 			p := pm.peers.Peer(peer)
 			if p == nil {
 				return 0
 			}
+			// don't download the events from a peer which has pruned them
+			if !p.CanServeEpoch(pm.store.GetEpoch()) {
+				return 0
+			}
 			return p.progress.Epoch
 		},
 	})
@@ -206,6 +218,12 @@ func NewProtocolManager(
 	return pm, nil
 }
 
+// isSnapshotSyncing returns true if the node is being bootstrapped from a snapshot,
+// the events aren't processed until it's finished.
+func (pm *ProtocolManager) isSnapshotSyncing() bool {
+	return pm.snapsync != nil && pm.snapsync.Active()
+}
+
 func (pm *ProtocolManager) peerMisbehaviour(peer string, err error) bool {
 	if eventcheck.IsBan(err) {
 		log.Warn("Dropping peer due to a misbehaviour", "peer", peer, "err", err)
@@ -269,11 +287,12 @@ func (pm *ProtocolManager) makeProcessor(checkers *eventcheck.Checkers) *dagproc
 			Process: func(_e dag.Event) error {
 				e := _e.(*inter.EventPayload)
 				now := time.Now()
+				arrival := pm.popArrival(e.ID(), now)
 				pm.engineMu.Lock()
 				defer pm.engineMu.Unlock()
 
 				start := time.Now()
-				err := pm.processEvent(e)
+				err := pm.processEvent(e, arrival)
 				if err != nil {
 					return err
 				}
@@ -469,11 +488,17 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.processor.Start()
 	pm.seeder.Start()
 	pm.leecher.Start()
+	if pm.snapsync != nil {
+		pm.snapsync.Start()
+	}
 }
 
 func (pm *ProtocolManager) Stop() {
 	log.Info("Stopping Fantom protocol")
 
+	if pm.snapsync != nil {
+		pm.snapsync.Stop()
+	}
 	pm.leecher.Stop()
 	pm.seeder.Stop()
 	pm.processor.Stop()
@@ -520,6 +545,7 @@ func (pm *ProtocolManager) myProgress() PeerProgress {
 		Epoch:            epoch,
 		LastBlockIdx:     bs.LastBlock.Idx,
 		LastBlockAtropos: bs.LastBlock.Atropos,
+		LowestEpoch:      pm.store.GetLowestEventsEpoch(),
 	}
 }
 
@@ -651,6 +677,10 @@ func (pm *ProtocolManager) handleEvents(p *peer, events dag.Events, ordered bool
 	// Schedule all the events for connection
 	peer := *p
 	now := time.Now()
+	for _, e := range events {
+		// remember the first arrival, the event may be received from several peers
+		_, _ = pm.arrivals.ContainsOrAdd(e.ID(), inter.Timestamp(now.UnixNano()))
+	}
 	requestEvents := func(ids []interface{}) error {
 		return peer.RequestEvents(interfacesToEventIDs(ids))
 	}
@@ -660,6 +690,16 @@ func (pm *ProtocolManager) handleEvents(p *peer, events dag.Events, ordered bool
 	_ = pm.processor.Enqueue(peer.id, events, ordered, notifyAnnounces, nil)
 }
 
+// popArrival returns the local time of the event arrival from a peer, or the default time if it's unknown.
+func (pm *ProtocolManager) popArrival(id hash.Event, def time.Time) inter.Timestamp {
+	arrival, ok := pm.arrivals.Get(id)
+	if !ok {
+		return inter.Timestamp(def.UnixNano())
+	}
+	pm.arrivals.Remove(id)
+	return arrival.(inter.Timestamp)
+}
+
 // handleMsg is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
 func (pm *ProtocolManager) handleMsg(p *peer) error {
@@ -692,12 +732,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		return errResp(ErrExtraStatusMsg, "uncontrolled status message")
 
 	case msg.Code == ProgressMsg:
-		var progress PeerProgress
-		if err := msg.Decode(&progress); err != nil {
+		progress, err := p.decodeProgress(msg)
+		if err != nil {
 			return errResp(ErrDecode, "%v: %v", msg, err)
 		}
 		p.SetProgress(progress)
-		if progress.Epoch == myEpoch {
+		if progress.Epoch == myEpoch && !pm.isSnapshotSyncing() {
 			atomic.StoreUint32(&pm.synced, 1) // Mark initial sync done on any peer which has the same epoch
 		}
 
@@ -759,6 +799,9 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		})
 
 	case msg.Code == EventsMsg:
+		if pm.isSnapshotSyncing() {
+			break
+		}
 		var events inter.EventPayloads
 		if err := msg.Decode(&events); err != nil {
 			return errResp(ErrDecode, "%v: %v", msg, err)
@@ -859,6 +902,44 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 		_ = pm.leecher.NotifyChunkReceived(chunk.SessionID, last, chunk.Done)
 
+	case msg.Code == GetSnapshotInfoMsg:
+		var sealedEpoch idx.Epoch
+		if err := msg.Decode(&sealedEpoch); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		return p.SendSnapshotInfo(pm.store.getSnapshotInfo(sealedEpoch))
+
+	case msg.Code == SnapshotInfoMsg:
+		var info snapshotInfo
+		if err := msg.Decode(&info); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if pm.snapsync != nil {
+			pm.snapsync.NotifySnapshotInfo(p.id, info)
+		}
+
+	case msg.Code == GetStateNodesMsg:
+		var hashes []common.Hash
+		if err := msg.Decode(&hashes); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if err := checkLenLimits(len(hashes), hashes); err != nil {
+			return err
+		}
+		return p.SendStateNodes(pm.store.getStateNodes(hashes, softResponseLimitSize))
+
+	case msg.Code == StateNodesMsg:
+		var nodes [][]byte
+		if err := msg.Decode(&nodes); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if len(nodes) > hardLimitItems {
+			return errResp(ErrMsgTooLarge, "%v", msg)
+		}
+		if pm.snapsync != nil {
+			pm.snapsync.NotifyStateNodes(p.id, nodes)
+		}
+
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
@@ -1006,7 +1087,7 @@ func (pm *ProtocolManager) onNewEpochLoop() {
 		select {
 		case myEpoch := <-pm.newEpochsCh:
 			pm.processor.Clear()
-			if atomic.LoadUint32(&pm.synced) == 0 {
+			if atomic.LoadUint32(&pm.synced) == 0 && !pm.isSnapshotSyncing() {
 				synced := false
 				for _, peer := range pm.peers.List() {
 					if peer.progress.Epoch == myEpoch {
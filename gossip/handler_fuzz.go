@@ -79,7 +79,7 @@ func makeFuzzedPM() (pm *ProtocolManager, err error) {
 	mu := new(sync.RWMutex)
 	feed := new(ServiceFeed)
 	checkers := makeCheckers(config.HeavyCheck, network.EvmChainConfig().ChainID, &heavyCheckReader, &gasPowerCheckReader, store)
-	processEvent := func(e *inter.EventPayload) error {
+	processEvent := func(e *inter.EventPayload, arrival inter.Timestamp) error {
 		return nil
 	}
 
@@ -401,7 +401,7 @@ func (p *peer) EnqueueSendEventsRLP(events []rlp.RawValue, ids []hash.Event, que
 // AsyncSendProgress queues a progress propagation to a remote peer.
 // If the peer's broadcast queue is full, the progress is silently dropped.
 func (p *peer) AsyncSendProgress(progress PeerProgress, queue chan broadcastItem) {
-	if !p.asyncSendNonEncodedItem(progress, ProgressMsg, queue) {
+	if !p.asyncSendNonEncodedItem(p.encodableProgress(progress), ProgressMsg, queue) {
 		p.Log().Debug("Dropping peer progress propagation")
 	}
 }
@@ -453,6 +453,23 @@ func (p *peer) RequestEventsStream(r dagstream.Request) error {
 	return p2p.Send(p.rw, RequestEventsStream, r)
 }
 
+func (p *peer) RequestSnapshotInfo(sealedEpoch idx.Epoch) error {
+	return p2p.Send(p.rw, GetSnapshotInfoMsg, sealedEpoch)
+}
+
+func (p *peer) SendSnapshotInfo(info snapshotInfo) error {
+	return p2p.Send(p.rw, SnapshotInfoMsg, &info)
+}
+
+func (p *peer) RequestStateNodes(hashes []common.Hash) error {
+	p.Log().Debug("Fetching batch of state nodes", "count", len(hashes))
+	return p2p.Send(p.rw, GetStateNodesMsg, hashes)
+}
+
+func (p *peer) SendStateNodes(nodes [][]byte) error {
+	return p2p.Send(p.rw, StateNodesMsg, nodes)
+}
+
 // Handshake executes the protocol handshake, negotiating version number,
 // network IDs, difficulties, head and genesis object.
 func (p *peer) Handshake(network uint64, progress PeerProgress, genesis common.Hash) error {
@@ -492,7 +509,47 @@ func (p *peer) Handshake(network uint64, progress PeerProgress, genesis common.H
 }
 
 func (p *peer) SendProgress(progress PeerProgress) error {
-	return p2p.Send(p.rw, ProgressMsg, progress)
+	return p2p.Send(p.rw, ProgressMsg, p.encodableProgress(progress))
+}
+
+// encodableProgress converts the progress into the format of the peer's protocol version
+func (p *peer) encodableProgress(progress PeerProgress) interface{} {
+	if p.version >= lachesis63 {
+		return progress
+	}
+	return legacyPeerProgress{
+		Epoch:            progress.Epoch,
+		LastBlockIdx:     progress.LastBlockIdx,
+		LastBlockAtropos: progress.LastBlockAtropos,
+		HighestLamport:   progress.HighestLamport,
+	}
+}
+
+// decodeProgress decodes the progress in the format of the peer's protocol version
+func (p *peer) decodeProgress(msg p2p.Msg) (PeerProgress, error) {
+	if p.version >= lachesis63 {
+		var progress PeerProgress
+		err := msg.Decode(&progress)
+		return progress, err
+	}
+	var legacy legacyPeerProgress
+	if err := msg.Decode(&legacy); err != nil {
+		return PeerProgress{}, err
+	}
+	return PeerProgress{
+		Epoch:            legacy.Epoch,
+		LastBlockIdx:     legacy.LastBlockIdx,
+		LastBlockAtropos: legacy.LastBlockAtropos,
+		HighestLamport:   legacy.HighestLamport,
+	}, nil
+}
+
+// CanServeEpoch returns true if the peer hasn't pruned the events of the epoch.
+func (p *peer) CanServeEpoch(epoch idx.Epoch) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.progress.LowestEpoch <= epoch
 }
 
 func (p *peer) readStatus(network uint64, handshake *handshakeData, genesis common.Hash) (err error) {
@@ -8,22 +8,24 @@ import (
 	notify "github.com/ethereum/go-ethereum/event"
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
 	"github.com/Fantom-foundation/go-opera/inter"
 )
 
 // Constants to match up protocol versions and messages
 const (
 	lachesis62 = 62 // derived from eth62
+	lachesis63 = 63 // lachesis62 with snapshot sync
 )
 
 // protocolName is the official short name of the protocol used during capability negotiation.
 const protocolName = "opera"
 
 // ProtocolVersions are the supported versions of the protocol (first is primary).
-var ProtocolVersions = []uint{lachesis62}
+var ProtocolVersions = []uint{lachesis63, lachesis62}
 
 // protocolLengths are the number of implemented message corresponding to different protocol versions.
-var protocolLengths = map[uint]uint64{lachesis62: EventsStreamResponse + 1}
+var protocolLengths = map[uint]uint64{lachesis63: StateNodesMsg + 1, lachesis62: EventsStreamResponse + 1}
 
 const protocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -55,6 +57,15 @@ const (
 	RequestEventsStream = 8
 	// Contains the requested events by RequestEventsStream
 	EventsStreamResponse = 9
+
+	// Request the block and epoch states right after the epoch sealing, lachesis63 only
+	GetSnapshotInfoMsg = 10
+	// Contains the requested snapshot info, or the empty info if the epoch is unknown
+	SnapshotInfoMsg = 11
+	// Request EVM state trie nodes and contract codes by hashes, lachesis63 only
+	GetStateNodesMsg = 12
+	// Contains the requested state trie nodes and contract codes
+	StateNodesMsg = 13
 )
 
 type errCode int
@@ -122,6 +133,17 @@ type PeerProgress struct {
 	LastBlockAtropos hash.Event
 	// Currently unused
 	HighestLamport idx.Lamport
+	// LowestEpoch is the lowest epoch whose events are available, zero if no events were pruned.
+	// Transferred since lachesis63
+	LowestEpoch idx.Epoch
+}
+
+// legacyPeerProgress is PeerProgress of the protocol versions prior to lachesis63
+type legacyPeerProgress struct {
+	Epoch            idx.Epoch
+	LastBlockIdx     idx.Block
+	LastBlockAtropos hash.Event
+	HighestLamport   idx.Lamport
 }
 
 type epochChunk struct {
@@ -130,3 +152,17 @@ type epochChunk struct {
 	IDs       hash.Events
 	Events    inter.EventPayloads
 }
+
+// snapshotInfo is a state of the network right after the epoch sealing.
+// The EVM state at BlockState.FinalizedStateRoot and the events of EpochState.Epoch
+// are enough to continue the network from the snapshot.
+type snapshotInfo struct {
+	SealedEpoch idx.Epoch
+	BlockState  *blockproc.BlockState `rlp:"nil"`
+	EpochState  *blockproc.EpochState `rlp:"nil"`
+	// Block lists its transactions explicitly instead of the events
+	Block *inter.Block `rlp:"nil"`
+	Txs   types.Transactions
+	// Events are the previous epoch events of the validators, which are required to check the gas power
+	Events inter.EventPayloads
+}
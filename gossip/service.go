@@ -6,14 +6,17 @@ import (
 	"math/rand"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/dag"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
 	"github.com/Fantom-foundation/lachesis-base/lachesis"
 	"github.com/Fantom-foundation/lachesis-base/utils/workers"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/types"
 	notify "github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/node"
@@ -53,6 +56,9 @@ type ServiceFeed struct {
 	newEpoch        notify.Feed
 	newPack         notify.Feed
 	newEmittedEvent notify.Feed
+	newEvent        notify.Feed
+	newSealedEpoch  notify.Feed
+	newAtropos      notify.Feed
 	newBlock        notify.Feed
 	newTxs          notify.Feed
 	newLogs         notify.Feed
@@ -66,6 +72,21 @@ func (f *ServiceFeed) SubscribeNewEmitted(ch chan<- *inter.EventPayload) notify.
 	return f.scope.Track(f.newEmittedEvent.Subscribe(ch))
 }
 
+// SubscribeNewEvent subscribes to the connected events, both local and received from peers.
+func (f *ServiceFeed) SubscribeNewEvent(ch chan<- *inter.EventPayload) notify.Subscription {
+	return f.scope.Track(f.newEvent.Subscribe(ch))
+}
+
+// SubscribeNewSealedEpoch subscribes to the sealed epochs.
+func (f *ServiceFeed) SubscribeNewSealedEpoch(ch chan<- ethapi.SealedEpochNotify) notify.Subscription {
+	return f.scope.Track(f.newSealedEpoch.Subscribe(ch))
+}
+
+// SubscribeNewAtropos subscribes to the decided blocks which aren't skipped.
+func (f *ServiceFeed) SubscribeNewAtropos(ch chan<- ethapi.AtroposNotify) notify.Subscription {
+	return f.scope.Track(f.newAtropos.Subscribe(ch))
+}
+
 func (f *ServiceFeed) SubscribeNewBlock(ch chan<- evmcore.ChainHeadNotify) notify.Subscription {
 	return f.scope.Track(f.newBlock.Subscribe(ch))
 }
@@ -127,6 +148,7 @@ type Service struct {
 	gasPowerCheckReader GasPowerCheckReader
 	checkers            *eventcheck.Checkers
 	uniqueEventIDs      uniqueID
+	processingEvent     *inter.EventPayload // event which is being processed by the consensus engine
 
 	// version watcher
 	verWatcher *verwatcher.VerWarcher
@@ -140,6 +162,9 @@ type Service struct {
 
 	feed ServiceFeed
 
+	// bloom bits retrievals of the logs filters
+	bloomRequests chan chan *bloombits.Retrieval
+
 	// application protocol
 	pm *ProtocolManager
 
@@ -177,6 +202,7 @@ func newService(config Config, store *Store, signer valkeystore.SignerI, blockPr
 	svc := &Service{
 		config:           config,
 		done:             make(chan struct{}),
+		bloomRequests:    make(chan chan *bloombits.Retrieval),
 		Name:             fmt.Sprintf("Node-%d", rand.Int()),
 		store:            store,
 		engine:           engine,
@@ -260,7 +286,7 @@ func (s *Service) makeEmitter(signer valkeystore.SignerI) *emitter.Emitter {
 				return s.checkers.Validate(emitted, parents.Interfaces())
 			},
 			Process: func(emitted *inter.EventPayload) error {
-				err := s.processEvent(emitted)
+				err := s.processEvent(emitted, inter.Timestamp(time.Now().UnixNano()))
 				if err != nil {
 					s.Log.Crit("Self-event connection failed", "err", err.Error())
 				}
@@ -286,6 +312,47 @@ func (s *Service) makeEmitter(signer valkeystore.SignerI) *emitter.Emitter {
 	)
 }
 
+// SnapshotSyncEngine is the consensus engine which bootstrapping is deferred until the snapshot sync is finished.
+type SnapshotSyncEngine struct {
+	// Reset moves the engine to the start of the epoch
+	Reset func(epoch idx.Epoch, validators *pos.Validators) error
+	// Bootstrap restores the engine state from its store
+	Bootstrap func() error
+}
+
+// EnableSnapshotSync makes the node bootstrap from a snapshot of a recently sealed epoch
+// if it's far behind the peers. Must be called before the service is started.
+func (s *Service) EnableSnapshotSync(engine SnapshotSyncEngine) {
+	s.pm.snapsync = newSnapshotSyncer(s.config.Protocol.SnapshotSync, s.store, s.pm.peers, s.engineMu, snapshotSyncCallbacks{
+		Finish: func(info *snapshotInfo) error {
+			s.engineMu.Lock()
+			defer s.engineMu.Unlock()
+
+			if info != nil {
+				err := s.store.applySnapshot(info)
+				if err != nil {
+					return err
+				}
+				err = engine.Reset(info.EpochState.Epoch, info.EpochState.Validators)
+				if err != nil {
+					return err
+				}
+				s.switchEpochTo(info.EpochState.Epoch)
+				s.Log.Info("Applied snapshot", "epoch", info.SealedEpoch, "block", info.BlockState.LastBlock.Idx)
+			}
+			err := engine.Bootstrap()
+			if err != nil {
+				return err
+			}
+			return s.store.Commit()
+		},
+		Finished: func() {
+			// resume the events sync from the current epoch
+			s.feed.newEpoch.Send(s.store.GetEpoch())
+		},
+	})
+}
+
 // Protocols returns protocols the service can communicate on.
 func (s *Service) Protocols() []p2p.Protocol {
 	protos := make([]p2p.Protocol, len(ProtocolVersions))
@@ -309,7 +376,7 @@ func (s *Service) APIs() []rpc.API {
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.EthAPI),
+			Service:   filters.NewPublicFilterAPI(s.EthAPI, s.config.Filter),
 			Public:    true,
 		}, {
 			Namespace: "net",
@@ -337,6 +404,9 @@ func (s *Service) Start() error {
 	}
 
 	s.blockProcTasks.Start(1)
+	if s.config.TraceIndex {
+		s.Log.Warn("Trace index is enabled, the blocks are processed with the EVM tracer, which slows down the blocks processing")
+	}
 
 	s.pm.Start(s.p2pServer.MaxPeers)
 
@@ -346,6 +416,15 @@ func (s *Service) Start() error {
 
 	s.verWatcher.Start()
 
+	if s.config.EventsPruning.KeepEpochs != 0 {
+		s.wg.Add(1)
+		go s.eventsPruningLoop()
+	}
+
+	s.startBloomHandlers()
+	s.wg.Add(1)
+	go s.bloomIndexingLoop()
+
 	return nil
 }
 
@@ -0,0 +1,116 @@
+package gossip
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/sfc"
+)
+
+// sfcStateABI is a subset of the SFC contract ABI which is required to read the stakes from a state.
+const sfcStateABI = `[
+{"constant":true,"inputs":[{"name":"","type":"uint256"}],"name":"getValidator","outputs":[{"name":"status","type":"uint256"},{"name":"deactivatedTime","type":"uint256"},{"name":"deactivatedEpoch","type":"uint256"},{"name":"receivedStake","type":"uint256"},{"name":"createdEpoch","type":"uint256"},{"name":"createdTime","type":"uint256"},{"name":"auth","type":"address"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"","type":"address"},{"name":"","type":"uint256"}],"name":"getStake","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"","type":"address"},{"name":"","type":"uint256"}],"name":"getLockupInfo","outputs":[{"name":"lockedStake","type":"uint256"},{"name":"fromEpoch","type":"uint256"},{"name":"endTime","type":"uint256"},{"name":"duration","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"delegator","type":"address"},{"name":"validatorID","type":"uint256"}],"name":"rewardsStash","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+const sfcStateCallGas = 100000000
+
+var sfcStateAbi = func() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(sfcStateABI))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+type (
+	// sfcValidator is a validator record of the SFC contract.
+	sfcValidator struct {
+		Status           *big.Int
+		DeactivatedTime  *big.Int
+		DeactivatedEpoch *big.Int
+		ReceivedStake    *big.Int
+		CreatedEpoch     *big.Int
+		CreatedTime      *big.Int
+		Auth             common.Address
+	}
+	// sfcLockup is a lockup record of a delegation of the SFC contract.
+	sfcLockup struct {
+		LockedStake *big.Int
+		FromEpoch   *big.Int
+		EndTime     *big.Int
+		Duration    *big.Int
+	}
+)
+
+// sfcStateReader performs read-only calls of the SFC contract on a state.
+type sfcStateReader struct {
+	evm *vm.EVM
+}
+
+func newSfcStateReader(statedb *state.StateDB, header *evmcore.EvmHeader, reader evmcore.DummyChain, rules opera.Rules) *sfcStateReader {
+	msg := types.NewMessage(common.Address{}, &sfc.ContractAddress, 0, new(big.Int), sfcStateCallGas, new(big.Int), nil, false)
+	context := evmcore.NewEVMContext(msg, header, reader, &common.Address{})
+	return &sfcStateReader{
+		evm: vm.NewEVM(context, statedb, rules.EvmChainConfig(), opera.DefaultVMConfig),
+	}
+}
+
+func (r *sfcStateReader) call(result interface{}, method string, args ...interface{}) error {
+	input, err := sfcStateAbi.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	ret, _, err := r.evm.StaticCall(vm.AccountRef(common.Address{}), sfc.ContractAddress, input, sfcStateCallGas)
+	if err != nil {
+		return err
+	}
+	if len(ret) == 0 {
+		return errors.New("SFC call " + method + " returned no data")
+	}
+	return sfcStateAbi.Unpack(result, method, ret)
+}
+
+func (r *sfcStateReader) Validator(id idx.ValidatorID) (v sfcValidator, err error) {
+	err = r.call(&v, "getValidator", new(big.Int).SetUint64(uint64(id)))
+	return
+}
+
+func (r *sfcStateReader) Stake(delegator common.Address, id idx.ValidatorID) (stake *big.Int, err error) {
+	err = r.call(&stake, "getStake", delegator, new(big.Int).SetUint64(uint64(id)))
+	return
+}
+
+func (r *sfcStateReader) Lockup(delegator common.Address, id idx.ValidatorID) (l sfcLockup, err error) {
+	err = r.call(&l, "getLockupInfo", delegator, new(big.Int).SetUint64(uint64(id)))
+	return
+}
+
+func (r *sfcStateReader) RewardsStash(delegator common.Address, id idx.ValidatorID) (rewards *big.Int, err error) {
+	err = r.call(&rewards, "rewardsStash", delegator, new(big.Int).SetUint64(uint64(id)))
+	return
+}
+
+func (r *sfcStateReader) TotalSupply() (supply *big.Int, err error) {
+	err = r.call(&supply, "totalSupply")
+	return
+}
+
+func (r *sfcStateReader) Owner() (owner common.Address, err error) {
+	err = r.call(&owner, "owner")
+	return
+}
@@ -0,0 +1,479 @@
+package gossip
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+const (
+	snapshotSyncRecheckPeriod = time.Second
+	snapshotSyncLogPeriod     = 8 * time.Second
+	// maxSnapshotPeerFailures is the number of failed state requests after which the peer isn't used anymore
+	maxSnapshotPeerFailures = 3
+)
+
+var errSnapshotSyncTerminated = errors.New("snapshot sync terminated")
+
+type (
+	snapshotInfoResponse struct {
+		peer string
+		info snapshotInfo
+	}
+
+	stateNodesResponse struct {
+		peer  string
+		nodes [][]byte
+	}
+
+	stateNodesRequest struct {
+		hashes   map[common.Hash]struct{}
+		deadline time.Time
+	}
+
+	snapshotSyncCallbacks struct {
+		// Finish switches the node to the downloaded snapshot, or continues from
+		// the current state if info is nil
+		Finish func(info *snapshotInfo) error
+		// Finished is called once the events processing is resumed
+		Finished func()
+	}
+)
+
+// snapshotSyncer bootstraps a fresh node from the EVM state of a recently sealed epoch instead of
+// processing all the events from genesis.
+// The snapshot is accepted only if a quorum of the peers reports the same, and the downloaded EVM state
+// is verified against the state root of the last block of the sealed epoch.
+// Once the snapshot is applied, the events of the next epoch are downloaded by the stream leecher as usual.
+// The history prior to the snapshot isn't downloaded, including the SFC logs, so the SFC API isn't available afterwards.
+//
+// The agreement is counted in peers rather than in stake. A fresh node knows only the validators of its own epoch,
+// peers aren't bound to validators, and neither events nor the epoch state hash commit to the block state,
+// so the stake behind a snapshot of a recent epoch cannot be verified without processing the epochs in between.
+// The snapshot is a trusted checkpoint therefore, and by default only the trusted and static peers are asked for it.
+type snapshotSyncer struct {
+	cfg      SnapshotSyncConfig
+	store    *Store
+	peers    *peerSet
+	engineMu sync.Locker
+	callback snapshotSyncCallbacks
+
+	infoCh  chan snapshotInfoResponse
+	nodesCh chan stateNodesResponse
+
+	active uint32
+	quit   chan struct{}
+	wg     sync.WaitGroup
+
+	logger.Instance
+}
+
+func newSnapshotSyncer(cfg SnapshotSyncConfig, store *Store, peers *peerSet, engineMu sync.Locker, callback snapshotSyncCallbacks) *snapshotSyncer {
+	ss := &snapshotSyncer{
+		cfg:      cfg,
+		store:    store,
+		peers:    peers,
+		engineMu: engineMu,
+		callback: callback,
+		infoCh:   make(chan snapshotInfoResponse, 64),
+		nodesCh:  make(chan stateNodesResponse, cfg.MaxParallelRequests),
+		active:   1,
+		quit:     make(chan struct{}),
+		Instance: logger.MakeInstance(),
+	}
+	ss.SetName("SnapshotSync")
+	return ss
+}
+
+// Start starts the snapshot sync in a separate goroutine.
+func (ss *snapshotSyncer) Start() {
+	if !ss.cfg.TrustedPeersOnly {
+		ss.Log.Warn("Snapshot sync counts untrusted peers, the snapshot may be forged by a quorum of malicious peers", "quorum", ss.cfg.Quorum)
+	}
+	ss.wg.Add(1)
+	go ss.loop()
+}
+
+// Stop interrupts the snapshot sync and waits until it's stopped.
+func (ss *snapshotSyncer) Stop() {
+	close(ss.quit)
+	ss.wg.Wait()
+}
+
+// Active returns true if the snapshot sync isn't finished yet.
+func (ss *snapshotSyncer) Active() bool {
+	return atomic.LoadUint32(&ss.active) != 0
+}
+
+// NotifySnapshotInfo delivers the snapshot info received from the peer.
+func (ss *snapshotSyncer) NotifySnapshotInfo(peer string, info snapshotInfo) {
+	if !ss.Active() {
+		return
+	}
+	select {
+	case ss.infoCh <- snapshotInfoResponse{peer, info}:
+	default:
+	}
+}
+
+// NotifyStateNodes delivers the state nodes received from the peer.
+func (ss *snapshotSyncer) NotifyStateNodes(peer string, nodes [][]byte) {
+	if !ss.Active() {
+		return
+	}
+	select {
+	case ss.nodesCh <- stateNodesResponse{peer, nodes}:
+	default:
+	}
+}
+
+func (ss *snapshotSyncer) loop() {
+	defer ss.wg.Done()
+
+	info, err := ss.sync()
+	if err == errSnapshotSyncTerminated {
+		return
+	}
+	err = ss.callback.Finish(info)
+	if err != nil {
+		ss.Log.Crit("Failed to finish snapshot sync", "err", err)
+	}
+	atomic.StoreUint32(&ss.active, 0)
+	ss.callback.Finished()
+}
+
+// sync downloads the snapshot and returns its info, or nil if the snapshot sync isn't needed.
+func (ss *snapshotSyncer) sync() (*snapshotInfo, error) {
+	started := time.Now()
+	ticker := time.NewTicker(snapshotSyncRecheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ss.quit:
+			return nil, errSnapshotSyncTerminated
+		case <-ticker.C:
+		}
+		waited := time.Since(started) >= ss.cfg.PeersWaitTimeout
+
+		myEpoch := ss.store.GetEpoch()
+		var bestEpoch idx.Epoch
+		var epochs []idx.Epoch
+		for _, p := range ss.peers.List() {
+			if bestEpoch < p.progress.Epoch {
+				bestEpoch = p.progress.Epoch
+			}
+			if ss.isSource(p) && p.progress.Epoch != 0 {
+				epochs = append(epochs, p.progress.Epoch)
+			}
+		}
+		if bestEpoch < myEpoch+ss.cfg.MinEpochsLag {
+			if waited {
+				ss.Log.Info("Snapshot sync isn't needed", "epoch", myEpoch, "peers_epoch", bestEpoch)
+				return nil, nil
+			}
+			continue
+		}
+		if len(epochs) < ss.cfg.Quorum {
+			if waited {
+				ss.Log.Warn("Not enough peers for snapshot sync, falling back to events sync", "peers", len(epochs), "quorum", ss.cfg.Quorum, "trusted_only", ss.cfg.TrustedPeersOnly)
+				return nil, nil
+			}
+			continue
+		}
+		// the latest sealed epoch which is known by a quorum of the peers
+		sort.Slice(epochs, func(i, j int) bool {
+			return epochs[i] > epochs[j]
+		})
+		sealedEpoch := epochs[ss.cfg.Quorum-1] - 1
+		if sealedEpoch < myEpoch {
+			continue
+		}
+
+		info, peers, err := ss.fetchSnapshotInfo(sealedEpoch)
+		if err != nil {
+			if err == errSnapshotSyncTerminated {
+				return nil, err
+			}
+			ss.Log.Warn("Failed to fetch snapshot info", "epoch", sealedEpoch, "err", err)
+			continue
+		}
+		root := info.BlockState.FinalizedStateRoot
+		ss.Log.Info("Downloading snapshot state", "epoch", sealedEpoch, "block", info.BlockState.LastBlock.Idx, "root", root.String(), "peers", len(peers))
+		err = ss.fetchState(common.Hash(root), peers)
+		if err != nil {
+			if err == errSnapshotSyncTerminated {
+				return nil, err
+			}
+			ss.Log.Warn("Failed to download snapshot state", "epoch", sealedEpoch, "err", err)
+			continue
+		}
+		ss.Log.Info("Downloaded snapshot state", "epoch", sealedEpoch, "root", root.String(), "elapsed", time.Since(started))
+		return info, nil
+	}
+}
+
+// isSource returns true if the peer may be asked for the snapshot.
+func (ss *snapshotSyncer) isSource(p *peer) bool {
+	if p.version < lachesis63 {
+		return false
+	}
+	if !ss.cfg.TrustedPeersOnly {
+		return true
+	}
+	info := p.Peer.Info()
+	return info.Network.Trusted || info.Network.Static
+}
+
+// validate checks the consistency of the snapshot info.
+func (info *snapshotInfo) validate(sealedEpoch idx.Epoch) error {
+	if info.BlockState == nil || info.EpochState == nil || info.Block == nil {
+		return errors.New("empty snapshot")
+	}
+	if info.SealedEpoch != sealedEpoch || info.EpochState.Epoch != sealedEpoch+1 {
+		return fmt.Errorf("snapshot epoch mismatch: expected %d, got %d", sealedEpoch+1, info.EpochState.Epoch)
+	}
+	if info.BlockState.LastBlock.Atropos != info.Block.Atropos {
+		return errors.New("snapshot block mismatch")
+	}
+	if info.BlockState.FinalizedStateRoot != info.Block.Root {
+		return errors.New("snapshot state root mismatch")
+	}
+	if info.EpochState.Validators == nil || info.EpochState.Validators.Len() == 0 {
+		return errors.New("snapshot has no validators")
+	}
+	txids := append(append([]common.Hash{}, info.Block.InternalTxs...), info.Block.Txs...)
+	if len(info.Block.Events) != 0 || len(info.Txs) != len(txids) {
+		return errors.New("snapshot block txs mismatch")
+	}
+	for i, tx := range info.Txs {
+		if tx.Hash() != txids[i] {
+			return fmt.Errorf("snapshot block tx %d mismatch", i)
+		}
+	}
+	events := make(map[hash.Event]bool, len(info.Events))
+	for _, e := range info.Events {
+		events[e.ID()] = true
+	}
+	for _, v := range info.EpochState.ValidatorStates {
+		if v.PrevEpochEvent != hash.ZeroEvent && !events[v.PrevEpochEvent] {
+			return fmt.Errorf("snapshot event %s is missing", v.PrevEpochEvent.String())
+		}
+	}
+	return nil
+}
+
+// fetchSnapshotInfo requests the snapshot info from all the suitable peers and returns the info
+// which is reported by a quorum of the peers, along with the peers which reported it.
+func (ss *snapshotSyncer) fetchSnapshotInfo(sealedEpoch idx.Epoch) (*snapshotInfo, []string, error) {
+	requested := make(map[string]bool)
+	for _, p := range ss.peers.List() {
+		if !ss.isSource(p) || p.progress.Epoch <= sealedEpoch {
+			continue
+		}
+		if err := p.RequestSnapshotInfo(sealedEpoch); err != nil {
+			continue
+		}
+		requested[p.id] = true
+	}
+	if len(requested) < ss.cfg.Quorum {
+		return nil, nil, fmt.Errorf("not enough peers: %d", len(requested))
+	}
+
+	type group struct {
+		info  snapshotInfo
+		peers []string
+	}
+	groups := make(map[common.Hash]*group)
+	responded := 0
+	nonEmpty := 0
+	timeout := time.NewTimer(ss.cfg.RequestTimeout)
+	defer timeout.Stop()
+	for responded < len(requested) {
+		select {
+		case <-ss.quit:
+			return nil, nil, errSnapshotSyncTerminated
+		case <-timeout.C:
+			responded = len(requested)
+		case resp := <-ss.infoCh:
+			if !requested[resp.peer] || resp.info.SealedEpoch != sealedEpoch {
+				continue
+			}
+			requested[resp.peer] = false
+			responded++
+			if resp.info.BlockState == nil {
+				continue
+			}
+			nonEmpty++
+			raw, err := rlp.EncodeToBytes(&resp.info)
+			if err != nil {
+				continue
+			}
+			h := crypto.Keccak256Hash(raw)
+			if groups[h] == nil {
+				groups[h] = &group{info: resp.info}
+			}
+			groups[h].peers = append(groups[h].peers, resp.peer)
+		}
+	}
+
+	for _, g := range groups {
+		if len(g.peers) < ss.cfg.Quorum || len(g.peers)*2 <= nonEmpty {
+			continue
+		}
+		if err := g.info.validate(sealedEpoch); err != nil {
+			return nil, nil, err
+		}
+		return &g.info, g.peers, nil
+	}
+	return nil, nil, fmt.Errorf("no quorum among %d snapshots", nonEmpty)
+}
+
+// fetchState downloads the EVM state trie and contract codes from the peers.
+// Every received item is verified by its hash, so the state matches the root once downloaded.
+func (ss *snapshotSyncer) fetchState(root common.Hash, peers []string) error {
+	db := ss.store.evm.EvmTable()
+	bloom := trie.NewSyncBloom(ss.cfg.BloomSize, db)
+	defer bloom.Close()
+	sched := state.NewStateSync(root, db, bloom)
+
+	var (
+		queue     []common.Hash
+		inflight  = make(map[string]*stateNodesRequest)
+		failures  = make(map[string]int)
+		processed = 0
+		committed = 0
+		lastLog   = time.Now()
+	)
+	ticker := time.NewTicker(snapshotSyncRecheckPeriod)
+	defer ticker.Stop()
+
+	for sched.Pending() != 0 {
+		// schedule the missing items
+		if len(queue) < ss.cfg.MaxStateNodesRequest*ss.cfg.MaxParallelRequests {
+			nodes, _, codes := sched.Missing(ss.cfg.MaxStateNodesRequest * ss.cfg.MaxParallelRequests)
+			queue = append(queue, nodes...)
+			queue = append(queue, codes...)
+		}
+		// request them from the idle peers
+		alive := 0
+		for _, id := range peers {
+			p := ss.peers.Peer(id)
+			if p == nil || failures[id] >= maxSnapshotPeerFailures {
+				continue
+			}
+			alive++
+			if inflight[id] != nil || len(queue) == 0 || len(inflight) >= ss.cfg.MaxParallelRequests {
+				continue
+			}
+			n := ss.cfg.MaxStateNodesRequest
+			if n > len(queue) {
+				n = len(queue)
+			}
+			batch := queue[:n]
+			if err := p.RequestStateNodes(batch); err != nil {
+				failures[id]++
+				continue
+			}
+			queue = queue[n:]
+			req := &stateNodesRequest{
+				hashes:   make(map[common.Hash]struct{}, len(batch)),
+				deadline: time.Now().Add(ss.cfg.RequestTimeout),
+			}
+			for _, h := range batch {
+				req.hashes[h] = struct{}{}
+			}
+			inflight[id] = req
+		}
+		if alive == 0 {
+			return errors.New("no peers left")
+		}
+
+		select {
+		case <-ss.quit:
+			return errSnapshotSyncTerminated
+
+		case resp := <-ss.nodesCh:
+			req := inflight[resp.peer]
+			if req == nil {
+				continue
+			}
+			delete(inflight, resp.peer)
+			delivered := 0
+			for _, blob := range resp.nodes {
+				h := crypto.Keccak256Hash(blob)
+				if _, ok := req.hashes[h]; !ok {
+					continue
+				}
+				delete(req.hashes, h)
+				err := sched.Process(trie.SyncResult{Hash: h, Data: blob})
+				if err != nil && err != trie.ErrNotRequested && err != trie.ErrAlreadyProcessed {
+					return err
+				}
+				delivered++
+			}
+			if delivered == 0 {
+				failures[resp.peer]++
+			}
+			processed += delivered
+			// reschedule the undelivered items
+			for h := range req.hashes {
+				queue = append(queue, h)
+			}
+
+		case now := <-ticker.C:
+			for id, req := range inflight {
+				if now.Before(req.deadline) && ss.peers.Peer(id) != nil {
+					continue
+				}
+				delete(inflight, id)
+				failures[id]++
+				for h := range req.hashes {
+					queue = append(queue, h)
+				}
+			}
+		}
+
+		if processed-committed >= ss.cfg.MaxStateNodesRequest*ss.cfg.MaxParallelRequests {
+			if err := ss.commitState(sched, db, false); err != nil {
+				return err
+			}
+			committed = processed
+		}
+		if time.Since(lastLog) >= snapshotSyncLogPeriod {
+			ss.Log.Info("Downloading snapshot state", "processed", processed, "pending", sched.Pending())
+			lastLog = time.Now()
+		}
+	}
+	return ss.commitState(sched, db, true)
+}
+
+// commitState writes the processed state items into the store.
+func (ss *snapshotSyncer) commitState(sched *trie.Sync, db ethdb.Database, force bool) error {
+	batch := db.NewBatch()
+	if err := sched.Commit(batch); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	ss.engineMu.Lock()
+	defer ss.engineMu.Unlock()
+	if force || ss.store.IsCommitNeeded(false) {
+		return ss.store.Commit()
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package gossip
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+// snapshotTestPeer is a fake remote peer which serves the snapshot of the source store.
+type snapshotTestPeer struct {
+	*peer
+	remote *p2p.MsgPipeRW
+	// forged makes the peer report a snapshot which differs from the source one
+	forged bool
+	// junk makes the peer respond with the junk instead of the state nodes
+	junk bool
+
+	infoRequests  uint32
+	nodesRequests uint32
+}
+
+type snapshotSyncTest struct {
+	src   *testEnv
+	store *Store
+	ss    *snapshotSyncer
+	peers []*snapshotTestPeer
+	wg    sync.WaitGroup
+}
+
+func newSnapshotSyncTest(t *testing.T, cfg SnapshotSyncConfig) *snapshotSyncTest {
+	src := newTestEnv()
+	src.ApplyBlock(sameEpoch, src.Transfer(1, 2, utils.ToFtm(100)))
+	src.ApplyBlock(nextEpoch)
+	src.ApplyBlock(nextEpoch)
+	// wait for the EVM state commit
+	src.blockProcWg.Wait()
+
+	genStore := makegenesis.FakeGenesisStore(genesisStakers, utils.ToFtm(genesisBalance), utils.ToFtm(genesisStake))
+	store := NewMemStore()
+	_, err := store.ApplyGenesis(DefaultBlockProc(genStore.GetGenesis()), genStore.GetGenesis())
+	require.NoError(t, err)
+
+	return &snapshotSyncTest{
+		src:   src,
+		store: store,
+		ss:    newSnapshotSyncer(cfg, store, newPeerSet(), new(sync.Mutex), snapshotSyncCallbacks{}),
+	}
+}
+
+func (st *snapshotSyncTest) addPeer(forged, junk bool) *snapshotTestPeer {
+	local, remote := p2p.MsgPipe()
+	id := enode.ID{byte(len(st.peers) + 1)}
+	p := &snapshotTestPeer{
+		peer:   newPeer(lachesis63, p2p.NewPeer(id, id.String(), nil), local),
+		remote: remote,
+		forged: forged,
+		junk:   junk,
+	}
+	p.progress.Epoch = st.src.store.GetEpoch()
+	if err := st.ss.peers.Register(p.peer); err != nil {
+		panic(err)
+	}
+	st.peers = append(st.peers, p)
+
+	st.wg.Add(1)
+	go func() {
+		defer st.wg.Done()
+		for {
+			msg, err := remote.ReadMsg()
+			if err != nil {
+				return
+			}
+			switch msg.Code {
+			case GetSnapshotInfoMsg:
+				atomic.AddUint32(&p.infoRequests, 1)
+				var epoch idx.Epoch
+				if err := msg.Decode(&epoch); err != nil {
+					panic(err)
+				}
+				info := st.src.store.getSnapshotInfo(epoch)
+				if p.forged {
+					block := *info.Block
+					block.Time++
+					info.Block = &block
+				}
+				st.ss.NotifySnapshotInfo(p.id, info)
+			case GetStateNodesMsg:
+				atomic.AddUint32(&p.nodesRequests, 1)
+				var hashes []common.Hash
+				if err := msg.Decode(&hashes); err != nil {
+					panic(err)
+				}
+				nodes := st.src.store.getStateNodes(hashes, softResponseLimitSize)
+				if p.junk {
+					nodes = [][]byte{[]byte("junk")}
+				}
+				st.ss.NotifyStateNodes(p.id, nodes)
+			default:
+				_ = msg.Discard()
+			}
+		}
+	}()
+	return p
+}
+
+func (st *snapshotSyncTest) Close() {
+	for _, p := range st.peers {
+		_ = st.ss.peers.Unregister(p.id)
+		_ = p.remote.Close()
+	}
+	st.wg.Wait()
+	st.store.Close()
+	st.src.Close()
+}
+
+func testSnapshotSyncConfig() SnapshotSyncConfig {
+	cfg := DefaultConfig().Protocol.SnapshotSync
+	cfg.Enabled = true
+	cfg.MinEpochsLag = 1
+	cfg.Quorum = 2
+	cfg.TrustedPeersOnly = false
+	cfg.PeersWaitTimeout = 0
+	cfg.RequestTimeout = 5 * time.Second
+	cfg.MaxStateNodesRequest = 4
+	cfg.MaxParallelRequests = 2
+	cfg.BloomSize = 1
+	return cfg
+}
+
+func TestSnapshotSyncer(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	st := newSnapshotSyncTest(t, testSnapshotSyncConfig())
+	defer st.Close()
+
+	honest := st.addPeer(false, false)
+	junk := st.addPeer(false, true)
+	forged := st.addPeer(true, false)
+
+	info, err := st.ss.sync()
+	require.NoError(err)
+	require.NotNil(info)
+	sealedEpoch := st.src.store.GetEpoch() - 1
+	expected := st.src.store.getSnapshotInfo(sealedEpoch)
+	require.Equal(sealedEpoch, info.SealedEpoch)
+	require.Equal(expected.Block.Time, info.Block.Time)
+	require.Equal(expected.BlockState.FinalizedStateRoot, info.BlockState.FinalizedStateRoot)
+
+	// the state is downloaded from the peers which reported the snapshot of the quorum,
+	// the peer which responds with the junk is replaced by the others
+	for _, p := range []*snapshotTestPeer{honest, junk, forged} {
+		require.Equal(uint32(1), atomic.LoadUint32(&p.infoRequests))
+	}
+	require.NotZero(atomic.LoadUint32(&honest.nodesRequests))
+	require.NotZero(atomic.LoadUint32(&junk.nodesRequests))
+	require.Zero(atomic.LoadUint32(&forged.nodesRequests))
+
+	require.NoError(st.store.applySnapshot(info))
+	require.Equal(sealedEpoch+1, st.store.GetEpoch())
+	require.Equal(expected.BlockState.LastBlock.Idx, st.store.GetLatestBlockIndex())
+	require.Equal(&expected.BlockState.LastBlock.Idx, st.store.GetSnapshotBlockIndex())
+	statedb, err := st.store.evm.StateDB(info.BlockState.FinalizedStateRoot)
+	require.NoError(err)
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+	}
+	require.NoError(it.Error)
+	srcState, err := st.src.store.evm.StateDB(info.BlockState.FinalizedStateRoot)
+	require.NoError(err)
+	// the fake keys aren't deterministic, so the address is derived once
+	addr := st.src.Address(2)
+	require.Equal(srcState.GetBalance(addr), statedb.GetBalance(addr))
+
+	// the SFC API index isn't downloaded
+	backend := &EthAPIBackend{svc: &Service{store: st.store}}
+	_, err = backend.GetStakers(context.Background())
+	require.Error(err)
+	require.Nil(st.src.store.GetSnapshotBlockIndex())
+	_, err = (&EthAPIBackend{svc: &Service{store: st.src.store}}).GetStakers(context.Background())
+	require.NoError(err)
+}
+
+func TestSnapshotSyncerNoQuorum(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	cfg := testSnapshotSyncConfig()
+	cfg.Quorum = 3
+	st := newSnapshotSyncTest(t, cfg)
+	defer st.Close()
+
+	st.addPeer(false, false)
+	st.addPeer(false, false)
+	st.addPeer(true, false)
+
+	_, _, err := st.ss.fetchSnapshotInfo(st.src.store.GetEpoch() - 1)
+	require.Error(err)
+}
+
+func TestSnapshotSyncerTrustedPeersOnly(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	require.True(DefaultConfig().Protocol.SnapshotSync.TrustedPeersOnly)
+
+	cfg := testSnapshotSyncConfig()
+	cfg.TrustedPeersOnly = true
+	st := newSnapshotSyncTest(t, cfg)
+	defer st.Close()
+
+	// the untrusted peers aren't asked for the snapshot, the node falls back to the events sync
+	peers := []*snapshotTestPeer{st.addPeer(false, false), st.addPeer(false, false)}
+	info, err := st.ss.sync()
+	require.NoError(err)
+	require.Nil(info)
+	for _, p := range peers {
+		require.Zero(atomic.LoadUint32(&p.infoRequests))
+	}
+}
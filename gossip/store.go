@@ -31,6 +31,8 @@ type Store struct {
 	sfcapi *sfcapi.Store
 	table  struct {
 		Version kvdb.Store `table:"_"`
+		// Tables affected by the running migration step
+		MigrationBackup kvdb.Store `table:"!"`
 
 		// Main DAG tables
 		BlockEpochState kvdb.Store `table:"D"`
@@ -40,12 +42,30 @@ type Store struct {
 		PacksNum        kvdb.Store `table:"n"`
 		Genesis         kvdb.Store `table:"g"`
 
+		// History of the block and epoch states at the epochs start
+		BlockEpochStateHistory kvdb.Store `table:"h"`
+
 		// Network version
 		NetworkVersion kvdb.Store `table:"V"`
 
+		// Lowest epoch whose events aren't pruned
+		LowestEventsEpoch kvdb.Store `table:"p"`
+
 		// API-only
-		BlockHashes kvdb.Store `table:"B"`
-		SfcAPI      kvdb.Store `table:"S"`
+		BlockHashes    kvdb.Store `table:"B"`
+		SfcAPI         kvdb.Store `table:"S"`
+		DecisiveEvents kvdb.Store `table:"d"`
+		EventLocalTime kvdb.Store `table:"a"`
+
+		// Logs blooms of the blocks and their sectioned bloom bits index
+		BlockBlooms kvdb.Store `table:"f"`
+		BloomBits   kvdb.Store `table:"F"`
+
+		// Progress of the interrupted logs reindexing
+		LogsReindex kvdb.Store `table:"i"`
+
+		// Statistics of the sealed epochs and the current epoch
+		EpochStats kvdb.Store `table:"j"`
 	}
 
 	prevFlushTime time.Time
@@ -146,9 +166,10 @@ func (s *Store) IsCommitNeeded(epochSealing bool) bool {
 		s.dbs.NotFlushedSizeEst() > size
 }
 
-// commitEVM commits EVM storage
-func (s *Store) commitEVM() {
-	err := s.evm.Commit(s.GetBlockState().FinalizedStateRoot)
+// commitEVM commits EVM storage, the state is flushed on the DB if flush is true or the state pruning is disabled
+func (s *Store) commitEVM(flush bool) {
+	bs := s.GetBlockState()
+	err := s.evm.Commit(bs.LastBlock.Idx, bs.FinalizedStateRoot, flush)
 	if err != nil {
 		s.Log.Crit("Failed to commit EVM storage", "err", err)
 	}
@@ -159,6 +180,11 @@ func (s *Store) commitEVM() {
 func (s *Store) Commit() error {
 	s.prevFlushTime = time.Now()
 	flushID := bigendian.Uint64ToBytes(uint64(time.Now().UnixNano()))
+	// Flush the latest EVM state, which may be kept only in memory if the state pruning is enabled
+	err := s.evm.Flush(s.GetBlockState().FinalizedStateRoot)
+	if err != nil {
+		return err
+	}
 	// Flush the DBs
 	s.FlushBlockEpochState()
 	return s.dbs.Flush(flushID)
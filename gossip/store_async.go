@@ -13,6 +13,11 @@ type asyncStore struct {
 	}
 }
 
+// AsyncDBTables returns the tables layout of the async DB, i.e. a struct with the `table:"x"` tags.
+func AsyncDBTables() interface{} {
+	return asyncStore{}.table
+}
+
 func newAsyncStore(db kvdb.Store) *asyncStore {
 	s := &asyncStore{
 		mainDB: db,
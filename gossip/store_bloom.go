@@ -0,0 +1,136 @@
+package gossip
+
+import (
+	"encoding/binary"
+
+	"github.com/Fantom-foundation/lachesis-base/common/bigendian"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BloomBitsSectionSize is the number of blocks in a section of the bloom bits index.
+const BloomBitsSectionSize = params.BloomBitsBlocks
+
+var bloomSectionsKey = []byte("s")
+
+// receiptsBloom returns the bloom of the receipts logs.
+func receiptsBloom(receipts []*types.ReceiptForStorage) types.Bloom {
+	var logs []*types.Log
+	for _, r := range receipts {
+		logs = append(logs, r.Logs...)
+	}
+	return types.BytesToBloom(types.LogsBloom(logs).Bytes())
+}
+
+// compressBits compresses the sparse bits, the empty result isn't nil so it may be stored.
+func compressBits(bits []byte) []byte {
+	return append([]byte{}, bitutil.CompressBytes(bits)...)
+}
+
+// SetBlockBloom stores the logs bloom of the block.
+func (s *Store) SetBlockBloom(n idx.Block, bloom types.Bloom) {
+	if err := s.table.BlockBlooms.Put(n.Bytes(), compressBits(bloom[:])); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// GetBlockBloom returns the logs bloom of the block, nil if the bloom isn't stored.
+func (s *Store) GetBlockBloom(n idx.Block) *types.Bloom {
+	buf, err := s.table.BlockBlooms.Get(n.Bytes())
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return nil
+	}
+	raw, err := bitutil.DecompressBytes(buf, types.BloomByteLength)
+	if err != nil {
+		s.Log.Crit("Failed to decompress bloom", "block", n, "err", err)
+	}
+	bloom := types.BytesToBloom(raw)
+	return &bloom
+}
+
+// GetBloomSections returns the number of the indexed bloom bits sections, which are indexed in order.
+func (s *Store) GetBloomSections() uint64 {
+	buf, err := s.table.BloomBits.Get(bloomSectionsKey)
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return 0
+	}
+	return bigendian.BytesToUint64(buf)
+}
+
+func bloomBitsKey(bit uint, section uint64) []byte {
+	key := make([]byte, 2+8)
+	binary.BigEndian.PutUint16(key, uint16(bit))
+	binary.BigEndian.PutUint64(key[2:], section)
+	return key
+}
+
+// GetBloomBits returns the compressed bit vector of the bloom bits section.
+func (s *Store) GetBloomBits(bit uint, section uint64) ([]byte, error) {
+	return s.table.BloomBits.Get(bloomBitsKey(bit, section))
+}
+
+// IndexNextBloomSection indexes the next bloom bits section if all its blocks are finalized.
+// The blocks whose blooms aren't stored match any filter, so their logs are always checked.
+// Returns false if the section isn't complete yet.
+func (s *Store) IndexNextBloomSection() bool {
+	section := s.GetBloomSections()
+	last := idx.Block((section+1)*BloomBitsSectionSize - 1)
+	if s.GetLatestBlockIndex() < last {
+		return false
+	}
+
+	gen, err := bloombits.NewGenerator(uint(BloomBitsSectionSize))
+	if err != nil {
+		s.Log.Crit("Failed to create bloom bits generator", "err", err)
+	}
+	var full types.Bloom
+	for i := range full {
+		full[i] = 0xff
+	}
+	first := idx.Block(section * BloomBitsSectionSize)
+	for n := first; n <= last; n++ {
+		bloom := s.GetBlockBloom(n)
+		if bloom == nil {
+			bloom = &types.Bloom{}
+			if s.GetBlock(n) != nil {
+				bloom = &full
+			}
+		}
+		if err := gen.AddBloom(uint(n-first), *bloom); err != nil {
+			s.Log.Crit("Failed to add bloom", "block", n, "err", err)
+		}
+	}
+	for bit := uint(0); bit < types.BloomBitLength; bit++ {
+		bits, err := gen.Bitset(bit)
+		if err != nil {
+			s.Log.Crit("Failed to get bloom bits", "bit", bit, "err", err)
+		}
+		if err := s.table.BloomBits.Put(bloomBitsKey(bit, section), compressBits(bits)); err != nil {
+			s.Log.Crit("Failed to put key-value", "err", err)
+		}
+	}
+	if err := s.table.BloomBits.Put(bloomSectionsKey, bigendian.Uint64ToBytes(section+1)); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+	return true
+}
+
+// resetBloomSections drops the indexed bloom bits sections which include blocks after the last block.
+func (s *Store) resetBloomSections(last idx.Block) {
+	complete := (uint64(last) + 1) / BloomBitsSectionSize
+	if s.GetBloomSections() <= complete {
+		return
+	}
+	if err := s.table.BloomBits.Put(bloomSectionsKey, bigendian.Uint64ToBytes(complete)); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
@@ -0,0 +1,63 @@
+package gossip
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStoreBloomBits(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	genStore := makegenesis.FakeGenesisStore(genesisStakers, utils.ToFtm(genesisBalance), utils.ToFtm(genesisStake))
+	store := NewMemStore()
+	defer store.Close()
+	_, err := store.ApplyGenesis(DefaultBlockProc(genStore.GetGenesis()), genStore.GetGenesis())
+	require.NoError(err)
+
+	// the blooms of the genesis blocks are stored
+	bs, es := store.GetBlockEpochState()
+	require.NotNil(store.GetBlockBloom(bs.LastBlock.Idx))
+	require.False(store.IndexNextBloomSection())
+	require.Equal(uint64(0), store.GetBloomSections())
+
+	addr := common.BytesToAddress([]byte("addr"))
+	withLog := types.BytesToBloom(types.LogsBloom([]*types.Log{{Address: addr}}).Bytes())
+	store.SetBlockBloom(10, withLog)
+	// the block without the stored bloom matches any filter
+	store.SetBlock(20, &inter.Block{})
+	store.SetBlockBloom(30, types.Bloom{})
+	bs.LastBlock.Idx = idx.Block(BloomBitsSectionSize - 1)
+	store.SetBlockEpochState(bs, es)
+
+	require.True(store.IndexNextBloomSection())
+	require.Equal(uint64(1), store.GetBloomSections())
+	require.False(store.IndexNextBloomSection())
+
+	for bit := uint(0); bit < types.BloomBitLength; bit++ {
+		comp, err := store.GetBloomBits(bit, 0)
+		require.NoError(err)
+		bits, err := bitutil.DecompressBytes(comp, int(BloomBitsSectionSize/8))
+		require.NoError(err)
+		isSet := func(n idx.Block) bool {
+			return bits[n/8]&(1<<(7-n%8)) != 0
+		}
+		require.Equal(withLog[types.BloomByteLength-1-bit/8]&(1<<(bit%8)) != 0, isSet(10), bit)
+		require.True(isSet(20), bit)
+		require.False(isSet(30), bit)
+	}
+
+	// the sections after the last block are dropped
+	store.resetBloomSections(idx.Block(BloomBitsSectionSize - 2))
+	require.Equal(uint64(0), store.GetBloomSections())
+}
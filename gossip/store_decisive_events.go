@@ -0,0 +1,26 @@
+package gossip
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// DecisiveEvents is a set of events which have decided a block.
+type DecisiveEvents struct {
+	// Decisive is the root, during the processing of which the Atropos election was decided
+	Decisive hash.Event
+	// Roots are the roots of the previous frame which are forkless caused by the Decisive root,
+	// i.e. the roots whose votes were aggregated to decide the election
+	Roots hash.Events
+}
+
+// SetDecisiveEvents stores events which have decided the block.
+func (s *Store) SetDecisiveEvents(n idx.Block, de *DecisiveEvents) {
+	s.rlp.Set(s.table.DecisiveEvents, n.Bytes(), de)
+}
+
+// GetDecisiveEvents returns stored events which have decided the block.
+func (s *Store) GetDecisiveEvents(n idx.Block) *DecisiveEvents {
+	de, _ := s.rlp.Get(s.table.DecisiveEvents, n.Bytes(), &DecisiveEvents{}).(*DecisiveEvents)
+	return de
+}
@@ -0,0 +1,30 @@
+package gossip
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+func TestStoreDecisiveEvents(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	store := NewMemStore()
+	defer store.Close()
+
+	require.Nil(store.GetDecisiveEvents(1))
+
+	de := &DecisiveEvents{
+		Decisive: hash.FakeEvent(),
+		Roots:    hash.Events{hash.FakeEvent(), hash.FakeEvent()},
+	}
+	store.SetDecisiveEvents(idx.Block(2), de)
+	require.Equal(de, store.GetDecisiveEvents(2))
+	require.Nil(store.GetDecisiveEvents(1))
+	require.Nil(store.GetDecisiveEvents(3))
+}
@@ -43,6 +43,11 @@ func newEpochStore(epoch idx.Epoch, db kvdb.DropableStore) *epochStore {
 	return es
 }
 
+// EpochDBTables returns the tables layout of an epoch DB, i.e. a struct with the `table:"x"` tags.
+func EpochDBTables() interface{} {
+	return epochStore{}.table
+}
+
 func (s *Store) getAnyEpochStore() *epochStore {
 	_es := s.epochStore.Load()
 	if _es == nil {
@@ -0,0 +1,42 @@
+package gossip
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/gossip/blockproc"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// SetHistoryBlockEpochState stores the block and epoch states at the start of the epoch,
+// i.e. right after the previous epoch was sealed.
+func (s *Store) SetHistoryBlockEpochState(epoch idx.Epoch, bs blockproc.BlockState, es blockproc.EpochState) {
+	bs, es = bs.Copy(), es.Copy()
+	s.rlp.Set(s.table.BlockEpochStateHistory, epoch.Bytes(), &BlockEpochState{&bs, &es})
+}
+
+// GetHistoryBlockEpochState returns the block and epoch states at the start of the epoch.
+func (s *Store) GetHistoryBlockEpochState(epoch idx.Epoch) (*blockproc.BlockState, *blockproc.EpochState) {
+	v, ok := s.rlp.Get(s.table.BlockEpochStateHistory, epoch.Bytes(), &BlockEpochState{}).(*BlockEpochState)
+	if !ok {
+		return nil, nil
+	}
+	return v.BlockState, v.EpochState
+}
+
+// GetBlockRules returns the network rules which the block was processed with, i.e. the rules of the Atropos epoch.
+// The rules of the past epochs are taken from the epoch start states, which may be missing
+// for the epochs sealed before the states history was introduced.
+func (s *Store) GetBlockRules(n idx.Block, block *inter.Block) (opera.Rules, error) {
+	epoch := block.Atropos.Epoch()
+	if rules, current := s.GetEpochRules(); epoch == current {
+		return rules, nil
+	}
+	_, es := s.GetHistoryBlockEpochState(epoch)
+	if es == nil {
+		return opera.Rules{}, fmt.Errorf("rules of epoch %d of block %d not found", epoch, n)
+	}
+	return es.Rules, nil
+}
@@ -0,0 +1,67 @@
+package gossip
+
+import (
+	"math/big"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// GetEpochStats returns the statistics of the sealed epoch, nil if they aren't stored.
+func (s *Store) GetEpochStats(epoch idx.Epoch) *inter.EpochStats {
+	if epoch >= s.GetEpoch() {
+		// the epoch isn't sealed, only the blocks are accounted
+		return nil
+	}
+	return s.getEpochStats(epoch)
+}
+
+func (s *Store) getEpochStats(epoch idx.Epoch) *inter.EpochStats {
+	stats, _ := s.rlp.Get(s.table.EpochStats, epoch.Bytes(), &inter.EpochStats{}).(*inter.EpochStats)
+	return stats
+}
+
+// addEpochBlockStats accounts the block in the statistics of the epoch.
+func (s *Store) addEpochBlockStats(epoch idx.Epoch, n idx.Block, gasUsed uint64, txs int) {
+	stats := s.getEpochStats(epoch)
+	if stats == nil {
+		stats = &inter.EpochStats{
+			Epoch:      epoch,
+			FirstBlock: n,
+			TotalFee:   new(big.Int),
+		}
+	}
+	stats.LastBlock = n
+	stats.GasUsed += gasUsed
+	stats.TxCount += uint64(txs)
+	s.rlp.Set(s.table.EpochStats, epoch.Bytes(), stats)
+}
+
+// sealEpochStats stores the statistics of the sealed epoch along with the accounted blocks of the epoch.
+// The originated fees of the validators are counted since the epoch start.
+func (s *Store) sealEpochStats(sealed inter.EpochStats) {
+	stats := sealed
+	stats.TotalFee = new(big.Int)
+	if blocks := s.getEpochStats(sealed.Epoch); blocks != nil {
+		stats.FirstBlock = blocks.FirstBlock
+		stats.GasUsed = blocks.GasUsed
+		stats.TxCount = blocks.TxCount
+	} else {
+		stats.FirstBlock = sealed.LastBlock
+	}
+
+	startBs, startEs := s.GetHistoryBlockEpochState(sealed.Epoch)
+	stats.Validators = make([]inter.ValidatorEpochStats, len(sealed.Validators))
+	for i, v := range sealed.Validators {
+		v.OriginatedFee = new(big.Int).Set(v.OriginatedFee)
+		if startBs != nil && startEs != nil && startEs.Validators.Exists(v.ID) {
+			if start := startBs.ValidatorStates[startEs.Validators.GetIdx(v.ID)].Originated; start != nil {
+				v.OriginatedFee.Sub(v.OriginatedFee, start)
+			}
+		}
+		stats.TotalFee.Add(stats.TotalFee, v.OriginatedFee)
+		stats.Validators[i] = v
+	}
+	s.rlp.Set(s.table.EpochStats, sealed.Epoch.Bytes(), &stats)
+}
@@ -0,0 +1,57 @@
+package gossip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStoreEpochStats(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv()
+	defer env.Close()
+
+	epoch := env.store.GetEpoch()
+	first := env.store.GetLatestBlockIndex() + 1
+	start := env.store.GetEpochState().EpochStart
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 2, utils.ToFtm(100)), env.Transfer(2, 3, utils.ToFtm(10)))
+	env.ApplyBlock(nextEpoch)
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 3, utils.ToFtm(1)))
+	env.blockProcWg.Wait()
+	require.Equal(epoch+1, env.store.GetEpoch())
+
+	// the current epoch isn't sealed
+	require.Nil(env.store.GetEpochStats(epoch + 1))
+
+	stats := env.store.GetEpochStats(epoch)
+	require.NotNil(stats)
+	require.Equal(epoch, stats.Epoch)
+	require.Equal(start, stats.Start)
+	require.Equal(env.store.GetEpochState().EpochStart, stats.End)
+	require.Equal(first, stats.FirstBlock)
+	require.Equal(first+1, stats.LastBlock)
+	gasUsed, txs := uint64(0), 0
+	for n := first; n <= stats.LastBlock; n++ {
+		for _, r := range env.store.evm.GetReceipts(n) {
+			gasUsed += r.GasUsed
+			txs++
+		}
+	}
+	require.Equal(gasUsed, stats.GasUsed)
+	require.Equal(uint64(txs), stats.TxCount)
+	// the events of the test env have no creator, so no fee is originated
+	require.Equal(0, stats.TotalFee.Sign())
+
+	_, es := env.store.GetHistoryBlockEpochState(epoch)
+	require.Len(stats.Validators, int(es.Validators.Len()))
+	for _, v := range stats.Validators {
+		require.True(es.Validators.Exists(v.ID))
+		require.Equal(utils.ToFtm(genesisStake), v.Weight)
+		require.Equal(0, v.OriginatedFee.Sign())
+	}
+}
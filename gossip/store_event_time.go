@@ -0,0 +1,39 @@
+package gossip
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// SetEventLocalTime stores the local time of the event arrival.
+func (s *Store) SetEventLocalTime(id hash.Event, t inter.Timestamp) {
+	if err := s.table.EventLocalTime.Put(id.Bytes(), t.Bytes()); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// GetEventLocalTime returns the local time of the event arrival.
+func (s *Store) GetEventLocalTime(id hash.Event) *inter.Timestamp {
+	buf, err := s.table.EventLocalTime.Get(id.Bytes())
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return nil
+	}
+	t := inter.BytesToTimestamp(buf)
+	return &t
+}
+
+// ForEachEventLocalTime iterates the local arrival times of the epoch events.
+func (s *Store) ForEachEventLocalTime(epoch idx.Epoch, onEvent func(id hash.Event, t inter.Timestamp) bool) {
+	it := s.table.EventLocalTime.NewIterator(epoch.Bytes(), nil)
+	defer it.Release()
+	for it.Next() {
+		if !onEvent(hash.BytesToEvent(it.Key()), inter.BytesToTimestamp(it.Value())) {
+			return
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package gossip
+
+import (
+	"errors"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// ErrEventsPruned is returned for the DAG specifics of the blocks whose events are pruned.
+var ErrEventsPruned = errors.New("events of the block are pruned")
+
+// GetLowestEventsEpoch returns the lowest epoch which isn't pruned yet, zero if no events were pruned.
+func (s *Store) GetLowestEventsEpoch() idx.Epoch {
+	buf, err := s.table.LowestEventsEpoch.Get([]byte("e"))
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return 0
+	}
+	return idx.BytesToEpoch(buf)
+}
+
+// SetLowestEventsEpoch stores the lowest epoch which isn't pruned yet.
+func (s *Store) SetLowestEventsEpoch(epoch idx.Epoch) {
+	err := s.table.LowestEventsEpoch.Put([]byte("e"), epoch.Bytes())
+	if err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// getLowestLinkedBlock returns the lowest block which may still refer to the events.
+// The blocks are unlinked in order, so the cursor is tracked separately from the epochs.
+func (s *Store) getLowestLinkedBlock() idx.Block {
+	buf, err := s.table.LowestEventsEpoch.Get([]byte("b"))
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		if genesis := s.GetGenesisBlockIndex(); genesis != nil {
+			return *genesis + 1
+		}
+		return 1
+	}
+	return idx.BytesToBlock(buf)
+}
+
+func (s *Store) setLowestLinkedBlock(n idx.Block) {
+	err := s.table.LowestEventsEpoch.Put([]byte("b"), n.Bytes())
+	if err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// isBlockUnlinked returns true if the events of the block are pruned.
+// Every block, except the genesis ones, refers to its Atropos event otherwise.
+func (s *Store) isBlockUnlinked(n idx.Block, block *inter.Block) bool {
+	if genesis := s.GetGenesisBlockIndex(); genesis != nil && n <= *genesis {
+		return false
+	}
+	return len(block.Events) == 0
+}
+
+// PruneEpochEvents deletes the events of the sealed epoch except the events in keep.
+// The blocks of the epoch are rewritten to list their transactions explicitly, including the skipped ones,
+// so the blocks and transactions are served without the events.
+// It's safe to call it again if the pruning was interrupted.
+// Returns false if the last block of the epoch isn't processed yet, the events are kept then.
+func (s *Store) PruneEpochEvents(epoch idx.Epoch, keep hash.EventsSet) bool {
+	if !s.unlinkEpochBlocks(epoch) {
+		return false
+	}
+
+	ids := make(hash.Events, 0, 1000)
+	it := s.table.Events.NewIterator(epoch.Bytes(), nil)
+	for it.Next() {
+		id := hash.BytesToEvent(it.Key())
+		if !keep.Contains(id) {
+			ids = append(ids, id)
+		}
+	}
+	it.Release()
+	for _, id := range ids {
+		s.DelEvent(id)
+	}
+	s.deleteByPrefix(s.table.EventLocalTime, epoch.Bytes())
+	s.deleteByPrefix(s.table.Packs, epoch.Bytes())
+	s.deleteByPrefix(s.table.PacksNum, epoch.Bytes())
+
+	s.SetLowestEventsEpoch(epoch + 1)
+	return true
+}
+
+// unlinkEpochBlocks stores the transactions of the epoch events separately and rewrites
+// the blocks of the epoch and of the prior epochs to list the transactions instead of the events.
+// The blocks are found by the epochs of their Atropos events, starting from the lowest linked block.
+// Returns false if no block of the next epochs is processed yet, so the epoch may have more blocks.
+func (s *Store) unlinkEpochBlocks(epoch idx.Epoch) bool {
+	latest := s.GetLatestBlockIndex()
+	n := s.getLowestLinkedBlock()
+	defer func() {
+		s.setLowestLinkedBlock(n)
+	}()
+	for ; n <= latest; n++ {
+		block := s.GetBlock(n)
+		if block == nil {
+			// blocks prior to a genesis snapshot are missing
+			continue
+		}
+		if block.Atropos.Epoch() > epoch {
+			return true
+		}
+		if len(block.Events) == 0 {
+			continue
+		}
+		_, txs, err := s.getBlockTxsForExecution(n, block)
+		if err != nil {
+			s.Log.Crit("Failed to unlink block", "err", err)
+		}
+		// store the txs of the events, including the skipped ones
+		for _, tx := range txs[len(block.Txs):] {
+			s.evm.SetTx(tx.Hash(), tx)
+			position := s.evm.GetTxPosition(tx.Hash())
+			if position != nil && position.Block == n && !position.Event.IsZero() {
+				s.evm.SetTxPosition(tx.Hash(), evmstore.TxPosition{
+					Block:       position.Block,
+					BlockOffset: position.BlockOffset,
+				})
+			}
+		}
+		s.SetBlock(n, unlinkBlock(block, txs, block.SkippedTxs))
+	}
+	return false
+}
+
+func (s *Store) deleteByPrefix(t kvdb.Store, prefix []byte) {
+	keys := make([][]byte, 0, 1000)
+	it := t.NewIterator(prefix, nil)
+	for it.Next() {
+		keys = append(keys, common.CopyBytes(it.Key()))
+	}
+	it.Release()
+	for _, key := range keys {
+		if err := t.Delete(key); err != nil {
+			s.Log.Crit("Failed to delete key", "err", err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gossip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStorePruneEpochEvents(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	genStore := makegenesis.FakeGenesisStore(genesisStakers, utils.ToFtm(genesisBalance), utils.ToFtm(genesisStake))
+	store := NewMemStore()
+	defer store.Close()
+	_, err := store.ApplyGenesis(DefaultBlockProc(genStore.GetGenesis()), genStore.GetGenesis())
+	require.NoError(err)
+
+	epoch := store.GetEpoch()
+	bs, es := store.GetBlockEpochState()
+	first := bs.LastBlock.Idx + 1
+
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.NewTransaction(nonce, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	}
+	newEvent := func(epoch idx.Epoch, lamport idx.Lamport, txs ...*types.Transaction) *inter.EventPayload {
+		me := &inter.MutableEventPayload{}
+		me.SetEpoch(epoch)
+		me.SetLamport(lamport)
+		me.SetTxs(txs)
+		e := me.Build()
+		store.SetEvent(e)
+		return e
+	}
+
+	// the epoch has 2 blocks, the second tx of the first block is skipped
+	txs := []*types.Transaction{newTx(0), newTx(1), newTx(2), newTx(3)}
+	e1 := newEvent(epoch, 1, txs[0], txs[1])
+	e2 := newEvent(epoch, 2, txs[2])
+	e3 := newEvent(epoch, 3, txs[3])
+	referenced := newEvent(epoch, 4)
+	next := newEvent(epoch+1, 5)
+	store.SetEventLocalTime(e1.ID(), 1)
+	store.SetBlock(first, &inter.Block{
+		Atropos:    e2.ID(),
+		Events:     hash.Events{e1.ID(), e2.ID()},
+		SkippedTxs: []uint32{1},
+	})
+	store.SetBlock(first+1, &inter.Block{
+		Atropos: e3.ID(),
+		Events:  hash.Events{e3.ID()},
+	})
+	positions := map[common.Hash]evmstore.TxPosition{
+		txs[0].Hash(): {Block: first, Event: e1.ID(), EventOffset: 0, BlockOffset: 0},
+		txs[2].Hash(): {Block: first, Event: e2.ID(), EventOffset: 0, BlockOffset: 1},
+		txs[3].Hash(): {Block: first + 1, Event: e3.ID(), EventOffset: 0, BlockOffset: 0},
+	}
+	for txid, pos := range positions {
+		store.evm.SetTxPosition(txid, pos)
+	}
+	bs.LastBlock.Idx = first + 1
+	store.SetBlockEpochState(bs, es)
+
+	blockTxs := func(n idx.Block) []common.Hash {
+		block := (&EvmStateReader{store: store}).GetDagBlock(hash.Event{}, n)
+		hashes := make([]common.Hash, len(block.Transactions))
+		for i, tx := range block.Transactions {
+			hashes[i] = tx.Hash()
+		}
+		return hashes
+	}
+	require.Equal([]common.Hash{txs[0].Hash(), txs[2].Hash()}, blockTxs(first))
+	require.Equal([]common.Hash{txs[3].Hash()}, blockTxs(first+1))
+
+	// the epoch may have more blocks until a block of the next epoch is processed
+	require.False(store.PruneEpochEvents(epoch, hash.NewEventsSet(referenced.ID())))
+	require.Equal(idx.Epoch(0), store.GetLowestEventsEpoch())
+	require.True(store.HasEvent(e1.ID()))
+
+	store.SetBlock(first+2, &inter.Block{
+		Atropos: next.ID(),
+		Events:  hash.Events{next.ID()},
+	})
+	bs.LastBlock.Idx = first + 2
+	store.SetBlockEpochState(bs, es)
+	for i := 0; i < 2; i++ {
+		// the pruning may be repeated if it was interrupted
+		require.True(store.PruneEpochEvents(epoch, hash.NewEventsSet(referenced.ID())))
+		require.Equal(epoch+1, store.GetLowestEventsEpoch())
+
+		for _, id := range []hash.Event{e1.ID(), e2.ID(), e3.ID()} {
+			require.False(store.HasEvent(id))
+		}
+		require.True(store.HasEvent(referenced.ID()))
+		require.True(store.HasEvent(next.ID()))
+		require.Nil(store.GetEventLocalTime(e1.ID()))
+
+		// the blocks are served without the events
+		block := store.GetBlock(first)
+		require.Empty(block.Events)
+		require.Equal([]common.Hash{txs[0].Hash(), txs[1].Hash(), txs[2].Hash()}, block.Txs)
+		require.Equal([]common.Hash{txs[0].Hash(), txs[2].Hash()}, blockTxs(first))
+		require.Equal([]common.Hash{txs[3].Hash()}, blockTxs(first+1))
+		require.Equal(hash.Events{next.ID()}, store.GetBlock(first+2).Events)
+
+		// the skipped txs are kept
+		require.Equal([]uint32{1}, block.SkippedTxs)
+		skipped, err := store.GetSkippedTxs(first, block)
+		require.NoError(err)
+		require.Len(skipped, 1)
+		require.Equal(txs[1].Hash(), skipped[0].Hash())
+
+		// the DAG specifics of the block aren't available
+		backend := &EthAPIBackend{svc: &Service{store: store}}
+		_, _, err = backend.GetDagBlock(context.Background(), rpc.BlockNumber(first))
+		require.Equal(ErrEventsPruned, err)
+		_, dagBlock, err := backend.GetDagBlock(context.Background(), rpc.BlockNumber(first+2))
+		require.NoError(err)
+		require.Equal(next.ID(), dagBlock.Atropos)
+		for txid, pos := range positions {
+			pruned := store.evm.GetTxPosition(txid)
+			require.True(pruned.Event.IsZero())
+			require.Equal(pos.Block, pruned.Block)
+			require.Equal(pos.BlockOffset, pruned.BlockOffset)
+			require.Equal(txid, store.evm.GetTx(txid).Hash())
+		}
+	}
+}
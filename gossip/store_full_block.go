@@ -0,0 +1,144 @@
+package gossip
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// FullBlock is a finalized block with all the data which is required to serve it without the DAG events.
+type FullBlock struct {
+	Number   idx.Block
+	Block    *inter.Block
+	Header   evmcore.EvmHeader
+	Txs      types.Transactions
+	Receipts []*types.ReceiptForStorage
+}
+
+// GetFullBlock returns the block with its EVM header, transactions and receipts.
+func (s *Store) GetFullBlock(n idx.Block) *FullBlock {
+	block := s.GetBlock(n)
+	if block == nil {
+		return nil
+	}
+	evmBlock := (&EvmStateReader{store: s}).GetDagBlock(block.Atropos, n)
+	if evmBlock == nil {
+		return nil
+	}
+
+	receipts := s.evm.GetReceipts(n)
+	receiptsStorage := make([]*types.ReceiptForStorage, len(receipts))
+	for i, r := range receipts {
+		receiptsStorage[i] = (*types.ReceiptForStorage)(r)
+	}
+
+	return &FullBlock{
+		Number:   n,
+		Block:    block,
+		Header:   *evmBlock.Header(),
+		Txs:      evmBlock.Transactions,
+		Receipts: receiptsStorage,
+	}
+}
+
+// Verify checks the consistency of the block data.
+func (b *FullBlock) Verify() error {
+	if b.Block == nil {
+		return errors.New("missing block")
+	}
+	if b.Header.Number == nil || b.Header.Number.Uint64() != uint64(b.Number) {
+		return fmt.Errorf("block %d: mismatched header number", b.Number)
+	}
+	if b.Header.Hash != common.Hash(b.Block.Atropos) {
+		return fmt.Errorf("block %d: mismatched header hash", b.Number)
+	}
+	if b.Header.Root != common.Hash(b.Block.Root) {
+		return fmt.Errorf("block %d: mismatched state root", b.Number)
+	}
+	if len(b.Txs) < len(b.Block.InternalTxs) {
+		return fmt.Errorf("block %d: missing internal txs", b.Number)
+	}
+	for i, txid := range b.Block.InternalTxs {
+		if b.Txs[i].Hash() != txid {
+			return fmt.Errorf("block %d: mismatched internal tx %d", b.Number, i)
+		}
+	}
+	if len(b.Receipts) != 0 && len(b.Receipts) != len(b.Txs) {
+		return fmt.Errorf("block %d: %d receipts for %d txs", b.Number, len(b.Receipts), len(b.Txs))
+	}
+	return nil
+}
+
+// IndexFullBlock stores the block, its transactions, receipts and logs without the transactions execution.
+// The DAG events aren't a part of the full block, so the block is stored with transactions
+// listed explicitly instead of the events.
+func (s *Store) IndexFullBlock(b *FullBlock) error {
+	if err := b.Verify(); err != nil {
+		return err
+	}
+	if prev := s.GetBlock(b.Number); prev != nil {
+		if prev.Atropos != b.Block.Atropos {
+			return fmt.Errorf("block %d: mismatched with already existing block %s", b.Number, prev.Atropos.String())
+		}
+		return nil
+	}
+	if b.Number != 0 {
+		parent := s.GetBlock(b.Number - 1)
+		if parent == nil {
+			return fmt.Errorf("block %d: missing parent block, blocks must be imported in order", b.Number)
+		}
+		if common.Hash(parent.Atropos) != b.Header.ParentHash {
+			return fmt.Errorf("block %d: mismatched parent hash", b.Number)
+		}
+	}
+
+	block := &inter.Block{
+		Time:        b.Block.Time,
+		Atropos:     b.Block.Atropos,
+		Events:      hash.Events{},
+		Txs:         []common.Hash{},
+		InternalTxs: b.Block.InternalTxs,
+		SkippedTxs:  []uint32{},
+		GasUsed:     b.Block.GasUsed,
+		Root:        b.Block.Root,
+	}
+	for i, tx := range b.Txs {
+		s.evm.SetTx(tx.Hash(), tx)
+		if i >= len(b.Block.InternalTxs) {
+			block.Txs = append(block.Txs, tx.Hash())
+		}
+		s.evm.SetTxPosition(tx.Hash(), evmstore.TxPosition{
+			Block:       b.Number,
+			BlockOffset: uint32(i),
+		})
+	}
+
+	if len(b.Receipts) != 0 {
+		s.evm.SetRawReceipts(b.Number, b.Receipts)
+		logIndex := uint(0)
+		for i, r := range b.Receipts {
+			for _, l := range r.Logs {
+				l.BlockNumber = uint64(b.Number)
+				l.BlockHash = b.Header.Hash
+				l.TxHash = b.Txs[i].Hash()
+				l.TxIndex = uint(i)
+				l.Index = logIndex
+				logIndex++
+			}
+			s.evm.IndexLogs(r.Logs...)
+		}
+	}
+
+	s.SetBlockBloom(b.Number, receiptsBloom(b.Receipts))
+	s.SetBlock(b.Number, block)
+	s.SetBlockIndex(hash.Event(b.Header.Hash), b.Number)
+	return nil
+}
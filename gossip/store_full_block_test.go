@@ -0,0 +1,110 @@
+package gossip
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStoreFullBlocksExportImport(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv()
+	defer env.Close()
+
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 2, utils.ToFtm(10)), env.Transfer(100, 1, utils.ToFtm(1)))
+	env.ApplyBlock(nextEpoch, env.Transfer(2, 3, utils.ToFtm(1)))
+	env.ApplyBlock(sameEpoch, env.Transfer(3, 1, utils.ToFtm(1)))
+	env.blockProcWg.Wait()
+	latest := env.store.GetLatestBlockIndex()
+
+	// export
+	buf := new(bytes.Buffer)
+	for n := idx.Block(0); n <= latest; n++ {
+		b := env.store.GetFullBlock(n)
+		require.NotNil(b, n)
+		require.NoError(b.Verify())
+		require.NoError(rlp.Encode(buf, b))
+	}
+
+	// import
+	decode := func(data []byte) []*FullBlock {
+		stream := rlp.NewStream(bytes.NewReader(data), 0)
+		var blocks []*FullBlock
+		for {
+			b := new(FullBlock)
+			if err := stream.Decode(b); err != nil {
+				break
+			}
+			blocks = append(blocks, b)
+		}
+		return blocks
+	}
+	imported := NewMemStore()
+	defer imported.Close()
+	blocks := decode(buf.Bytes())
+	require.Len(blocks, int(latest)+1)
+	// blocks must be imported in order
+	require.Error(imported.IndexFullBlock(blocks[2]))
+	for _, b := range blocks {
+		require.NoError(imported.IndexFullBlock(b))
+	}
+	// already imported block is ignored
+	require.NoError(imported.IndexFullBlock(blocks[1]))
+
+	for n := idx.Block(0); n <= latest; n++ {
+		exp := env.store.GetFullBlock(n)
+		got := imported.GetFullBlock(n)
+		require.NotNil(got, n)
+		require.Equal(exp.Header, got.Header, n)
+		require.Equal(exp.Txs.Len(), got.Txs.Len(), n)
+		for i := range exp.Txs {
+			require.Equal(exp.Txs[i].Hash(), got.Txs[i].Hash())
+			pos := imported.evm.GetTxPosition(got.Txs[i].Hash())
+			require.NotNil(pos)
+			require.Equal(n, pos.Block)
+		}
+		require.Equal(len(exp.Receipts), len(got.Receipts), n)
+		for i := range exp.Receipts {
+			require.Equal(exp.Receipts[i].Status, got.Receipts[i].Status)
+			require.Equal(exp.Receipts[i].CumulativeGasUsed, got.Receipts[i].CumulativeGasUsed)
+			require.Equal(len(exp.Receipts[i].Logs), len(got.Receipts[i].Logs))
+		}
+		require.Equal(n, *imported.GetBlockIndex(hash.Event(exp.Header.Hash)))
+	}
+
+	// tampered blocks are rejected
+	tamper := func(n idx.Block, modify func(b *FullBlock)) *FullBlock {
+		b := decode(buf.Bytes())[n]
+		modify(b)
+		return b
+	}
+	for name, b := range map[string]*FullBlock{
+		"header number": tamper(latest, func(b *FullBlock) { b.Number++ }),
+		"header hash":   tamper(latest, func(b *FullBlock) { b.Header.Hash = common.Hash{1} }),
+		"state root":    tamper(latest, func(b *FullBlock) { b.Header.Root = common.Hash{1} }),
+		"receipts":      tamper(latest-1, func(b *FullBlock) { b.Receipts = b.Receipts[1:] }),
+		"internal txs":  tamper(latest-1, func(b *FullBlock) { b.Txs[0] = env.Transfer(1, 2, utils.ToFtm(1)) }),
+	} {
+		require.Error(b.Verify(), name)
+	}
+	// the block with a tampered parent isn't indexed
+	fresh := NewMemStore()
+	defer fresh.Close()
+	for _, b := range blocks[:latest] {
+		require.NoError(fresh.IndexFullBlock(b))
+	}
+	b := tamper(latest, func(b *FullBlock) { b.Header.ParentHash = common.Hash{1} })
+	require.NoError(b.Verify())
+	require.Error(fresh.IndexFullBlock(b))
+	require.Nil(fresh.GetBlock(latest))
+}
@@ -0,0 +1,212 @@
+package gossip
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/topicsdb"
+)
+
+// logsReindexBatch is the number of blocks whose logs are collected in parallel and indexed at once.
+const logsReindexBatch = 1000
+
+var logsReindexKey = []byte("p")
+
+// LogsReindexProgress is the range of the logs reindexing and the next block to reindex.
+type LogsReindexProgress struct {
+	From idx.Block
+	To   idx.Block
+	Next idx.Block
+}
+
+// GetLogsReindexProgress returns the progress of the interrupted logs reindexing, nil if there's no such one.
+func (s *Store) GetLogsReindexProgress() *LogsReindexProgress {
+	p, _ := s.rlp.Get(s.table.LogsReindex, logsReindexKey, &LogsReindexProgress{}).(*LogsReindexProgress)
+	return p
+}
+
+func (s *Store) setLogsReindexProgress(p *LogsReindexProgress) {
+	s.rlp.Set(s.table.LogsReindex, logsReindexKey, p)
+}
+
+// ReindexLogs rebuilds the logs index of the blocks range from the stored receipts.
+// The index entries of the range are deleted first, then the logs of the blocks are collected
+// by the workers in parallel batches. The progress is saved along with each batch, so the reindexing
+// is resumed if it's started again with the same range after an interruption.
+// The blocks without the stored receipts are skipped.
+// onBatch is called after each batch, the reindexing stops if it returns false.
+func (s *Store) ReindexLogs(from, to idx.Block, workers int, onBatch func(last idx.Block) bool) error {
+	if from > to {
+		return fmt.Errorf("invalid blocks range [%d, %d]", from, to)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	p := s.GetLogsReindexProgress()
+	if p != nil && p.From == from && p.To == to {
+		s.Log.Info("Resuming logs reindexing", "from", from, "to", to, "next", p.Next)
+	} else {
+		if err := s.evm.EvmLogs().DeleteInBlocks(from, to); err != nil {
+			return err
+		}
+		p = &LogsReindexProgress{From: from, To: to, Next: from}
+		s.setLogsReindexProgress(p)
+	}
+
+	for p.Next <= to {
+		last := p.Next + logsReindexBatch - 1
+		if last > to || last < p.Next {
+			last = to
+		}
+		logs, err := s.collectBlocksLogs(p.Next, last, workers)
+		if err != nil {
+			return err
+		}
+		for _, blockLogs := range logs {
+			s.evm.IndexLogs(blockLogs...)
+		}
+		if last == to {
+			s.delete(s.table.LogsReindex, logsReindexKey)
+			return nil
+		}
+		p.Next = last + 1
+		s.setLogsReindexProgress(p)
+		if s.IsCommitNeeded(false) {
+			if err := s.Commit(); err != nil {
+				return err
+			}
+		}
+		if onBatch != nil && !onBatch(last) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// collectBlocksLogs returns the logs of the blocks range in the order of the blocks.
+func (s *Store) collectBlocksLogs(from, to idx.Block, workers int) ([][]*types.Log, error) {
+	var (
+		logs   = make([][]*types.Log, to-from+1)
+		errs   = make([]error, to-from+1)
+		blocks = make(chan idx.Block, workers)
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range blocks {
+				logs[n-from], errs[n-from] = s.GetBlockLogs(n)
+			}
+		}()
+	}
+	for n := from; n <= to && n >= from; n++ {
+		blocks <- n
+	}
+	close(blocks)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return logs, nil
+}
+
+// GetBlockLogs returns the logs of the stored block receipts, nil if the block or the receipts aren't stored.
+// The logs fields which aren't stored along with the receipts are restored from the block.
+func (s *Store) GetBlockLogs(n idx.Block) ([]*types.Log, error) {
+	receipts := s.evm.GetReceipts(n)
+	if len(receipts) == 0 {
+		return nil, nil
+	}
+	block := (&EvmStateReader{store: s}).GetDagBlock(hash.Event{}, n)
+	if block == nil {
+		return nil, nil
+	}
+	txs := block.Transactions
+	if len(txs) != len(receipts) {
+		return nil, fmt.Errorf("block %d: %d receipts for %d txs", n, len(receipts), len(txs))
+	}
+
+	var logs []*types.Log
+	for i, r := range receipts {
+		for _, l := range r.Logs {
+			// the receipts may be cached, so the logs are copied
+			cp := *l
+			cp.BlockNumber = uint64(n)
+			cp.BlockHash = block.Hash
+			cp.TxHash = txs[i].Hash()
+			cp.TxIndex = uint(i)
+			cp.Index = uint(len(logs))
+			logs = append(logs, &cp)
+		}
+	}
+	return logs, nil
+}
+
+// CheckLogsIndex cross-checks the log records of the blocks range against the topic keys of the logs index.
+// The records are checked by the workers in parallel batches while the topic keys are scanned.
+// onIssue is called for every inconsistency, the check stops if it returns false.
+func (s *Store) CheckLogsIndex(from, to idx.Block, workers int, onIssue func(id topicsdb.ID, issue string) bool) error {
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		mu      sync.Mutex
+		stopped bool
+	)
+	report := func(id topicsdb.ID, issue string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped && !onIssue(id, issue) {
+			stopped = true
+		}
+		return !stopped
+	}
+
+	batches := make(chan idx.Block, workers)
+	errs := make(chan error, workers+1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs <- s.evm.EvmLogs().CheckTopics(from, to, report)
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			for batch := range batches {
+				if err != nil {
+					continue
+				}
+				last := batch + logsReindexBatch - 1
+				if last > to || last < batch {
+					last = to
+				}
+				err = s.evm.EvmLogs().CheckRecords(batch, last, report)
+			}
+			errs <- err
+		}()
+	}
+	for batch := from; batch <= to && batch >= from; batch += logsReindexBatch {
+		batches <- batch
+	}
+	close(batches)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package gossip
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/topicsdb"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStoreReindexLogs(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	genStore := makegenesis.FakeGenesisStore(genesisStakers, utils.ToFtm(genesisBalance), utils.ToFtm(genesisStake))
+	store := NewMemStore()
+	defer store.Close()
+	_, err := store.ApplyGenesis(DefaultBlockProc(genStore.GetGenesis()), genStore.GetGenesis())
+	require.NoError(err)
+
+	addr := common.BytesToAddress([]byte("addr"))
+	topic := common.BytesToHash([]byte("topic"))
+	first := store.GetLatestBlockIndex() + 1
+	last := first + logsReindexBatch + 10
+	for n := first; n <= last; n++ {
+		txs := make([]common.Hash, 2)
+		receipts := make(types.Receipts, 2)
+		for i := range txs {
+			tx := types.NewTransaction(uint64(n), addr, big.NewInt(int64(i)), 0, nil, nil)
+			txs[i] = tx.Hash()
+			store.evm.SetTx(txs[i], tx)
+			receipts[i] = &types.Receipt{
+				Logs: []*types.Log{{Address: addr, Topics: []common.Hash{topic}, Data: []byte{byte(i)}}},
+			}
+		}
+		store.SetBlock(n, &inter.Block{
+			Atropos:     hash.Event{byte(n), byte(n >> 8), 1},
+			InternalTxs: txs,
+		})
+		store.evm.SetReceipts(n, receipts)
+	}
+
+	find := func() []*types.Log {
+		var logs []*types.Log
+		err := store.evm.EvmLogs().ForEachInBlocks(first, last, [][]common.Hash{{}, {topic}}, func(l *types.Log) bool {
+			logs = append(logs, l)
+			return true
+		})
+		require.NoError(err)
+		return logs
+	}
+	issues := func() int {
+		count := 0
+		err := store.CheckLogsIndex(first, last, 4, func(topicsdb.ID, string) bool {
+			count++
+			return true
+		})
+		require.NoError(err)
+		return count
+	}
+	require.Empty(find())
+	require.Equal(0, issues())
+
+	// interrupted after the first batch
+	require.NoError(store.ReindexLogs(first, last, 4, func(idx.Block) bool {
+		return false
+	}))
+	require.Len(find(), 2*logsReindexBatch)
+	require.Equal(&LogsReindexProgress{From: first, To: last, Next: first + logsReindexBatch}, store.GetLogsReindexProgress())
+
+	// resumed
+	require.NoError(store.ReindexLogs(first, last, 4, nil))
+	require.Nil(store.GetLogsReindexProgress())
+	logs := find()
+	require.Len(logs, 2*int(last-first+1))
+	for _, l := range logs {
+		n := idx.Block(l.BlockNumber)
+		block := store.GetBlock(n)
+		require.Equal(common.Hash(block.Atropos), l.BlockHash)
+		// one log per tx
+		require.Equal(block.InternalTxs[l.Index], l.TxHash)
+		require.Equal([]byte{byte(l.Index)}, l.Data)
+	}
+	require.Equal(0, issues())
+
+	// the index is rebuilt from scratch
+	require.NoError(store.ReindexLogs(first, last, 1, nil))
+	require.Len(find(), len(logs))
+	require.Equal(0, issues())
+}
@@ -1,6 +1,8 @@
 package gossip
 
 import (
+	"reflect"
+
 	"github.com/Fantom-foundation/lachesis-base/kvdb"
 
 	"github.com/Fantom-foundation/go-opera/utils/migration"
@@ -19,7 +21,42 @@ func (s *Store) Migrate() error {
 		versions.SetID(s.migrations().ID())
 		return nil
 	}
-	return s.migrations().Exec(versions)
+	checkpoints := migration.NewKvdbCheckpointer(s.table.MigrationBackup, s.namedTables())
+	err := s.migrations().ExecWithCheckpoints(versions, checkpoints)
+	if err != nil {
+		// keep the applied steps, the restored tables of the failed step and the failure record
+		if cerr := s.Commit(); cerr != nil {
+			s.Log.Error("Failed to commit the failed migration", "err", cerr)
+		}
+	}
+	return err
+}
+
+// MigrationSteps returns the migration steps of the store in order, the applied steps are marked.
+func (s *Store) MigrationSteps() ([]migration.Step, error) {
+	return s.migrations().Steps(migration.NewKvdbIDStore(s.table.Version))
+}
+
+// GetMigrationFailure returns the failed migration step, nil if the last migration didn't fail.
+func (s *Store) GetMigrationFailure() *migration.Failure {
+	return migration.NewKvdbIDStore(s.table.Version).GetFailure()
+}
+
+// namedTables returns the main DB tables by their names, the migration steps refer to the tables by names.
+// The tables of the migration itself aren't included, so they aren't checkpointed.
+func (s *Store) namedTables() map[string]kvdb.Store {
+	tables := make(map[string]kvdb.Store)
+	v := reflect.ValueOf(&s.table).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if name == "Version" || name == "MigrationBackup" {
+			continue
+		}
+		if t, ok := v.Field(i).Interface().(kvdb.Store); ok {
+			tables[name] = t
+		}
+	}
+	return tables
 }
 
 func (s *Store) migrations() *migration.Migration {
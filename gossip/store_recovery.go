@@ -0,0 +1,108 @@
+package gossip
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// FindRecoverableEpoch returns the latest epoch whose start states are stored along with the EVM state
+// of the epoch start, zero if there's no such epoch.
+// The states history is flushed after the EVM state (the table keys are flushed in the sorted order),
+// so the epoch start states are complete if they are found and the root of the EVM state is available.
+func (s *Store) FindRecoverableEpoch() idx.Epoch {
+	epochs := make([]idx.Epoch, 0, 1000)
+	it := s.table.BlockEpochStateHistory.NewIterator(nil, nil)
+	for it.Next() {
+		epochs = append(epochs, idx.BytesToEpoch(it.Key()))
+	}
+	it.Release()
+
+	for i := len(epochs) - 1; i >= 0; i-- {
+		bs, es := s.GetHistoryBlockEpochState(epochs[i])
+		if bs == nil || es == nil {
+			continue
+		}
+		if _, err := s.evm.StateDB(bs.FinalizedStateRoot); err == nil {
+			return epochs[i]
+		}
+	}
+	return 0
+}
+
+// RollbackToEpoch reverts the store to the start of the epoch, i.e. right after the previous epoch was sealed.
+// The events of the epoch and the subsequent epochs are deleted along with the blocks decided after the epoch start,
+// so the epochs are processed again once their events are connected.
+// The epoch store isn't reset, the caller has to drop the epoch DBs.
+// It's safe to call it again if the rollback was interrupted.
+func (s *Store) RollbackToEpoch(epoch idx.Epoch) error {
+	bs, es := s.GetHistoryBlockEpochState(epoch)
+	if bs == nil || es == nil {
+		return fmt.Errorf("states of epoch %d not found", epoch)
+	}
+	if _, err := s.evm.StateDB(bs.FinalizedStateRoot); err != nil {
+		return fmt.Errorf("EVM state of epoch %d: %v", epoch, err)
+	}
+
+	// delete the blocks decided after the epoch start
+	blocks := make([]idx.Block, 0, 1000)
+	it := s.table.Blocks.NewIterator(nil, (bs.LastBlock.Idx + 1).Bytes())
+	for it.Next() {
+		blocks = append(blocks, idx.BytesToBlock(it.Key()))
+	}
+	it.Release()
+	for _, n := range blocks {
+		if block := s.GetBlock(n); block != nil {
+			s.delete(s.table.BlockHashes, block.Atropos.Bytes())
+			s.cache.BlockHashes.Remove(block.Atropos)
+		}
+		s.delete(s.table.DecisiveEvents, n.Bytes())
+		s.delete(s.table.BlockBlooms, n.Bytes())
+		s.delete(s.table.Blocks, n.Bytes())
+		s.cache.Blocks.Remove(n)
+	}
+
+	s.resetBloomSections(bs.LastBlock.Idx)
+
+	// delete the events of the epoch and the subsequent epochs
+	ids := make(hash.Events, 0, 1000)
+	s.ForEachEventRLP(epoch.Bytes(), func(id hash.Event, _ rlp.RawValue) bool {
+		ids = append(ids, id)
+		return true
+	})
+	for _, id := range ids {
+		s.DelEvent(id)
+	}
+	s.deleteFrom(s.table.EventLocalTime, epoch.Bytes())
+	s.deleteFrom(s.table.Packs, epoch.Bytes())
+	s.deleteFrom(s.table.PacksNum, epoch.Bytes())
+	s.deleteFrom(s.table.BlockEpochStateHistory, (epoch + 1).Bytes())
+	s.deleteFrom(s.table.EpochStats, epoch.Bytes())
+
+	s.SetBlockEpochState(*bs, *es)
+	s.FlushBlockEpochState()
+	return nil
+}
+
+func (s *Store) delete(t kvdb.Store, key []byte) {
+	if err := t.Delete(key); err != nil {
+		s.Log.Crit("Failed to delete key", "err", err)
+	}
+}
+
+// deleteFrom deletes the table keys which are greater or equal to start.
+func (s *Store) deleteFrom(t kvdb.Store, start []byte) {
+	keys := make([][]byte, 0, 1000)
+	it := t.NewIterator(nil, start)
+	for it.Next() {
+		keys = append(keys, common.CopyBytes(it.Key()))
+	}
+	it.Release()
+	for _, key := range keys {
+		s.delete(t, key)
+	}
+}
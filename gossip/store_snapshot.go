@@ -0,0 +1,134 @@
+package gossip
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// getSnapshotInfo returns the snapshot of the sealed epoch, or the empty snapshot
+// if the epoch or its EVM state isn't known.
+func (s *Store) getSnapshotInfo(sealedEpoch idx.Epoch) snapshotInfo {
+	info := snapshotInfo{SealedEpoch: sealedEpoch}
+	bs, es := s.GetHistoryBlockEpochState(sealedEpoch + 1)
+	if bs == nil || es == nil {
+		return info
+	}
+	block := s.GetBlock(bs.LastBlock.Idx)
+	if block == nil {
+		return info
+	}
+	if len(rawdb.ReadTrieNode(s.evm.EvmTable(), common.Hash(bs.FinalizedStateRoot))) == 0 {
+		return info
+	}
+	events := make(inter.EventPayloads, 0, len(es.ValidatorStates))
+	for _, v := range es.ValidatorStates {
+		if v.PrevEpochEvent == hash.ZeroEvent {
+			continue
+		}
+		e := s.GetEventPayload(v.PrevEpochEvent)
+		if e == nil {
+			return info
+		}
+		events = append(events, e)
+	}
+	txs := (&EvmStateReader{store: s}).GetDagBlock(block.Atropos, bs.LastBlock.Idx).Transactions
+	// the skipped txs aren't transferred, they don't affect the state
+	info.BlockState, info.EpochState, info.Block = bs, es, unlinkBlock(block, txs[len(block.InternalTxs):], []uint32{})
+	info.Txs, info.Events = txs, events
+	return info
+}
+
+// getStateNodes returns the EVM state trie nodes and contract codes by hashes.
+// Unknown hashes are skipped.
+func (s *Store) getStateNodes(hashes []common.Hash, sizeLimit int) [][]byte {
+	db := s.evm.EvmTable()
+	nodes := make([][]byte, 0, len(hashes))
+	size := 0
+	for _, h := range hashes {
+		blob := rawdb.ReadTrieNode(db, h)
+		if len(blob) == 0 {
+			blob = rawdb.ReadCode(db, h)
+		}
+		if len(blob) == 0 {
+			continue
+		}
+		nodes = append(nodes, blob)
+		size += len(blob)
+		if size >= sizeLimit {
+			break
+		}
+	}
+	return nodes
+}
+
+// applySnapshot moves the store to the state right after the epoch sealing.
+// The EVM state at info.BlockState.FinalizedStateRoot must be already written.
+// The blocks, transactions and receipts prior to the snapshot aren't indexed, neither are the SFC API tables.
+func (s *Store) applySnapshot(info *snapshotInfo) error {
+	bs, es, block := *info.BlockState, *info.EpochState, info.Block
+	if _, err := s.evm.StateDB(bs.FinalizedStateRoot); err != nil {
+		return fmt.Errorf("snapshot state %s isn't written: %v", bs.FinalizedStateRoot.String(), err)
+	}
+	for i, tx := range info.Txs {
+		s.evm.SetTx(tx.Hash(), tx)
+		s.evm.SetTxPosition(tx.Hash(), evmstore.TxPosition{
+			Block:       bs.LastBlock.Idx,
+			BlockOffset: uint32(i),
+		})
+	}
+	for _, e := range info.Events {
+		s.SetEvent(e)
+	}
+	s.SetBlock(bs.LastBlock.Idx, block)
+	s.SetBlockIndex(block.Atropos, bs.LastBlock.Idx)
+	s.SetBlockEpochState(bs, es)
+	s.SetHistoryBlockEpochState(es.Epoch, bs, es)
+	s.FlushBlockEpochState()
+	// the events prior to the snapshot are unavailable
+	s.SetLowestEventsEpoch(es.Epoch)
+	s.setLowestLinkedBlock(bs.LastBlock.Idx + 1)
+	s.setSnapshotBlockIndex(bs.LastBlock.Idx)
+	return nil
+}
+
+// GetSnapshotBlockIndex returns the block of the applied snapshot, or nil if the node isn't bootstrapped from a snapshot.
+// The SFC API index is built from the SFC logs since genesis, so it's incomplete on such nodes.
+func (s *Store) GetSnapshotBlockIndex() *idx.Block {
+	buf, err := s.table.Genesis.Get([]byte("s"))
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return nil
+	}
+	n := idx.BytesToBlock(buf)
+	return &n
+}
+
+func (s *Store) setSnapshotBlockIndex(n idx.Block) {
+	err := s.table.Genesis.Put([]byte("s"), n.Bytes())
+	if err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// unlinkBlock returns the block which lists its transactions explicitly instead of the events.
+// txs are the non-internal transactions of the block, skippedTxs are the indexes of the skipped ones among them.
+func unlinkBlock(block *inter.Block, txs types.Transactions, skippedTxs []uint32) *inter.Block {
+	unlinked := *block
+	unlinked.Events = hash.Events{}
+	unlinked.Txs = make([]common.Hash, len(txs))
+	unlinked.SkippedTxs = skippedTxs
+	for i, tx := range txs {
+		unlinked.Txs[i] = tx.Hash()
+	}
+	return &unlinked
+}
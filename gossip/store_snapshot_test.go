@@ -0,0 +1,95 @@
+package gossip
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func TestStoreSnapshot(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	env := newTestEnv()
+	defer env.Close()
+
+	env.ApplyBlock(sameEpoch, env.Transfer(1, 2, utils.ToFtm(100)))
+	env.ApplyBlock(nextEpoch)
+	// wait for the EVM state commit
+	env.blockProcWg.Wait()
+	sealedEpoch := env.store.GetEpoch() - 1
+
+	unknown := env.store.getSnapshotInfo(sealedEpoch + 10)
+	require.Error(unknown.validate(sealedEpoch + 10))
+
+	info := env.store.getSnapshotInfo(sealedEpoch)
+	require.NoError(info.validate(sealedEpoch))
+	require.Error(info.validate(sealedEpoch - 1))
+
+	// the info survives the p2p encoding
+	raw, err := rlp.EncodeToBytes(&info)
+	require.NoError(err)
+	var decoded snapshotInfo
+	require.NoError(rlp.DecodeBytes(raw, &decoded))
+	require.NoError(decoded.validate(sealedEpoch))
+	require.NotEmpty(decoded.Txs)
+	require.Equal(info.BlockState.FinalizedStateRoot, decoded.BlockState.FinalizedStateRoot)
+	require.Equal(info.EpochState.Hash(), decoded.EpochState.Hash())
+
+	// download the state into a fresh store
+	genStore := makegenesis.FakeGenesisStore(genesisStakers, utils.ToFtm(genesisBalance), utils.ToFtm(genesisStake))
+	store := NewMemStore()
+	defer store.Close()
+	_, err = store.ApplyGenesis(DefaultBlockProc(genStore.GetGenesis()), genStore.GetGenesis())
+	require.NoError(err)
+
+	db := store.evm.EvmTable()
+	bloom := trie.NewSyncBloom(1, db)
+	defer bloom.Close()
+	sched := state.NewStateSync(common.Hash(decoded.BlockState.FinalizedStateRoot), db, bloom)
+	for sched.Pending() != 0 {
+		nodes, _, codes := sched.Missing(softLimitItems)
+		blobs := env.store.getStateNodes(append(nodes, codes...), math.MaxInt32)
+		require.Equal(len(nodes)+len(codes), len(blobs))
+		for _, blob := range blobs {
+			require.NoError(sched.Process(trie.SyncResult{Hash: crypto.Keccak256Hash(blob), Data: blob}))
+		}
+		batch := db.NewBatch()
+		require.NoError(sched.Commit(batch))
+		require.NoError(batch.Write())
+	}
+
+	require.NoError(store.applySnapshot(&decoded))
+	require.Equal(sealedEpoch+1, store.GetEpoch())
+	require.Equal(info.BlockState.LastBlock.Idx, store.GetLatestBlockIndex())
+	// the snapshot block is served without its events
+	block := (&EvmStateReader{store: store}).GetDagBlock(info.Block.Atropos, info.BlockState.LastBlock.Idx)
+	require.Equal(len(info.Txs), len(block.Transactions))
+	for _, v := range info.EpochState.ValidatorStates {
+		require.True(v.PrevEpochEvent.IsZero() || store.HasEvent(v.PrevEpochEvent))
+	}
+
+	// the state is complete
+	countNodes := func(s *Store) int {
+		statedb, err := s.evm.StateDB(info.BlockState.FinalizedStateRoot)
+		require.NoError(err)
+		nodes := 0
+		it := state.NewNodeIterator(statedb)
+		for it.Next() {
+			nodes++
+		}
+		require.NoError(it.Error)
+		return nodes
+	}
+	require.Equal(countNodes(env.store), countNodes(store))
+}
@@ -0,0 +1,207 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/Fantom-foundation/go-opera/gossip"
+	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
+	"github.com/Fantom-foundation/go-opera/gossip/sfcapi"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+	"github.com/Fantom-foundation/go-opera/topicsdb"
+)
+
+// TableStats is a report of the keys number and size of a DB table.
+type TableStats struct {
+	Name   string        `json:"name"`
+	Prefix string        `json:"prefix"`
+	Keys   uint64        `json:"keys"`
+	Size   uint64        `json:"size"`
+	Tables []*TableStats `json:"tables,omitempty"`
+
+	prefix []byte
+}
+
+// DBStats is a report of the keys number and size of a DB and its tables.
+// Keys which don't belong to any known table are counted in the Unknown table.
+type DBStats struct {
+	Name   string        `json:"name"`
+	Keys   uint64        `json:"keys"`
+	Size   uint64        `json:"size"`
+	Tables []*TableStats `json:"tables"`
+}
+
+// InspectDBs iterates over all the keys of every DB and counts them per table.
+func InspectDBs(producer kvdb.IterableDBProducer) ([]*DBStats, error) {
+	names := producer.Names()
+	sortDBNames(names)
+
+	res := make([]*DBStats, 0, len(names))
+	for _, name := range names {
+		db, err := producer.OpenDB(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open DB %s: %v", name, err)
+		}
+		stats, err := InspectDB(name, db)
+		_ = db.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect DB %s: %v", name, err)
+		}
+		res = append(res, stats)
+	}
+	return res, nil
+}
+
+// InspectDB iterates over all the keys of the DB and counts them per table of the DB layout.
+func InspectDB(name string, db kvdb.Iteratee) (*DBStats, error) {
+	stats := &DBStats{
+		Name:   name,
+		Tables: dbTables(name),
+	}
+	unknown := &TableStats{Name: "Unknown"}
+
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		size := uint64(len(it.Key()) + len(it.Value()))
+		stats.Keys++
+		stats.Size += size
+		if !countKey(stats.Tables, it.Key(), size) {
+			unknown.Keys++
+			unknown.Size += size
+		}
+	}
+	if it.Error() != nil {
+		return nil, it.Error()
+	}
+	if unknown.Keys != 0 {
+		stats.Tables = append(stats.Tables, unknown)
+	}
+	return stats, nil
+}
+
+// countKey adds the key to the table it belongs to and to its sub-tables.
+func countKey(tables []*TableStats, key []byte, size uint64) bool {
+	for _, t := range tables {
+		if !bytes.HasPrefix(key, t.prefix) {
+			continue
+		}
+		t.Keys++
+		t.Size += size
+		countKey(t.Tables, key[len(t.prefix):], size)
+		return true
+	}
+	return false
+}
+
+// dbTables returns the tables layout of a DB by its name.
+func dbTables(name string) []*TableStats {
+	tables := []*TableStats{
+		newTableStats("FlushID", FlushIDKey),
+	}
+	switch {
+	case name == "gossip":
+		tables = append(tables, storeTables((*gossip.Store)(nil), "table")...)
+		tables = append(tables, storeTables((*evmstore.Store)(nil), "table")...)
+		// the EVM tables are created explicitly by evmstore.NewStore
+		evmLogs := newTableStats("EvmLogs", []byte("L"))
+		evmLogs.Tables = storeTables((*topicsdb.Index)(nil), "table")
+		tables = append(tables, newTableStats("EvmState", []byte("M")), evmLogs)
+		for _, t := range tables {
+			if t.Name == "SfcAPI" {
+				t.Tables = storeTables((*sfcapi.Store)(nil), "table")
+			}
+		}
+	case name == "gossip-async":
+		tables = append(tables, tablesOf(reflect.TypeOf(gossip.AsyncDBTables()))...)
+	case isEpochDBName(name, "gossip"):
+		tables = append(tables, tablesOf(reflect.TypeOf(gossip.EpochDBTables()))...)
+	case name == "lachesis":
+		tables = append(tables, storeTables((*abft.Store)(nil), "table")...)
+	case isEpochDBName(name, "lachesis"):
+		tables = append(tables, storeTables((*abft.Store)(nil), "epochTable")...)
+	case name == "genesis":
+		tables = append(tables, storeTables((*genesisstore.Store)(nil), "table")...)
+	}
+	return tables
+}
+
+// storeTables returns the tables declared by the `table:"x"` tags of the store's field.
+func storeTables(store interface{}, field string) []*TableStats {
+	f, ok := reflect.TypeOf(store).Elem().FieldByName(field)
+	if !ok {
+		panic(fmt.Sprintf("%T has no %s field", store, field))
+	}
+	return tablesOf(f.Type)
+}
+
+// tablesOf returns the tables declared by the `table:"x"` tags of the struct.
+func tablesOf(t reflect.Type) []*TableStats {
+	tables := make([]*TableStats, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		prefix := t.Field(i).Tag.Get("table")
+		if prefix == "" || prefix == "-" {
+			continue
+		}
+		tables = append(tables, newTableStats(t.Field(i).Name, []byte(prefix)))
+	}
+	return tables
+}
+
+func newTableStats(name string, prefix []byte) *TableStats {
+	return &TableStats{
+		Name:   name,
+		Prefix: printablePrefix(prefix),
+		prefix: prefix,
+	}
+}
+
+func printablePrefix(prefix []byte) string {
+	for _, b := range prefix {
+		if b < 0x20 || b > 0x7e {
+			return hexutil.Encode(prefix)
+		}
+	}
+	return string(prefix)
+}
+
+func isEpochDBName(name, base string) bool {
+	_, ok := epochOfDBName(name, base)
+	return ok
+}
+
+func epochOfDBName(name, base string) (uint64, bool) {
+	if !strings.HasPrefix(name, base+"-") {
+		return 0, false
+	}
+	epoch, err := strconv.ParseUint(name[len(base)+1:], 10, 32)
+	return epoch, err == nil
+}
+
+// sortDBNames sorts the names alphabetically, and the epoch DBs by epoch number.
+func sortDBNames(names []string) {
+	split := func(name string) (string, uint64) {
+		if i := strings.LastIndexByte(name, '-'); i >= 0 {
+			if epoch, ok := epochOfDBName(name, name[:i]); ok {
+				return name[:i], epoch
+			}
+		}
+		return name, 0
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, aEpoch := split(names[i])
+		b, bEpoch := split(names[j])
+		if a != b {
+			return a < b
+		}
+		return aEpoch < bEpoch
+	})
+}
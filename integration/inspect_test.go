@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip"
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+	"github.com/Fantom-foundation/go-opera/utils"
+	"github.com/Fantom-foundation/go-opera/vecmt"
+)
+
+func TestInspectDBs(t *testing.T) {
+	require := require.New(t)
+
+	rawProducer := memorydb.NewProducer("")
+	genStore := makegenesis.FakeGenesisStore(3, utils.ToFtm(1), utils.ToFtm(1))
+	_, _, store, s2, s3, _ := MakeEngine(rawProducer, InputGenesis{
+		Hash: genStore.Hash(),
+		Read: func(store *genesisstore.Store) error {
+			buf := bytes.NewBuffer(nil)
+			err := genStore.Export(buf)
+			if err != nil {
+				return err
+			}
+			return store.Import(buf)
+		},
+		Close: func() error {
+			return nil
+		},
+	}, Configs{
+		Opera:         gossip.DefaultConfig(),
+		OperaStore:    gossip.DefaultStoreConfig(),
+		Lachesis:      abft.DefaultConfig(),
+		LachesisStore: abft.DefaultStoreConfig(),
+		VectorClock:   vecmt.DefaultConfig(),
+	})
+	require.NoError(store.Commit())
+	store.Close()
+	s2.Close()
+	s3.Close()
+
+	db, err := rawProducer.OpenDB("gossip")
+	require.NoError(err)
+	require.NoError(db.Put([]byte{0xff, 1}, []byte{2}))
+	require.NoError(db.Close())
+	for _, name := range []string{"gossip-10", "gossip-2"} {
+		db, err := rawProducer.OpenDB(name)
+		require.NoError(err)
+		require.NoError(db.Put([]byte("t1"), []byte{2}))
+		require.NoError(db.Close())
+	}
+
+	stats, err := InspectDBs(rawProducer)
+	require.NoError(err)
+	names := make([]string, len(stats))
+	for i, db := range stats {
+		names[i] = db.Name
+	}
+	require.Equal([]string{"genesis", "gossip", "gossip-2", "gossip-10", "gossip-async", "lachesis"}, names)
+
+	tables := func(db *DBStats) map[string]*TableStats {
+		res := map[string]*TableStats{}
+		var keys uint64
+		for _, t := range db.Tables {
+			res[t.Name] = t
+			keys += t.Keys
+		}
+		require.Equal(db.Keys, keys, db.Name)
+		return res
+	}
+	for _, db := range stats {
+		tt := tables(db)
+		if db.Name == "gossip" {
+			require.Equal(uint64(1), tt["Unknown"].Keys)
+			require.Equal(uint64(3), tt["Unknown"].Size)
+			require.NotZero(tt["Blocks"].Keys)
+			require.NotZero(tt["EvmState"].Keys)
+			require.NotZero(tt["EvmLogs"].Keys)
+			require.NotZero(tt["EvmLogs"].Tables[1].Keys) // Logrec
+		} else if db.Name == "gossip-2" {
+			require.Equal(uint64(1), tt["Tips"].Keys)
+		} else {
+			require.Nil(tt["Unknown"], db.Name)
+		}
+	}
+}
+
+func TestDBTablesPrefixes(t *testing.T) {
+	var check func(tables []*TableStats)
+	check = func(tables []*TableStats) {
+		for i, a := range tables {
+			require.NotEmpty(t, a.prefix, a.Name)
+			for _, b := range tables[i+1:] {
+				require.False(t, bytes.HasPrefix(a.prefix, b.prefix) || bytes.HasPrefix(b.prefix, a.prefix),
+					"tables %s and %s have overlapping prefixes", a.Name, b.Name)
+			}
+			check(a.Tables)
+		}
+	}
+	for _, name := range []string{"gossip", "gossip-async", "gossip-1", "lachesis", "lachesis-1", "genesis"} {
+		tables := dbTables(name)
+		require.Greater(t, len(tables), 1, name)
+		check(tables)
+	}
+}
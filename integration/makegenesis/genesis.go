@@ -88,6 +88,39 @@ func FakeGenesisStore(num int, balance, stake *big.Int) *genesisstore.Store {
 		Root:        hash.Hash{},
 		Receipts:    []*types.ReceiptForStorage{},
 	})
+	preDeploySystemContracts(genStore)
+
+	return genStore
+}
+
+func GetFakeValidators(num int) gpos.Validators {
+	validators := make(gpos.Validators, 0, num)
+
+	for i := 1; i <= num; i++ {
+		key := FakeKey(i)
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		pubkeyraw := crypto.FromECDSAPub(&key.PublicKey)
+		validatorID := idx.ValidatorID(i)
+		validators = append(validators, gpos.Validator{
+			ID:      validatorID,
+			Address: addr,
+			PubKey: validatorpk.PubKey{
+				Raw:  pubkeyraw,
+				Type: validatorpk.Types.Secp256k1,
+			},
+			CreationTime:     FakeGenesisTime,
+			CreationEpoch:    0,
+			DeactivatedTime:  0,
+			DeactivatedEpoch: 0,
+			Status:           0,
+		})
+	}
+
+	return validators
+}
+
+// preDeploySystemContracts sets the code of the system contracts.
+func preDeploySystemContracts(genStore *genesisstore.Store) {
 	// pre deploy NetworkInitializer
 	genStore.SetEvmAccount(netinit.ContractAddress, genesis.Account{
 		Code:    netinit.GetContractBin(),
@@ -118,32 +151,4 @@ func FakeGenesisStore(num int, balance, stake *big.Int) *genesisstore.Store {
 		Balance: new(big.Int),
 		Nonce:   0,
 	})
-
-	return genStore
-}
-
-func GetFakeValidators(num int) gpos.Validators {
-	validators := make(gpos.Validators, 0, num)
-
-	for i := 1; i <= num; i++ {
-		key := FakeKey(i)
-		addr := crypto.PubkeyToAddress(key.PublicKey)
-		pubkeyraw := crypto.FromECDSAPub(&key.PublicKey)
-		validatorID := idx.ValidatorID(i)
-		validators = append(validators, gpos.Validator{
-			ID:      validatorID,
-			Address: addr,
-			PubKey: validatorpk.PubKey{
-				Raw:  pubkeyraw,
-				Type: validatorpk.Types.Secp256k1,
-			},
-			CreationTime:     FakeGenesisTime,
-			CreationEpoch:    0,
-			DeactivatedTime:  0,
-			DeactivatedEpoch: 0,
-			Status:           0,
-		})
-	}
-
-	return validators
 }
@@ -0,0 +1,264 @@
+package makegenesis
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/opera/genesis"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/driver"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/driverauth"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/evmwriter"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/gpos"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/netinit"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/sfc"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+)
+
+type (
+	// GenesisSpec is a declarative description of a new network genesis.
+	// Amounts are decimal or 0x-prefixed hex numbers, times are unix seconds.
+	GenesisSpec struct {
+		// Rules of the network, the omitted fields are taken from the mainnet rules
+		Rules       opera.Rules
+		FirstEpoch  idx.Epoch
+		Time        uint64
+		ExtraData   hexutil.Bytes
+		DriverOwner common.Address
+		// TotalSupply is the sum of the balances if omitted
+		TotalSupply *math.HexOrDecimal256
+
+		Accounts    []AccountSpec
+		Validators  []ValidatorSpec
+		Delegations []DelegationSpec
+	}
+	// AccountSpec is an EVM account of a genesis spec.
+	AccountSpec struct {
+		Address common.Address
+		Balance *math.HexOrDecimal256
+		Nonce   uint64
+		Code    hexutil.Bytes
+		Storage map[common.Hash]common.Hash
+	}
+	// ValidatorSpec is a validator of a genesis spec.
+	// The stake is self-delegated from the validator address.
+	ValidatorSpec struct {
+		ID      idx.ValidatorID
+		Address common.Address
+		PubKey  validatorpk.PubKey
+		Stake   *math.HexOrDecimal256
+	}
+	// DelegationSpec is a delegation of a genesis spec.
+	DelegationSpec struct {
+		Delegator       common.Address
+		ValidatorID     idx.ValidatorID
+		Stake           *math.HexOrDecimal256
+		LockedStake     *math.HexOrDecimal256
+		LockupFromEpoch idx.Epoch
+		LockupEndTime   uint64
+		LockupDuration  uint64
+	}
+)
+
+// NewGenesisSpec returns a spec with the default values of the optional fields.
+// A spec file should be decoded into it.
+func NewGenesisSpec() *GenesisSpec {
+	return &GenesisSpec{
+		Rules:      opera.MainNetRules(),
+		FirstEpoch: 2,
+	}
+}
+
+func bigOf(v *math.HexOrDecimal256) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(v)
+}
+
+func isSystemContract(addr common.Address) bool {
+	switch addr {
+	case netinit.ContractAddress, driver.ContractAddress, driverauth.ContractAddress, sfc.ContractAddress, evmwriter.ContractAddress:
+		return true
+	}
+	return false
+}
+
+// Validate checks the consistency of the spec.
+func (spec *GenesisSpec) Validate() error {
+	if spec.Rules.NetworkID == 0 || len(spec.Rules.Name) == 0 {
+		return errors.New("network name and ID must be specified")
+	}
+	if spec.FirstEpoch < 2 {
+		return errors.New("first epoch must be at least 2")
+	}
+	if spec.Time == 0 {
+		return errors.New("genesis time must be specified")
+	}
+	if spec.DriverOwner == (common.Address{}) {
+		return errors.New("driver owner must be specified")
+	}
+
+	balances := new(big.Int)
+	accounts := make(map[common.Address]bool, len(spec.Accounts))
+	for _, acc := range spec.Accounts {
+		if accounts[acc.Address] {
+			return fmt.Errorf("account %s is duplicated", acc.Address.String())
+		}
+		accounts[acc.Address] = true
+		if isSystemContract(acc.Address) {
+			return fmt.Errorf("account %s is a system contract", acc.Address.String())
+		}
+		if bigOf(acc.Balance).Sign() < 0 {
+			return fmt.Errorf("account %s has negative balance", acc.Address.String())
+		}
+		if len(acc.Storage) != 0 && len(acc.Code) == 0 {
+			return fmt.Errorf("account %s has storage but no code", acc.Address.String())
+		}
+		balances.Add(balances, bigOf(acc.Balance))
+	}
+	if spec.TotalSupply != nil && bigOf(spec.TotalSupply).Cmp(balances) < 0 {
+		return fmt.Errorf("total supply %s is less than the sum of balances %s", bigOf(spec.TotalSupply), balances)
+	}
+
+	if len(spec.Validators) == 0 {
+		return errors.New("no validators")
+	}
+	validators := make(map[idx.ValidatorID]bool, len(spec.Validators))
+	type delegationID struct {
+		delegator common.Address
+		to        idx.ValidatorID
+	}
+	delegations := make(map[delegationID]bool, len(spec.Validators)+len(spec.Delegations))
+	for _, v := range spec.Validators {
+		if v.ID == 0 {
+			return errors.New("validator ID must be non-zero")
+		}
+		if validators[v.ID] {
+			return fmt.Errorf("validator %d is duplicated", v.ID)
+		}
+		validators[v.ID] = true
+		if v.PubKey.Empty() {
+			return fmt.Errorf("validator %d has no pubkey", v.ID)
+		}
+		if v.Address == (common.Address{}) {
+			return fmt.Errorf("validator %d has no address", v.ID)
+		}
+		if bigOf(v.Stake).Sign() <= 0 {
+			return fmt.Errorf("validator %d must have non-zero stake", v.ID)
+		}
+		delegations[delegationID{v.Address, v.ID}] = true
+	}
+	for _, d := range spec.Delegations {
+		if !validators[d.ValidatorID] {
+			return fmt.Errorf("delegation of %s to unknown validator %d", d.Delegator.String(), d.ValidatorID)
+		}
+		id := delegationID{d.Delegator, d.ValidatorID}
+		if delegations[id] {
+			return fmt.Errorf("delegation of %s to validator %d is duplicated", d.Delegator.String(), d.ValidatorID)
+		}
+		delegations[id] = true
+		if bigOf(d.Stake).Sign() <= 0 {
+			return fmt.Errorf("delegation of %s to validator %d must have non-zero stake", d.Delegator.String(), d.ValidatorID)
+		}
+		if bigOf(d.LockedStake).Sign() < 0 || bigOf(d.LockedStake).Cmp(bigOf(d.Stake)) > 0 {
+			return fmt.Errorf("delegation of %s to validator %d has invalid locked stake", d.Delegator.String(), d.ValidatorID)
+		}
+		if bigOf(d.LockedStake).Sign() != 0 && (d.LockupEndTime == 0 || d.LockupDuration == 0) {
+			return fmt.Errorf("delegation of %s to validator %d has locked stake without lockup period", d.Delegator.String(), d.ValidatorID)
+		}
+	}
+	return nil
+}
+
+// BuildGenesisStore validates the spec and writes the genesis into the store.
+func BuildGenesisStore(spec *GenesisSpec, genStore *genesisstore.Store) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	genesisTime := inter.FromUnix(int64(spec.Time))
+
+	genStore.SetRules(spec.Rules)
+
+	totalSupply := new(big.Int)
+	for _, acc := range spec.Accounts {
+		code := acc.Code
+		if code == nil {
+			code = []byte{}
+		}
+		genStore.SetEvmAccount(acc.Address, genesis.Account{
+			Code:    code,
+			Balance: bigOf(acc.Balance),
+			Nonce:   acc.Nonce,
+		})
+		for key, val := range acc.Storage {
+			genStore.SetEvmState(acc.Address, key, val)
+		}
+		totalSupply.Add(totalSupply, bigOf(acc.Balance))
+	}
+	if spec.TotalSupply != nil {
+		totalSupply = bigOf(spec.TotalSupply)
+	}
+
+	validators := make(gpos.Validators, 0, len(spec.Validators))
+	for _, v := range spec.Validators {
+		validators = append(validators, gpos.Validator{
+			ID:            v.ID,
+			Address:       v.Address,
+			PubKey:        v.PubKey,
+			CreationTime:  genesisTime,
+			CreationEpoch: 0,
+			Status:        0,
+		})
+		genStore.SetDelegation(v.Address, v.ID, genesis.Delegation{
+			Stake:              bigOf(v.Stake),
+			Rewards:            new(big.Int),
+			LockedStake:        new(big.Int),
+			EarlyUnlockPenalty: new(big.Int),
+		})
+	}
+	for _, d := range spec.Delegations {
+		genStore.SetDelegation(d.Delegator, d.ValidatorID, genesis.Delegation{
+			Stake:           bigOf(d.Stake),
+			Rewards:         new(big.Int),
+			LockedStake:     bigOf(d.LockedStake),
+			LockupFromEpoch: d.LockupFromEpoch,
+			// lockup timestamps are passed to SFC as is, see drivercall.SetGenesisDelegation
+			LockupEndTime:      inter.Timestamp(d.LockupEndTime),
+			LockupDuration:     inter.Timestamp(d.LockupDuration),
+			EarlyUnlockPenalty: new(big.Int),
+		})
+	}
+
+	genStore.SetMetadata(genesisstore.Metadata{
+		Validators:    validators,
+		FirstEpoch:    spec.FirstEpoch,
+		Time:          genesisTime,
+		PrevEpochTime: genesisTime - inter.Timestamp(time.Hour),
+		ExtraData:     spec.ExtraData,
+		DriverOwner:   spec.DriverOwner,
+		TotalSupply:   totalSupply,
+	})
+	genStore.SetBlock(0, genesis.Block{
+		Time:        genesisTime - inter.Timestamp(time.Minute),
+		Atropos:     hash.Event{},
+		Txs:         types.Transactions{},
+		InternalTxs: types.Transactions{},
+		Root:        hash.Hash{},
+		Receipts:    []*types.ReceiptForStorage{},
+	})
+	preDeploySystemContracts(genStore)
+
+	return nil
+}
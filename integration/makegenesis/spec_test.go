@@ -0,0 +1,118 @@
+package makegenesis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip"
+	"github.com/Fantom-foundation/go-opera/opera/genesis"
+	"github.com/Fantom-foundation/go-opera/opera/genesis/sfc"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func amount(ftm uint64) *math.HexOrDecimal256 {
+	return (*math.HexOrDecimal256)(utils.ToFtm(ftm))
+}
+
+func testGenesisSpec() *GenesisSpec {
+	spec := NewGenesisSpec()
+	spec.Rules.Name = "private"
+	spec.Rules.NetworkID = 4242
+	spec.Time = uint64(FakeGenesisTime.Unix())
+
+	validators := GetFakeValidators(2)
+	spec.DriverOwner = validators[0].Address
+	for _, v := range validators {
+		spec.Accounts = append(spec.Accounts, AccountSpec{
+			Address: v.Address,
+			Balance: amount(1000),
+		})
+		spec.Validators = append(spec.Validators, ValidatorSpec{
+			ID:      v.ID,
+			Address: v.Address,
+			PubKey:  v.PubKey,
+			Stake:   amount(5000000),
+		})
+	}
+	spec.Delegations = append(spec.Delegations, DelegationSpec{
+		Delegator:      crypto.PubkeyToAddress(FakeKey(100).PublicKey),
+		ValidatorID:    1,
+		Stake:          amount(100),
+		LockedStake:    amount(50),
+		LockupEndTime:  spec.Time + 86400,
+		LockupDuration: 86400,
+	})
+	return spec
+}
+
+func TestBuildGenesisStore(t *testing.T) {
+	require := require.New(t)
+
+	spec := testGenesisSpec()
+	genStore := genesisstore.NewMemStore()
+	require.NoError(BuildGenesisStore(spec, genStore))
+
+	g := genStore.GetGenesis()
+	require.Equal("private", g.Rules.Name)
+	require.Equal(2, len(g.Validators))
+	require.Equal(utils.ToFtm(2000), g.TotalSupply)
+	delegations := 0
+	g.Delegations.ForEach(func(common.Address, idx.ValidatorID, genesis.Delegation) {
+		delegations++
+	})
+	require.Equal(3, delegations)
+
+	// the genesis is applicable
+	store := gossip.NewMemStore()
+	defer store.Close()
+	_, err := store.ApplyGenesis(gossip.DefaultBlockProc(g), g)
+	require.NoError(err)
+	validators, _ := store.GetEpochValidators()
+	require.Equal(idx.Validator(2), validators.Len())
+}
+
+func TestGenesisSpecValidate(t *testing.T) {
+	require := require.New(t)
+
+	for name, corrupt := range map[string]func(*GenesisSpec){
+		"no network ID": func(spec *GenesisSpec) {
+			spec.Rules.NetworkID = 0
+		},
+		"no validators": func(spec *GenesisSpec) {
+			spec.Validators = nil
+		},
+		"duplicated validator": func(spec *GenesisSpec) {
+			spec.Validators[1].ID = spec.Validators[0].ID
+		},
+		"zero validator stake": func(spec *GenesisSpec) {
+			spec.Validators[0].Stake = nil
+		},
+		"zero delegation stake": func(spec *GenesisSpec) {
+			spec.Delegations[0].Stake = amount(0)
+		},
+		"unknown delegation validator": func(spec *GenesisSpec) {
+			spec.Delegations[0].ValidatorID = 10
+		},
+		"locked stake exceeds stake": func(spec *GenesisSpec) {
+			spec.Delegations[0].LockedStake = amount(1000)
+		},
+		"total supply is less than balances": func(spec *GenesisSpec) {
+			spec.TotalSupply = (*math.HexOrDecimal256)(big.NewInt(1))
+		},
+		"system contract account": func(spec *GenesisSpec) {
+			spec.Accounts[0].Address = sfc.ContractAddress
+		},
+	} {
+		spec := testGenesisSpec()
+		corrupt(spec)
+		require.Error(spec.Validate(), name)
+	}
+	require.NoError(testGenesisSpec().Validate())
+}
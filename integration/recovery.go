@@ -0,0 +1,338 @@
+package integration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/common/bigendian"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/flushable"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/Fantom-foundation/go-opera/gossip"
+)
+
+var (
+	errRecoveryImpossible = errors.New("recovery is impossible")
+
+	initialFlushID = []byte("initial")
+)
+
+// flushMarker is a parsed flush ID of a DB.
+// The flushable DBs are flushed in 3 phases: all the DBs are marked as dirty (0xde + previous ID + new ID),
+// the data are written, and all the DBs are marked as clean (0x00 + new ID).
+type flushMarker struct {
+	dirty bool
+	prev  []byte
+	id    []byte
+}
+
+func parseFlushMarker(marker []byte) (flushMarker, bool) {
+	if len(marker) < 2 {
+		return flushMarker{}, false
+	}
+	if marker[0] == flushable.CleanPrefix {
+		return flushMarker{id: marker[1:]}, true
+	}
+	if marker[0] != flushable.DirtyPrefix {
+		return flushMarker{}, false
+	}
+	ids := marker[1:]
+	if bytes.HasPrefix(ids, initialFlushID) {
+		return flushMarker{dirty: true, prev: initialFlushID, id: ids[len(initialFlushID):]}, len(ids) > len(initialFlushID)
+	}
+	// previous ID is a clean marker of the same length as the new ID
+	if len(ids)%2 != 1 || ids[0] != flushable.CleanPrefix {
+		return flushMarker{}, false
+	}
+	idLen := (len(ids) - 1) / 2
+	return flushMarker{dirty: true, prev: ids[:1+idLen], id: ids[1+idLen:]}, idLen > 0
+}
+
+func cleanFlushMarker(id []byte) []byte {
+	return append([]byte{flushable.CleanPrefix}, id...)
+}
+
+// RecoverDBs brings the DBs to the last consistent flush after an unclean shutdown.
+// If the shutdown happened while the DBs were being marked as dirty or clean, the markers are fixed.
+// Otherwise, the DBs are rolled back to the start of the latest epoch whose state is fully flushed,
+// and the events of the rolled back epochs are written into the journal file (RLP stream of events),
+// so they may be connected again instead of downloading them.
+// All the DBs are dropped if the recovery is impossible.
+// It's safe to call it again if the recovery was interrupted.
+func RecoverDBs(rawProducer kvdb.IterableDBProducer, journal string, cfg Configs) error {
+	err := recoverDBs(rawProducer, journal, cfg)
+	if err == errRecoveryImpossible {
+		log.Warn("DBs recovery is impossible, dropping all the DBs")
+		dropAllDBs(rawProducer)
+		return nil
+	}
+	return err
+}
+
+func recoverDBs(rawProducer kvdb.IterableDBProducer, journal string, cfg Configs) error {
+	names := rawProducer.Names()
+	raw := make(map[string][]byte, len(names))
+	written := 0
+	for _, name := range names {
+		db, err := rawProducer.OpenDB(name)
+		if err != nil {
+			return err
+		}
+		raw[name], err = db.Get(FlushIDKey)
+		_ = db.Close()
+		if err != nil {
+			return err
+		}
+		if raw[name] != nil {
+			written++
+		}
+	}
+	if written == 0 {
+		// either no DBs or the genesis processing was interrupted
+		return nil
+	}
+	for _, name := range []string{"gossip", "lachesis", "genesis"} {
+		if raw[name] == nil {
+			log.Warn("DB is missing or was never flushed", "name", name)
+			return errRecoveryImpossible
+		}
+	}
+
+	// the DBs created after the last flush
+	markers := make(map[string]flushMarker, len(raw))
+	var drop []string
+	for name, marker := range raw {
+		if marker == nil {
+			drop = append(drop, name)
+			continue
+		}
+		m, ok := parseFlushMarker(marker)
+		if !ok {
+			log.Warn("Malformed flush ID, rolling back", "name", name)
+			return rollbackDBs(rawProducer, journal, cfg, drop)
+		}
+		markers[name] = m
+	}
+
+	var (
+		clean []byte
+		flush []byte
+		mixed bool
+	)
+	for _, m := range markers {
+		if !m.dirty {
+			if clean != nil && !bytes.Equal(clean, m.id) {
+				mixed = true
+			}
+			clean = m.id
+			continue
+		}
+		if flush != nil && !bytes.Equal(flush, m.id) {
+			mixed = true
+		}
+		flush = m.id
+	}
+	switch {
+	case mixed:
+		log.Warn("DBs are flushed with different flush IDs, rolling back")
+		return rollbackDBs(rawProducer, journal, cfg, drop)
+	case flush == nil:
+		// all the DBs are clean
+		return dropDBs(rawProducer, drop)
+	case clean != nil && bytes.Equal(clean, flush):
+		// interrupted while marking the DBs as clean, all the data are flushed
+		log.Warn("Flush was interrupted after the data were written, fixing flush IDs")
+		for name, m := range markers {
+			if m.dirty {
+				if err := putFlushID(rawProducer, name, cleanFlushMarker(flush)); err != nil {
+					return err
+				}
+			}
+		}
+		return dropDBs(rawProducer, drop)
+	case clean != nil:
+		// interrupted while marking the DBs as dirty, no data are written
+		prev := cleanFlushMarker(clean)
+		for _, m := range markers {
+			if m.dirty && !bytes.Equal(m.prev, prev) && !bytes.Equal(m.prev, initialFlushID) {
+				log.Warn("Flush IDs mismatch, rolling back")
+				return rollbackDBs(rawProducer, journal, cfg, drop)
+			}
+		}
+		log.Warn("Flush was interrupted before the data were written, restoring flush IDs")
+		for name, m := range markers {
+			if !m.dirty {
+				continue
+			}
+			if bytes.Equal(m.prev, initialFlushID) {
+				drop = append(drop, name)
+				continue
+			}
+			if err := putFlushID(rawProducer, name, prev); err != nil {
+				return err
+			}
+		}
+		if err := dropDBs(rawProducer, drop); err != nil {
+			return err
+		}
+		// the epoch DBs may be dropped before the DBs are marked as dirty
+		if !hasEpochDBs(rawProducer, cfg) {
+			log.Warn("Epoch DBs are missing, rolling back")
+			return rollbackDBs(rawProducer, journal, cfg, nil)
+		}
+		return nil
+	default:
+		// interrupted while the data were written
+		log.Warn("Flush was interrupted while the data were written, rolling back")
+		return rollbackDBs(rawProducer, journal, cfg, drop)
+	}
+}
+
+// hasEpochDBs checks that the DBs of the current epoch exist.
+func hasEpochDBs(rawProducer kvdb.IterableDBProducer, cfg Configs) bool {
+	gdb, cdb, genesisStore := getStores(&DummyFlushableProducer{rawProducer}, cfg)
+	gossipEpoch := fmt.Sprintf("gossip-%d", gdb.GetEpoch())
+	lachesisEpoch := fmt.Sprintf("lachesis-%d", cdb.GetEpoch())
+	gdb.Close()
+	_ = cdb.Close()
+	genesisStore.Close()
+
+	exists := map[string]bool{}
+	for _, name := range rawProducer.Names() {
+		exists[name] = true
+	}
+	return exists[gossipEpoch] && exists[lachesisEpoch]
+}
+
+// rollbackDBs reverts the DBs to the start of the latest epoch whose state is fully flushed.
+func rollbackDBs(rawProducer kvdb.IterableDBProducer, journal string, cfg Configs, drop []string) error {
+	if err := dropDBs(rawProducer, drop); err != nil {
+		return err
+	}
+
+	gdb, cdb, genesisStore := getStores(&DummyFlushableProducer{rawProducer}, cfg)
+	epoch := gdb.FindRecoverableEpoch()
+	if epoch == 0 {
+		gdb.Close()
+		_ = cdb.Close()
+		genesisStore.Close()
+		log.Warn("No epoch with a fully flushed state")
+		return errRecoveryImpossible
+	}
+	_, es := gdb.GetHistoryBlockEpochState(epoch)
+	log.Warn("Rolling back DBs", "epoch", epoch)
+
+	var block idx.Block
+	err := func() error {
+		defer genesisStore.Close()
+		defer cdb.Close()
+		defer gdb.Close()
+		if journal != "" {
+			if err := writeEventsJournal(journal, gdb, epoch); err != nil {
+				return fmt.Errorf("failed to write events journal: %v", err)
+			}
+		}
+		if err := gdb.RollbackToEpoch(epoch); err != nil {
+			return err
+		}
+		cdb.SetEpochState(&abft.EpochState{Epoch: epoch, Validators: es.Validators})
+		cdb.SetLastDecidedState(&abft.LastDecidedState{LastDecidedFrame: abft.FirstFrame - 1})
+		block = gdb.GetLatestBlockIndex()
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	// the epoch DBs are re-created from scratch
+	var epochDBs []string
+	for _, name := range rawProducer.Names() {
+		if isEpochDBName(name, "gossip") || isEpochDBName(name, "lachesis") {
+			epochDBs = append(epochDBs, name)
+		}
+	}
+	if err := dropDBs(rawProducer, epochDBs); err != nil {
+		return err
+	}
+	flushID := cleanFlushMarker(bigendian.Uint64ToBytes(uint64(time.Now().UnixNano())))
+	for _, name := range rawProducer.Names() {
+		if err := putFlushID(rawProducer, name, flushID); err != nil {
+			return err
+		}
+	}
+	log.Warn("Rolled back DBs", "epoch", epoch, "block", block)
+	return nil
+}
+
+// writeEventsJournal writes the events of the epoch and the subsequent epochs into the journal file.
+// The events of the existing journal are kept.
+func writeEventsJournal(fn string, gdb *gossip.Store, from idx.Epoch) error {
+	tmp := fn + ".tmp"
+	fh, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	if prev, err := os.Open(fn); err == nil {
+		_, err = io.Copy(fh, prev)
+		_ = prev.Close()
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	events := 0
+	gdb.ForEachEventRLP(from.Bytes(), func(_ hash.Event, e rlp.RawValue) bool {
+		_, err = fh.Write(e)
+		events++
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := fh.Sync(); err != nil {
+		return err
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	log.Info("Saved events into journal", "file", fn, "events", events)
+	return os.Rename(tmp, fn)
+}
+
+func putFlushID(rawProducer kvdb.IterableDBProducer, name string, flushID []byte) error {
+	db, err := rawProducer.OpenDB(name)
+	if err != nil {
+		return err
+	}
+	err = db.Put(FlushIDKey, flushID)
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+func dropDBs(rawProducer kvdb.IterableDBProducer, names []string) error {
+	for _, name := range names {
+		db, err := rawProducer.OpenDB(name)
+		if err != nil {
+			return err
+		}
+		_ = db.Close()
+		db.Drop()
+		log.Info("Dropped DB", "name", name)
+	}
+	return nil
+}
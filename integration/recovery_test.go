@@ -0,0 +1,187 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip"
+	"github.com/Fantom-foundation/go-opera/integration/makegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
+	"github.com/Fantom-foundation/go-opera/utils"
+	"github.com/Fantom-foundation/go-opera/vecmt"
+)
+
+func TestRecoverDBs(t *testing.T) {
+	require := require.New(t)
+
+	cfg := Configs{
+		Opera:         gossip.DefaultConfig(),
+		OperaStore:    gossip.DefaultStoreConfig(),
+		Lachesis:      abft.DefaultConfig(),
+		LachesisStore: abft.DefaultStoreConfig(),
+		VectorClock:   vecmt.DefaultConfig(),
+	}
+	rawProducer := memorydb.NewProducer("")
+	genStore := makegenesis.FakeGenesisStore(3, utils.ToFtm(1), utils.ToFtm(1))
+	_, _, store, s2, s3, _ := MakeEngine(rawProducer, InputGenesis{
+		Hash: genStore.Hash(),
+		Read: func(store *genesisstore.Store) error {
+			buf := bytes.NewBuffer(nil)
+			err := genStore.Export(buf)
+			if err != nil {
+				return err
+			}
+			return store.Import(buf)
+		},
+		Close: func() error {
+			return nil
+		},
+	}, cfg)
+	epoch := store.GetEpoch()
+	lastBlock := store.GetLatestBlockIndex()
+	require.NoError(store.Commit())
+	store.Close()
+	s2.Close()
+	s3.Close()
+
+	get := func(name string) []byte {
+		db, err := rawProducer.OpenDB(name)
+		require.NoError(err)
+		defer db.Close()
+		marker, err := db.Get(FlushIDKey)
+		require.NoError(err)
+		return marker
+	}
+	put := func(name string, marker []byte) {
+		require.NoError(putFlushID(rawProducer, name, marker))
+	}
+	dirty := func(prev, id []byte) []byte {
+		return append(append([]byte{0xde}, prev...), id...)
+	}
+	requireSynced := func() {
+		dbs, err := makeFlushableProducer(rawProducer)
+		require.NoError(err)
+		require.NoError(dbs.Close())
+	}
+	names := func() map[string]bool {
+		res := map[string]bool{}
+		for _, name := range rawProducer.Names() {
+			res[name] = true
+		}
+		return res
+	}
+
+	// the epoch DBs which exist once the node is started
+	clean := get("gossip")
+	for _, name := range []string{fmt.Sprintf("gossip-%d", epoch), fmt.Sprintf("lachesis-%d", epoch)} {
+		put(name, clean)
+	}
+	all := rawProducer.Names()
+
+	// consistent DBs
+	require.NoError(RecoverDBs(rawProducer, "", cfg))
+	for _, name := range all {
+		require.Equal(clean, get(name), name)
+	}
+
+	// interrupted while marking the DBs as clean
+	flush := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+	for _, name := range all {
+		put(name, cleanFlushMarker(flush))
+	}
+	put("gossip", dirty(clean, flush))
+	require.NoError(RecoverDBs(rawProducer, "", cfg))
+	for _, name := range all {
+		require.Equal(cleanFlushMarker(flush), get(name), name)
+	}
+	requireSynced()
+	clean = cleanFlushMarker(flush)
+
+	// interrupted while marking the DBs as dirty
+	flush = []byte{0, 0, 0, 0, 0, 0, 0, 2}
+	put("lachesis", dirty(clean, flush))
+	put("gossip-100", dirty(initialFlushID, flush))
+	db, err := rawProducer.OpenDB("unflushed")
+	require.NoError(err)
+	require.NoError(db.Put([]byte{1}, []byte{1}))
+	require.NoError(db.Close())
+	require.NoError(RecoverDBs(rawProducer, "", cfg))
+	require.False(names()["gossip-100"])
+	require.False(names()["unflushed"])
+	for _, name := range all {
+		require.Equal(clean, get(name), name)
+	}
+	requireSynced()
+
+	// interrupted while the data were written, the DBs are rolled back
+	gdb, cdb, genesisStore := getStores(&DummyFlushableProducer{rawProducer}, cfg)
+	e := &inter.MutableEventPayload{}
+	e.SetEpoch(epoch)
+	e.SetLamport(1)
+	e.SetCreator(1)
+	event := e.Build()
+	gdb.SetEvent(event)
+	block := *gdb.GetBlock(lastBlock)
+	block.Atropos = event.ID()
+	gdb.SetBlock(lastBlock+1, &block)
+	gdb.SetBlockIndex(block.Atropos, lastBlock+1)
+	gdb.Close()
+	_ = cdb.Close()
+	genesisStore.Close()
+
+	flush = []byte{0, 0, 0, 0, 0, 0, 0, 3}
+	for _, name := range all {
+		put(name, dirty(clean, flush))
+	}
+	journal := path.Join(t.TempDir(), "journal.rlp")
+	require.NoError(RecoverDBs(rawProducer, journal, cfg))
+	requireSynced()
+	for name := range names() {
+		require.False(isEpochDBName(name, "gossip") || isEpochDBName(name, "lachesis"), name)
+	}
+
+	gdb, cdb, genesisStore = getStores(&DummyFlushableProducer{rawProducer}, cfg)
+	require.Equal(epoch, gdb.GetEpoch())
+	require.Equal(lastBlock, gdb.GetLatestBlockIndex())
+	require.Nil(gdb.GetBlock(lastBlock + 1))
+	require.Nil(gdb.GetBlockIndex(event.ID()))
+	require.False(gdb.HasEvent(event.ID()))
+	require.Equal(epoch, cdb.GetEpoch())
+	require.Equal(abft.FirstFrame-1, cdb.GetLastDecidedFrame())
+	gdb.Close()
+	_ = cdb.Close()
+	genesisStore.Close()
+
+	raw, err := ioutil.ReadFile(journal)
+	require.NoError(err)
+	var saved inter.EventPayload
+	require.NoError(rlp.DecodeBytes(raw, &saved))
+	require.Equal(event.ID(), saved.ID())
+
+	// the rollback is repeated, the journal is kept
+	for _, name := range rawProducer.Names() {
+		put(name, dirty(clean, flush))
+	}
+	require.NoError(RecoverDBs(rawProducer, journal, cfg))
+	requireSynced()
+	raw2, err := ioutil.ReadFile(journal)
+	require.NoError(err)
+	require.Equal(raw, raw2)
+
+	// the gossip DB was never flushed, the recovery is impossible
+	db, err = rawProducer.OpenDB("gossip")
+	require.NoError(err)
+	require.NoError(db.Delete(FlushIDKey))
+	require.NoError(db.Close())
+	require.NoError(RecoverDBs(rawProducer, journal, cfg))
+	require.Empty(rawProducer.Names())
+}
@@ -11,7 +11,7 @@ type Block struct {
 	Events      hash.Events
 	Txs         []common.Hash
 	InternalTxs []common.Hash
-	SkippedTxs  []uint32 // indexes of skipped txs, starting from first tx of first event, ending with last tx of last event
+	SkippedTxs  []uint32 // indexes of skipped txs, starting from first of Txs, ending with last tx of last event
 	GasUsed     uint64
 	Root        hash.Hash
 }
@@ -0,0 +1,201 @@
+package dagdump
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// ErrTooManyEvents is returned if the subgraph exceeds the limit of events.
+var ErrTooManyEvents = errors.New("too many events, narrow down the Lamport range or the validators")
+
+// Reader is the events source, it's implemented by gossip.Store.
+type Reader interface {
+	ForEachEpochEvent(epoch idx.Epoch, onEvent func(event *inter.EventPayload) bool)
+	GetEvent(id hash.Event) *inter.Event
+	GetBlockIndex(id hash.Event) *idx.Block
+}
+
+// Filter limits the subgraph by the Lamport range and by the event creators.
+type Filter struct {
+	FromLamport idx.Lamport
+	// ToLamport is the last Lamport time of the subgraph, 0 means no limit
+	ToLamport idx.Lamport
+	// Validators are the creators of the subgraph events, nil means all the validators
+	Validators map[idx.ValidatorID]bool
+}
+
+// Match returns true if the event is accepted by the filter.
+func (f Filter) Match(e inter.EventI) bool {
+	if e.Lamport() < f.FromLamport || (f.ToLamport != 0 && e.Lamport() > f.ToLamport) {
+		return false
+	}
+	return f.Validators == nil || f.Validators[e.Creator()]
+}
+
+// Vertex is an event of the subgraph.
+type Vertex struct {
+	ID      hash.Event
+	Creator idx.ValidatorID
+	Seq     idx.Event
+	Lamport idx.Lamport
+	Frame   idx.Frame
+	// Root is true if the event is the first event of its creator in the frame
+	Root bool
+	// Block is the index of the block decided by the event, nil if the event isn't an Atropos
+	Block   *idx.Block
+	Parents hash.Events
+}
+
+// Collect returns the events of the epoch accepted by the filter, ordered by Lamport time.
+// ErrTooManyEvents is returned if there are more than limit events, 0 means no limit.
+func Collect(r Reader, epoch idx.Epoch, filter Filter, limit int) ([]Vertex, error) {
+	frames := make(map[hash.Event]idx.Frame)
+	frameOf := func(id hash.Event) (idx.Frame, bool) {
+		if frame, ok := frames[id]; ok {
+			return frame, true
+		}
+		if e := r.GetEvent(id); e != nil {
+			return e.Frame(), true
+		}
+		return 0, false
+	}
+
+	var (
+		res []Vertex
+		err error
+	)
+	r.ForEachEpochEvent(epoch, func(e *inter.EventPayload) bool {
+		if filter.ToLamport != 0 && e.Lamport() > filter.ToLamport {
+			// the events are ordered by Lamport time
+			return false
+		}
+		frames[e.ID()] = e.Frame()
+		if !filter.Match(e) {
+			return true
+		}
+		if limit != 0 && len(res) >= limit {
+			err = ErrTooManyEvents
+			return false
+		}
+		root := true
+		if sp := e.SelfParent(); sp != nil {
+			if frame, ok := frameOf(*sp); ok {
+				root = frame < e.Frame()
+			}
+		}
+		res = append(res, Vertex{
+			ID:      e.ID(),
+			Creator: e.Creator(),
+			Seq:     e.Seq(),
+			Lamport: e.Lamport(),
+			Frame:   e.Frame(),
+			Root:    root,
+			Block:   r.GetBlockIndex(e.ID()),
+			Parents: e.Parents(),
+		})
+		return true
+	})
+	return res, err
+}
+
+// WriteDOT writes the subgraph in the Graphviz DOT format.
+// The events are grouped by creators, the roots are boxes and the Atropos events are filled.
+// Only the edges between the subgraph events are written.
+func WriteDOT(w io.Writer, vertices []Vertex) error {
+	byCreator := make(map[idx.ValidatorID][]Vertex)
+	var creators []idx.ValidatorID
+	included := make(map[hash.Event]bool, len(vertices))
+	for _, v := range vertices {
+		if _, ok := byCreator[v.Creator]; !ok {
+			creators = append(creators, v.Creator)
+		}
+		byCreator[v.Creator] = append(byCreator[v.Creator], v)
+		included[v.ID] = true
+	}
+
+	p := &dotPrinter{w: w}
+	p.printf("digraph DAG {\n")
+	p.printf("  rankdir=BT;\n  node [shape=ellipse];\n")
+	for _, creator := range creators {
+		p.printf("  subgraph \"cluster_%d\" {\n    label=\"validator %d\";\n", creator, creator)
+		for _, v := range byCreator[creator] {
+			attrs := fmt.Sprintf("label=\"%s\\nseq=%d lamport=%d frame=%d", v.ID.String(), v.Seq, v.Lamport, v.Frame)
+			if v.Block != nil {
+				attrs += fmt.Sprintf("\\natropos of block %d", *v.Block)
+			}
+			attrs += "\""
+			if v.Root {
+				attrs += ", shape=box"
+			}
+			if v.Block != nil {
+				attrs += ", style=filled, fillcolor=gold"
+			}
+			p.printf("    \"%s\" [%s];\n", v.ID.Hex(), attrs)
+		}
+		p.printf("  }\n")
+	}
+	for _, v := range vertices {
+		for _, parent := range v.Parents {
+			if included[parent] {
+				p.printf("  \"%s\" -> \"%s\";\n", v.ID.Hex(), parent.Hex())
+			}
+		}
+	}
+	p.printf("}\n")
+	return p.err
+}
+
+type dotPrinter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *dotPrinter) printf(format string, args ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+type jsonVertex struct {
+	ID      string     `json:"id"`
+	Creator uint32     `json:"creator"`
+	Seq     uint32     `json:"seq"`
+	Lamport uint32     `json:"lamport"`
+	Frame   uint32     `json:"frame"`
+	Root    bool       `json:"root"`
+	Atropos bool       `json:"atropos"`
+	Block   *idx.Block `json:"block,omitempty"`
+	Parents []string   `json:"parents"`
+}
+
+// WriteJSON writes the subgraph as a JSON array of events.
+func WriteJSON(w io.Writer, vertices []Vertex) error {
+	res := make([]jsonVertex, len(vertices))
+	for i, v := range vertices {
+		parents := make([]string, len(v.Parents))
+		for j, parent := range v.Parents {
+			parents[j] = parent.Hex()
+		}
+		res[i] = jsonVertex{
+			ID:      v.ID.Hex(),
+			Creator: uint32(v.Creator),
+			Seq:     uint32(v.Seq),
+			Lamport: uint32(v.Lamport),
+			Frame:   uint32(v.Frame),
+			Root:    v.Root,
+			Atropos: v.Block != nil,
+			Block:   v.Block,
+			Parents: parents,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
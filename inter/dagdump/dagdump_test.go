@@ -0,0 +1,142 @@
+package dagdump
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+type testReader struct {
+	events []*inter.EventPayload
+	blocks map[hash.Event]idx.Block
+}
+
+func (r *testReader) ForEachEpochEvent(epoch idx.Epoch, onEvent func(event *inter.EventPayload) bool) {
+	for _, e := range r.events {
+		if e.Epoch() == epoch && !onEvent(e) {
+			return
+		}
+	}
+}
+
+func (r *testReader) GetEvent(id hash.Event) *inter.Event {
+	for _, e := range r.events {
+		if e.ID() == id {
+			return &e.Event
+		}
+	}
+	return nil
+}
+
+func (r *testReader) GetBlockIndex(id hash.Event) *idx.Block {
+	if n, ok := r.blocks[id]; ok {
+		return &n
+	}
+	return nil
+}
+
+// newTestDAG builds 2 validators chains of 3 events, every event observes the previous event of the other validator.
+// The events of the last Lamport time are the roots of frame 2, the first one is an Atropos.
+func newTestDAG() *testReader {
+	r := &testReader{blocks: make(map[hash.Event]idx.Block)}
+	last := make(map[idx.ValidatorID]*inter.EventPayload)
+	for lamport := idx.Lamport(1); lamport <= 3; lamport++ {
+		prev := make(map[idx.ValidatorID]*inter.EventPayload)
+		for v, e := range last {
+			prev[v] = e
+		}
+		for creator := idx.ValidatorID(1); creator <= 2; creator++ {
+			me := &inter.MutableEventPayload{}
+			me.SetEpoch(1)
+			me.SetCreator(creator)
+			me.SetSeq(idx.Event(lamport))
+			me.SetLamport(lamport)
+			me.SetFrame(1)
+			if lamport == 3 {
+				me.SetFrame(2)
+			}
+			var parents hash.Events
+			if sp := prev[creator]; sp != nil {
+				parents = append(parents, sp.ID())
+			}
+			if other := prev[3-creator]; other != nil {
+				parents = append(parents, other.ID())
+			}
+			me.SetParents(parents)
+			e := me.Build()
+			r.events = append(r.events, e)
+			last[creator] = e
+		}
+	}
+	r.blocks[r.events[4].ID()] = 7
+	return r
+}
+
+func TestCollect(t *testing.T) {
+	require := require.New(t)
+	r := newTestDAG()
+
+	all, err := Collect(r, 1, Filter{}, 0)
+	require.NoError(err)
+	require.Len(all, 6)
+	for i, v := range all {
+		e := r.events[i]
+		require.Equal(e.ID(), v.ID)
+		require.Equal(e.Creator(), v.Creator)
+		require.Equal(e.Lamport(), v.Lamport)
+		require.Equal(e.Parents(), v.Parents)
+		require.Equal(e.Lamport() != 2, v.Root, i)
+		require.Equal(i == 4, v.Block != nil, i)
+	}
+	require.Equal(idx.Block(7), *all[4].Block)
+
+	// Lamport range and validators
+	vv, err := Collect(r, 1, Filter{FromLamport: 2, ToLamport: 3, Validators: map[idx.ValidatorID]bool{2: true}}, 0)
+	require.NoError(err)
+	require.Len(vv, 2)
+	require.Equal(r.events[3].ID(), vv[0].ID)
+	require.Equal(r.events[5].ID(), vv[1].ID)
+	// the root marker is kept even if the self-parent isn't in the range
+	vv, err = Collect(r, 1, Filter{FromLamport: 3}, 0)
+	require.NoError(err)
+	require.Len(vv, 2)
+	require.True(vv[0].Root)
+
+	_, err = Collect(r, 1, Filter{}, 5)
+	require.Equal(ErrTooManyEvents, err)
+	vv, err = Collect(r, 2, Filter{}, 0)
+	require.NoError(err)
+	require.Empty(vv)
+}
+
+func TestWrite(t *testing.T) {
+	require := require.New(t)
+	r := newTestDAG()
+	vv, err := Collect(r, 1, Filter{FromLamport: 2}, 0)
+	require.NoError(err)
+
+	dot := &bytes.Buffer{}
+	require.NoError(WriteDOT(dot, vv))
+	require.True(strings.HasPrefix(dot.String(), "digraph DAG {"))
+	require.Equal(2, strings.Count(dot.String(), "subgraph"))
+	// the edges to the events of Lamport time 1 are omitted
+	require.Equal(4, strings.Count(dot.String(), " -> "))
+	require.Equal(1, strings.Count(dot.String(), "fillcolor"))
+
+	raw := &bytes.Buffer{}
+	require.NoError(WriteJSON(raw, vv))
+	var decoded []jsonVertex
+	require.NoError(json.Unmarshal(raw.Bytes(), &decoded))
+	require.Len(decoded, 4)
+	require.Equal(vv[2].ID.Hex(), decoded[2].ID)
+	require.True(decoded[2].Atropos)
+	require.Equal(idx.Block(7), *decoded[2].Block)
+	require.Len(decoded[0].Parents, 2)
+}
@@ -0,0 +1,37 @@
+package inter
+
+import (
+	"math/big"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+)
+
+// ValidatorEpochStats is the participation of a validator in a sealed epoch.
+type ValidatorEpochStats struct {
+	ID     idx.ValidatorID
+	Weight *big.Int
+	// Uptime and MissedBlocks are the metrics sent to the SFC contract, the downtime below BlockMissedSlack is forgiven
+	Uptime       Timestamp
+	MissedBlocks idx.Block
+	MissedPeriod Timestamp
+	// OriginatedFee is the fee of the transactions originated by the validator during the epoch
+	OriginatedFee *big.Int
+}
+
+// EpochStats is the summary of a sealed epoch.
+type EpochStats struct {
+	Epoch      idx.Epoch
+	Start      Timestamp
+	End        Timestamp
+	FirstBlock idx.Block
+	LastBlock  idx.Block
+	GasUsed    uint64
+	TxCount    uint64
+	TotalFee   *big.Int
+	Validators []ValidatorEpochStats
+}
+
+// Duration returns the duration of the epoch.
+func (s *EpochStats) Duration() Timestamp {
+	return s.End - s.Start
+}
@@ -0,0 +1,182 @@
+package topicsdb
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// keysChunk is the number of keys which are collected before they are deleted.
+const keysChunk = 10000
+
+// DeleteInBlocks deletes the log records of the blocks range along with their topic keys.
+// The topic keys are ordered by topics, so the whole topics table is scanned.
+func (tt *Index) DeleteInBlocks(from, to idx.Block) error {
+	inRange := func(key []byte) bool {
+		n := idx.Block(bytesToUint(key[:uint64Size]))
+		return n >= from && n <= to
+	}
+	err := deleteKeys(tt.table.Topic, nil, func(key []byte) (del bool, gonext bool) {
+		return len(key) == topicKeySize && inRange(key[hashSize+uint8Size:]), true
+	})
+	if err != nil {
+		return err
+	}
+	return deleteKeys(tt.table.Logrec, uintToBytes(uint64(from)), func(key []byte) (del bool, gonext bool) {
+		return inRange(key), inRange(key)
+	})
+}
+
+// deleteKeys deletes the table keys starting from start, which are selected by the filter.
+// The keys are deleted in chunks, the iteration is restarted after each chunk.
+func deleteKeys(t kvdb.Store, start []byte, filter func(key []byte) (del bool, gonext bool)) error {
+	for {
+		keys := make([][]byte, 0, keysChunk)
+		done := true
+		it := t.NewIterator(nil, start)
+		for it.Next() {
+			del, gonext := filter(it.Key())
+			if !gonext {
+				break
+			}
+			if !del {
+				continue
+			}
+			keys = append(keys, common.CopyBytes(it.Key()))
+			if len(keys) >= keysChunk {
+				done = false
+				break
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := t.Delete(key); err != nil {
+				return err
+			}
+		}
+		if done {
+			return nil
+		}
+		start = keys[len(keys)-1]
+	}
+}
+
+// recordTopics returns the address and topics of the log record with the topics count.
+func recordTopics(buf []byte, topicsCount uint8) (address common.Address, topics []common.Hash, err error) {
+	if len(buf) < int(topicsCount)*common.HashLength+common.HashLength+common.AddressLength {
+		return address, nil, fmt.Errorf("record of %d bytes is too short for %d topics", len(buf), topicsCount)
+	}
+	topics = make([]common.Hash, topicsCount)
+	offset := 0
+	for i := range topics {
+		topics[i] = common.BytesToHash(buf[offset : offset+common.HashLength])
+		offset += common.HashLength
+	}
+	offset += common.HashLength
+	address = common.BytesToAddress(buf[offset : offset+common.AddressLength])
+	return address, topics, nil
+}
+
+// CheckRecords checks that the log records of the blocks range are indexed by their address and topics.
+// onIssue is called for every inconsistency, the check stops if it returns false.
+func (tt *Index) CheckRecords(from, to idx.Block, onIssue func(id ID, issue string) (gonext bool)) error {
+	it := tt.table.Logrec.NewIterator(nil, uintToBytes(uint64(from)))
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) != logrecKeySize {
+			continue
+		}
+		var id ID
+		copy(id[:], it.Key())
+		if id.BlockNumber() > uint64(to) {
+			break
+		}
+		issue, err := tt.checkRecord(id, it.Value())
+		if err != nil {
+			return err
+		}
+		if issue != "" && !onIssue(id, issue) {
+			return nil
+		}
+	}
+	return it.Error()
+}
+
+// checkRecord finds the topics count of the record by its address key, and checks the topic keys.
+func (tt *Index) checkRecord(id ID, buf []byte) (issue string, err error) {
+	for count := 0; count <= MaxTopicsCount; count++ {
+		address, topics, err := recordTopics(buf, uint8(count))
+		if err != nil {
+			break
+		}
+		val, err := tt.table.Topic.Get(topicKey(address.Hash(), 0, id))
+		if err != nil {
+			return "", err
+		}
+		if val == nil || bytesToPos(val) != uint8(count) {
+			continue
+		}
+		for pos, topic := range topics {
+			val, err := tt.table.Topic.Get(topicKey(topic, uint8(pos+1), id))
+			if err != nil {
+				return "", err
+			}
+			if val == nil {
+				return fmt.Sprintf("topic %d isn't indexed", pos), nil
+			}
+			if bytesToPos(val) != uint8(count) {
+				return fmt.Sprintf("topic %d is indexed with %d topics instead of %d", pos, bytesToPos(val), count), nil
+			}
+		}
+		return "", nil
+	}
+	return "address isn't indexed", nil
+}
+
+// CheckTopics checks that the topic keys of the blocks range refer to the log records with the same topics.
+// The topic keys are ordered by topics, so the whole topics table is scanned.
+// onIssue is called for every inconsistency, the check stops if it returns false.
+func (tt *Index) CheckTopics(from, to idx.Block, onIssue func(id ID, issue string) (gonext bool)) error {
+	it := tt.table.Topic.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != topicKeySize {
+			continue
+		}
+		id := extractLogrecID(key)
+		if id.BlockNumber() < uint64(from) || id.BlockNumber() > uint64(to) {
+			continue
+		}
+		topic := common.BytesToHash(key[:hashSize])
+		pos := bytesToPos(key[hashSize:])
+		count := bytesToPos(it.Value())
+
+		buf, err := tt.table.Logrec.Get(id.Bytes())
+		if err != nil {
+			return err
+		}
+		var issue string
+		if buf == nil {
+			issue = fmt.Sprintf("topic %d refers to a missing record", int(pos)-1)
+		} else if address, topics, err := recordTopics(buf, count); err != nil {
+			issue = err.Error()
+		} else if pos > count {
+			issue = fmt.Sprintf("topic %d is out of %d topics", int(pos)-1, count)
+		} else if pos == 0 && address.Hash() != topic {
+			issue = "address mismatches the record"
+		} else if pos != 0 && topics[pos-1] != topic {
+			issue = fmt.Sprintf("topic %d mismatches the record", pos-1)
+		}
+		if issue != "" && !onIssue(id, issue) {
+			return nil
+		}
+	}
+	return it.Error()
+}
@@ -0,0 +1,69 @@
+package topicsdb
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+)
+
+func TestIndexMaintenance(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	var (
+		hash1 = common.BytesToHash([]byte("topic1"))
+		hash2 = common.BytesToHash([]byte("topic2"))
+		addr  = randAddress()
+	)
+	index := New(memorydb.New())
+	for n := uint64(1); n <= 5; n++ {
+		require.NoError(index.Push(&types.Log{
+			BlockNumber: n,
+			Address:     addr,
+			Topics:      []common.Hash{hash1, hash2},
+			Data:        make([]byte, 100),
+		}, &types.Log{
+			BlockNumber: n,
+			Index:       1,
+			Address:     addr,
+		}))
+	}
+
+	issues := func() map[uint64][]string {
+		res := map[uint64][]string{}
+		onIssue := func(id ID, issue string) bool {
+			res[id.BlockNumber()] = append(res[id.BlockNumber()], issue)
+			return true
+		}
+		require.NoError(index.CheckRecords(0, 5, onIssue))
+		require.NoError(index.CheckTopics(0, 5, onIssue))
+		return res
+	}
+	require.Empty(issues())
+
+	// missing and mismatched topic keys
+	id := NewID(2, common.Hash{}, 0)
+	require.NoError(index.table.Topic.Delete(topicKey(hash2, 2, id)))
+	require.NoError(index.table.Topic.Put(topicKey(hash1, 2, NewID(3, common.Hash{}, 0)), posToBytes(2)))
+	require.NoError(index.table.Topic.Put(topicKey(hash1, 1, NewID(4, common.Hash{}, 7)), posToBytes(1)))
+	require.Equal(map[uint64][]string{
+		2: {"topic 1 isn't indexed"},
+		3: {"topic 1 mismatches the record"},
+		4: {"topic 0 refers to a missing record"},
+	}, issues())
+
+	// the blocks range is deleted along with the inconsistent keys
+	require.NoError(index.DeleteInBlocks(2, 4))
+	require.Empty(issues())
+	var blocks []uint64
+	require.NoError(index.ForEachInBlocks(0, 5, [][]common.Hash{{addr.Hash()}}, func(l *types.Log) bool {
+		blocks = append(blocks, l.BlockNumber)
+		return true
+	}))
+	require.Equal([]uint64{1, 1, 5, 5}, blocks)
+}
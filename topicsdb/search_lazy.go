@@ -1,6 +1,10 @@
 package topicsdb
 
 import (
+	"bytes"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
@@ -69,3 +73,59 @@ func (tt *Index) walk(
 
 	return
 }
+
+// fetchOrdered merges the records of the first non-empty topic variants in the order of their IDs,
+// and walks for the rest of topics.
+func (tt *Index) fetchOrdered(topics [][]common.Hash, from ID, to idx.Block, onLog func(*types.Log) bool) (err error) {
+	pos := uint8(0)
+	for len(topics[pos]) < 1 {
+		pos++
+	}
+
+	its := make([]kvdb.Iterator, 0, len(topics[pos]))
+	heads := make([]*ID, 0, len(topics[pos]))
+	defer func() {
+		for _, it := range its {
+			it.Release()
+		}
+	}()
+	next := func(i int) {
+		heads[i] = nil
+		if its[i].Next() {
+			id := extractLogrecID(its[i].Key())
+			heads[i] = &id
+		}
+	}
+	for _, variant := range topics[pos] {
+		prefix := append(variant.Bytes(), posToBytes(pos)...)
+		its = append(its, tt.table.Topic.NewIterator(prefix, from.Bytes()))
+		heads = append(heads, nil)
+		next(len(its) - 1)
+	}
+
+	for {
+		min := -1
+		for i, head := range heads {
+			if head != nil && (min < 0 || bytes.Compare(head.Bytes(), heads[min].Bytes()) < 0) {
+				min = i
+			}
+		}
+		if min < 0 || heads[min].BlockNumber() > uint64(to) {
+			return
+		}
+		id := *heads[min]
+		topicCount := bytesToPos(its[min].Value())
+		// skip the duplicated variants
+		for i, head := range heads {
+			if head != nil && *head == id {
+				next(i)
+			}
+		}
+
+		var gonext bool
+		gonext, err = tt.walk(newLogrec(id, topicCount), nil, topics, pos+1, onLog)
+		if err != nil || !gonext {
+			return
+		}
+	}
+}
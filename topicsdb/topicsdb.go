@@ -49,9 +49,15 @@ func (tt *Index) ForEach(topics [][]common.Hash, onLog func(*types.Log) (gonext
 	return tt.fetchLazy(topics, nil, onLog)
 }
 
-// ForEachInBlocks matches log records of block range by topics. 1st topics element is an address.
+// ForEachInBlocks matches log records of block range by topics in the order of their IDs. 1st topics element is an address.
 func (tt *Index) ForEachInBlocks(from, to idx.Block, topics [][]common.Hash, onLog func(*types.Log) (gonext bool)) error {
-	if from > to {
+	return tt.ForEachFrom(NewID(uint64(from), common.Hash{}, 0), to, topics, onLog)
+}
+
+// ForEachFrom matches log records by topics in the order of their IDs, starting from the ID and up to the block.
+// 1st topics element is an address.
+func (tt *Index) ForEachFrom(from ID, to idx.Block, topics [][]common.Hash, onLog func(*types.Log) (gonext bool)) error {
+	if from.BlockNumber() > uint64(to) {
 		return nil
 	}
 
@@ -59,14 +65,7 @@ func (tt *Index) ForEachInBlocks(from, to idx.Block, topics [][]common.Hash, onL
 		return err
 	}
 
-	moreAccurate := func(l *types.Log) (gonext bool) {
-		if l.BlockNumber > uint64(to) {
-			return false
-		}
-		return onLog(l)
-	}
-
-	return tt.fetchLazy(topics, uintToBytes(uint64(from)), moreAccurate)
+	return tt.fetchOrdered(topics, from, to, onLog)
 }
 
 func checkTopics(topics [][]common.Hash) error {
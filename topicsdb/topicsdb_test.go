@@ -127,6 +127,35 @@ func TestIndexSearchMultyVariants(t *testing.T) {
 		require.Equal(2, len(got))
 		check(require, got)
 	})
+
+	t.Run("Ordered from ID", func(t *testing.T) {
+		require := require.New(t)
+		pattern := [][]common.Hash{
+			{addr4.Hash(), addr3.Hash(), addr2.Hash(), addr1.Hash()},
+			{hash4, hash3, hash2, hash1},
+		}
+		var blocks []uint64
+		err := index.ForEachFrom(NewID(2, common.Hash{}, 0), 999, pattern, func(item *types.Log) bool {
+			blocks = append(blocks, item.BlockNumber)
+			return true
+		})
+		require.NoError(err)
+		require.Equal([]uint64{3, 998, 999}, blocks)
+
+		blocks = nil
+		err = index.ForEachFrom(NewID(998, common.Hash{}, 1), 999, pattern, func(item *types.Log) bool {
+			blocks = append(blocks, item.BlockNumber)
+			return true
+		})
+		require.NoError(err)
+		require.Equal([]uint64{999}, blocks)
+
+		// the variants beyond the range don't stop the others
+		got, err := index.FindInBlocks(2, 998, pattern)
+		require.NoError(err)
+		require.Equal(2, len(got))
+		check(require, got)
+	})
 }
 
 func TestIndexSearchSingleVariant(t *testing.T) {
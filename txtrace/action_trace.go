@@ -0,0 +1,168 @@
+package txtrace
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Trace types
+const (
+	CallType    = "call"
+	CreateType  = "create"
+	SuicideType = "suicide"
+)
+
+// ActionTrace is a Parity-style flat trace of a single call.
+type ActionTrace struct {
+	Action              Action         `json:"action"`
+	BlockHash           common.Hash    `json:"blockHash"`
+	BlockNumber         hexutil.Uint64 `json:"blockNumber"`
+	Error               string         `json:"error,omitempty"`
+	Result              *Result        `json:"result"`
+	Subtraces           int            `json:"subtraces"`
+	TraceAddress        []int          `json:"traceAddress"`
+	TransactionHash     common.Hash    `json:"transactionHash"`
+	TransactionPosition hexutil.Uint64 `json:"transactionPosition"`
+	Type                string         `json:"type"`
+	// Internal is true for traces of internal (unsigned) transactions,
+	// which are generated by the node itself, e.g. calls of the driver and SFC contracts
+	Internal bool `json:"internal,omitempty"`
+}
+
+// Action is a Parity-style description of a call.
+type Action struct {
+	CallType      string          `json:"callType,omitempty"`
+	From          *common.Address `json:"from,omitempty"`
+	To            *common.Address `json:"to,omitempty"`
+	Value         *hexutil.Big    `json:"value,omitempty"`
+	Gas           *hexutil.Uint64 `json:"gas,omitempty"`
+	Input         *hexutil.Bytes  `json:"input,omitempty"`
+	Init          *hexutil.Bytes  `json:"init,omitempty"`
+	Address       *common.Address `json:"address,omitempty"`
+	RefundAddress *common.Address `json:"refundAddress,omitempty"`
+	Balance       *hexutil.Big    `json:"balance,omitempty"`
+}
+
+// Result is a Parity-style result of a call.
+type Result struct {
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+}
+
+// TxContext is a position of a traced transaction in the chain.
+type TxContext struct {
+	BlockHash   common.Hash
+	BlockNumber uint64
+	TxHash      common.Hash
+	TxPosition  uint64
+	Internal    bool
+}
+
+// Sender returns the address which has performed the call.
+func (t *ActionTrace) Sender() common.Address {
+	if t.Action.From != nil {
+		return *t.Action.From
+	}
+	if t.Action.Address != nil {
+		return *t.Action.Address
+	}
+	return common.Address{}
+}
+
+// Recipient returns the address which has received the call.
+func (t *ActionTrace) Recipient() common.Address {
+	switch {
+	case t.Action.To != nil:
+		return *t.Action.To
+	case t.Action.RefundAddress != nil:
+		return *t.Action.RefundAddress
+	case t.Result != nil && t.Result.Address != nil:
+		return *t.Result.Address
+	}
+	return common.Address{}
+}
+
+// Flatten converts the tree of calls into the list of Parity-style traces in the depth-first order.
+func Flatten(root *Call, ctx TxContext) []ActionTrace {
+	traces := make([]ActionTrace, 0, 1)
+	flatten(root, ctx, []int{}, &traces)
+	return traces
+}
+
+func flatten(call *Call, ctx TxContext, traceAddress []int, traces *[]ActionTrace) {
+	trace := ActionTrace{
+		BlockHash:           ctx.BlockHash,
+		BlockNumber:         hexutil.Uint64(ctx.BlockNumber),
+		Subtraces:           len(call.Calls),
+		TraceAddress:        traceAddress,
+		TransactionHash:     ctx.TxHash,
+		TransactionPosition: hexutil.Uint64(ctx.TxPosition),
+		Internal:            ctx.Internal,
+	}
+	from, to := call.From, call.To
+	value := call.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	gas := hexutil.Uint64(call.Gas)
+	input := hexutil.Bytes(call.Input)
+	output := hexutil.Bytes(call.Output)
+
+	switch call.Type {
+	case vm.CREATE, vm.CREATE2:
+		trace.Type = CreateType
+		trace.Action = Action{
+			From:  &from,
+			Value: (*hexutil.Big)(value),
+			Gas:   &gas,
+			Init:  &input,
+		}
+		trace.Result = &Result{
+			GasUsed: hexutil.Uint64(call.GasUsed),
+			Address: &to,
+			Code:    &output,
+		}
+	case vm.SELFDESTRUCT:
+		trace.Type = SuicideType
+		trace.Action = Action{
+			Address:       &from,
+			RefundAddress: &to,
+			Balance:       (*hexutil.Big)(value),
+		}
+	default:
+		trace.Type = CallType
+		trace.Action = Action{
+			CallType: strings.ToLower(call.Type.String()),
+			From:     &from,
+			To:       &to,
+			Value:    (*hexutil.Big)(value),
+			Gas:      &gas,
+			Input:    &input,
+		}
+		trace.Result = &Result{
+			GasUsed: hexutil.Uint64(call.GasUsed),
+			Output:  &output,
+		}
+	}
+	if call.Error != "" {
+		trace.Error = call.Error
+		if call.Error == vm.ErrExecutionReverted.Error() {
+			trace.Error = "Reverted"
+		}
+		trace.Result = nil
+	}
+	*traces = append(*traces, trace)
+
+	for i, sub := range call.Calls {
+		subAddress := make([]int, len(traceAddress)+1)
+		copy(subAddress, traceAddress)
+		subAddress[len(traceAddress)] = i
+		flatten(sub, ctx, subAddress, traces)
+	}
+}
@@ -0,0 +1,90 @@
+package txtrace
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// AddressesTracer collects senders and recipients of all the calls performed during transactions execution.
+// It's a lightweight tracer which is intended for indexing, it may be used for multiple transactions.
+type AddressesTracer struct {
+	addresses map[common.Address]struct{}
+	// creates holds depths of the pending contract creations
+	creates []int
+}
+
+// NewAddressesTracer creates a tracer which collects addresses of calls.
+func NewAddressesTracer() *AddressesTracer {
+	return &AddressesTracer{
+		addresses: make(map[common.Address]struct{}),
+	}
+}
+
+// Addresses returns the collected addresses.
+func (t *AddressesTracer) Addresses() []common.Address {
+	addresses := make([]common.Address, 0, len(t.addresses))
+	for addr := range t.addresses {
+		addresses = append(addresses, addr)
+	}
+	return addresses
+}
+
+func (t *AddressesTracer) add(addr common.Address) {
+	t.addresses[addr] = struct{}{}
+}
+
+// CaptureStart implements vm.Tracer.
+func (t *AddressesTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.add(from)
+	t.add(to)
+	t.creates = t.creates[:0]
+	return nil
+}
+
+// CaptureState implements vm.Tracer.
+func (t *AddressesTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	if err != nil {
+		// the current call is failed, so the pending creations of this call won't be finished
+		for len(t.creates) != 0 && t.creates[len(t.creates)-1] >= depth {
+			t.creates = t.creates[:len(t.creates)-1]
+		}
+		return nil
+	}
+	// address of a created contract is on the stack after the creation is finished
+	if len(t.creates) != 0 && t.creates[len(t.creates)-1] == depth && op != vm.CREATE && op != vm.CREATE2 {
+		t.creates = t.creates[:len(t.creates)-1]
+		if ret := stack.Back(0); !ret.IsZero() {
+			t.add(common.Address(ret.Bytes20()))
+		}
+	}
+
+	switch op {
+	case vm.CREATE, vm.CREATE2:
+		t.add(contract.Address())
+		t.creates = append(t.creates, depth)
+	case vm.SELFDESTRUCT:
+		t.add(contract.Address())
+		t.add(common.Address(stack.Back(0).Bytes20()))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		to := common.Address(stack.Back(1).Bytes20())
+		if _, ok := vm.PrecompiledContractsIstanbul[to]; ok {
+			return nil
+		}
+		t.add(contract.Address())
+		t.add(to)
+	}
+	return nil
+}
+
+// CaptureFault implements vm.Tracer.
+func (t *AddressesTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer.
+func (t *AddressesTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) error {
+	return nil
+}
@@ -0,0 +1,213 @@
+package txtrace
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Call is a message call or a contract creation performed during a transaction execution.
+type Call struct {
+	Type    vm.OpCode
+	From    common.Address
+	To      common.Address
+	Value   *big.Int
+	Gas     uint64
+	GasUsed uint64
+	Input   []byte
+	Output  []byte
+	Error   string
+	Calls   []*Call
+
+	gasKnown bool
+	gasIn    uint64
+	gasCost  uint64
+	outOff   uint64
+	outLen   uint64
+}
+
+// CallTracer collects the tree of calls performed during a transaction execution.
+// It's a port of the go-ethereum JavaScript callTracer.
+type CallTracer struct {
+	callstack []*Call
+	// descended tracks whether we've just descended from an outer call into an inner call
+	descended bool
+}
+
+// NewCallTracer creates a tracer which collects calls of a single transaction.
+func NewCallTracer() *CallTracer {
+	return &CallTracer{
+		callstack: []*Call{{}},
+	}
+}
+
+// Root returns the top-level call of the transaction.
+func (t *CallTracer) Root() *Call {
+	return t.callstack[0]
+}
+
+// CaptureStart implements vm.Tracer.
+func (t *CallTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	root := t.callstack[0]
+	root.Type = vm.CALL
+	if create {
+		root.Type = vm.CREATE
+	}
+	root.From = from
+	root.To = to
+	root.Input = common.CopyBytes(input)
+	root.Gas = gas
+	root.gasKnown = true
+	root.Value = new(big.Int).Set(value)
+	return nil
+}
+
+// CaptureState implements vm.Tracer.
+func (t *CallTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	if err != nil {
+		t.fault(err)
+		return nil
+	}
+
+	switch op {
+	case vm.CREATE, vm.CREATE2:
+		inOff, inLen := stack.Back(1).Uint64(), stack.Back(2).Uint64()
+		t.push(&Call{
+			Type:    op,
+			From:    contract.Address(),
+			Input:   memory.GetCopy(int64(inOff), int64(inLen)),
+			Value:   stack.Back(0).ToBig(),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		return nil
+
+	case vm.SELFDESTRUCT:
+		t.top().Calls = append(t.top().Calls, &Call{
+			Type:  op,
+			From:  contract.Address(),
+			To:    common.Address(stack.Back(0).Bytes20()),
+			Value: new(big.Int).Set(env.StateDB.GetBalance(contract.Address())),
+		})
+		return nil
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		to := common.Address(stack.Back(1).Bytes20())
+		// skip pre-compile invocations, those are just fancy opcodes
+		if _, ok := vm.PrecompiledContractsIstanbul[to]; ok {
+			return nil
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		inOff, inLen := stack.Back(2+off).Uint64(), stack.Back(3+off).Uint64()
+		call := &Call{
+			Type:    op,
+			From:    contract.Address(),
+			To:      to,
+			Input:   memory.GetCopy(int64(inOff), int64(inLen)),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  stack.Back(4 + off).Uint64(),
+			outLen:  stack.Back(5 + off).Uint64(),
+		}
+		if off != 0 {
+			call.Value = stack.Back(2).ToBig()
+		}
+		t.push(call)
+		return nil
+	}
+
+	// if we've just descended into an inner call, retrieve its true gas allowance
+	if t.descended {
+		if depth >= len(t.callstack) {
+			t.top().Gas = gas
+			t.top().gasKnown = true
+		}
+		t.descended = false
+	}
+	if op == vm.REVERT {
+		t.top().Error = vm.ErrExecutionReverted.Error()
+		return nil
+	}
+	// if an existing call is returning, pop it off the call stack
+	if depth == len(t.callstack)-1 {
+		call := t.pop()
+		ret := stack.Back(0)
+		if call.Type == vm.CREATE || call.Type == vm.CREATE2 {
+			call.GasUsed = call.gasIn - call.gasCost - gas
+			if !ret.IsZero() {
+				call.To = common.Address(ret.Bytes20())
+				call.Output = env.StateDB.GetCode(call.To)
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		} else {
+			if call.gasKnown {
+				call.GasUsed = call.gasIn - call.gasCost + call.Gas - gas
+			}
+			if !ret.IsZero() {
+				call.Output = memory.GetCopy(int64(call.outOff), int64(call.outLen))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		t.top().Calls = append(t.top().Calls, call)
+	}
+	return nil
+}
+
+// CaptureFault implements vm.Tracer.
+func (t *CallTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	t.fault(err)
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer.
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) error {
+	root := t.callstack[0]
+	root.Output = common.CopyBytes(output)
+	root.GasUsed = gasUsed
+	if err != nil && root.Error == "" {
+		root.Error = err.Error()
+	}
+	return nil
+}
+
+// fault is invoked when the actual execution of an opcode fails.
+func (t *CallTracer) fault(err error) {
+	// if the topmost call already reverted, don't handle the additional fault again
+	if t.top().Error != "" {
+		return
+	}
+	call := t.pop()
+	call.Error = err.Error()
+	// consume all available gas
+	if call.gasKnown {
+		call.GasUsed = call.Gas
+	}
+	if len(t.callstack) == 0 {
+		// the top-level call failed, leave it in the stack
+		t.callstack = append(t.callstack, call)
+		return
+	}
+	t.top().Calls = append(t.top().Calls, call)
+}
+
+func (t *CallTracer) push(call *Call) {
+	t.callstack = append(t.callstack, call)
+	t.descended = true
+}
+
+func (t *CallTracer) pop() *Call {
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	return call
+}
+
+func (t *CallTracer) top() *Call {
+	return t.callstack[len(t.callstack)-1]
+}
@@ -0,0 +1,61 @@
+package txtrace
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTracer(t *testing.T) {
+	require := require.New(t)
+
+	callee := common.HexToAddress("0x00000000000000000000000000000000000000ff")
+	code := []byte{
+		byte(vm.PUSH1), 0, // outLen
+		byte(vm.PUSH1), 0, // outOff
+		byte(vm.PUSH1), 0, // inLen
+		byte(vm.PUSH1), 0, // inOff
+		byte(vm.PUSH1), 0, // value
+		byte(vm.PUSH20),
+	}
+	code = append(code, callee.Bytes()...)
+	code = append(code,
+		byte(vm.GAS),
+		byte(vm.CALL),
+		byte(vm.STOP),
+	)
+
+	tracer := NewCallTracer()
+	addresses := NewAddressesTracer()
+	for _, tr := range []vm.Tracer{tracer, addresses} {
+		_, _, err := runtime.Execute(code, nil, &runtime.Config{
+			EVMConfig: vm.Config{
+				Debug:  true,
+				Tracer: tr,
+			},
+		})
+		require.NoError(err)
+	}
+
+	traces := Flatten(tracer.Root(), TxContext{TxPosition: 1})
+	require.Len(traces, 2)
+
+	require.Equal(CallType, traces[0].Type)
+	require.Equal("call", traces[0].Action.CallType)
+	require.Equal(1, traces[0].Subtraces)
+	require.Empty(traces[0].TraceAddress)
+	require.Equal(common.BytesToAddress([]byte("contract")), traces[0].Recipient())
+
+	require.Equal(CallType, traces[1].Type)
+	require.Equal([]int{0}, traces[1].TraceAddress)
+	require.Equal(common.BytesToAddress([]byte("contract")), traces[1].Sender())
+	require.Equal(callee, traces[1].Recipient())
+	require.Empty(traces[1].Error)
+	require.EqualValues(1, traces[1].TransactionPosition)
+
+	require.Contains(addresses.Addresses(), callee)
+	require.Contains(addresses.Addresses(), common.BytesToAddress([]byte("contract")))
+}
@@ -0,0 +1,174 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Checkpointer saves the tables affected by a migration step, so they may be restored if the step fails.
+type Checkpointer interface {
+	// Tables returns all the tables which may be checkpointed
+	Tables() []string
+	Save(tables []string) error
+	Restore(tables []string) error
+	Discard(tables []string) error
+}
+
+// KvdbCheckpointer copies the tables into the backup table.
+// A complete checkpoint which is left by an interrupted step is restored instead of saving a new one.
+type KvdbCheckpointer struct {
+	backup kvdb.Store
+	tables map[string]kvdb.Store
+}
+
+// NewKvdbCheckpointer constructor
+func NewKvdbCheckpointer(backup kvdb.Store, tables map[string]kvdb.Store) *KvdbCheckpointer {
+	return &KvdbCheckpointer{
+		backup: backup,
+		tables: tables,
+	}
+}
+
+func (c *KvdbCheckpointer) dataPrefix(name string) []byte {
+	return append([]byte(name), 0)
+}
+
+func (c *KvdbCheckpointer) completeKey(name string) []byte {
+	return append([]byte(name), 1)
+}
+
+func (c *KvdbCheckpointer) table(name string) (kvdb.Store, error) {
+	t := c.tables[name]
+	if t == nil {
+		return nil, errors.New("unknown table " + name)
+	}
+	return t, nil
+}
+
+// Tables returns the names of all the tables in order
+func (c *KvdbCheckpointer) Tables() []string {
+	names := make([]string, 0, len(c.tables))
+	for name := range c.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save copies the tables into the backup table
+func (c *KvdbCheckpointer) Save(tables []string) error {
+	for _, name := range tables {
+		t, err := c.table(name)
+		if err != nil {
+			return err
+		}
+		complete, err := c.backup.Has(c.completeKey(name))
+		if err != nil {
+			return err
+		}
+		if complete {
+			// the previous attempt of the step was interrupted
+			err = c.restore(name, t)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err = deleteAll(c.backup, c.dataPrefix(name))
+		if err != nil {
+			return err
+		}
+		err = copyAll(c.backup, t, nil, c.dataPrefix(name))
+		if err != nil {
+			return err
+		}
+		err = c.backup.Put(c.completeKey(name), []byte{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore replaces the tables with the saved ones
+func (c *KvdbCheckpointer) Restore(tables []string) error {
+	for _, name := range tables {
+		t, err := c.table(name)
+		if err != nil {
+			return err
+		}
+		complete, err := c.backup.Has(c.completeKey(name))
+		if err != nil {
+			return err
+		}
+		if !complete {
+			return fmt.Errorf("checkpoint of table %s isn't found", name)
+		}
+		err = c.restore(name, t)
+		if err != nil {
+			return err
+		}
+	}
+	return c.Discard(tables)
+}
+
+func (c *KvdbCheckpointer) restore(name string, t kvdb.Store) error {
+	err := deleteAll(t, nil)
+	if err != nil {
+		return err
+	}
+	return copyAll(t, c.backup, c.dataPrefix(name), nil)
+}
+
+// Discard erases the saved tables
+func (c *KvdbCheckpointer) Discard(tables []string) error {
+	for _, name := range tables {
+		err := c.backup.Delete(c.completeKey(name))
+		if err != nil {
+			return err
+		}
+		err = deleteAll(c.backup, c.dataPrefix(name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyAll copies the records with the prefix from src into dst, the prefix is replaced with the dstPrefix.
+func copyAll(dst kvdb.Store, src kvdb.Iteratee, prefix, dstPrefix []byte) error {
+	it := src.NewIterator(prefix, nil)
+	defer it.Release()
+	for it.Next() {
+		key := append(common.CopyBytes(dstPrefix), it.Key()[len(prefix):]...)
+		err := dst.Put(key, common.CopyBytes(it.Value()))
+		if err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+func deleteAll(t kvdb.Store, prefix []byte) error {
+	var keys [][]byte
+	it := t.NewIterator(prefix, nil)
+	for it.Next() {
+		keys = append(keys, common.CopyBytes(it.Key()))
+	}
+	err := it.Error()
+	it.Release()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err := t.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,20 @@ type IDStore interface {
 	SetID(string)
 }
 
+// Failure describes the failed migration step.
+type Failure struct {
+	Name  string `json:"name"`
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// FailureStore records the failed migration step.
+type FailureStore interface {
+	GetFailure() *Failure
+	// SetFailure records the failed step, nil erases the record.
+	SetFailure(*Failure)
+}
+
 type inmemIDStore struct {
 	lastID string
 }
@@ -3,19 +3,22 @@ package migration
 import (
 	"github.com/Fantom-foundation/lachesis-base/kvdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // KvdbIDStore stores id
 type KvdbIDStore struct {
-	table kvdb.Store
-	key   []byte
+	table      kvdb.Store
+	key        []byte
+	failureKey []byte
 }
 
 // NewKvdbIDStore constructor
 func NewKvdbIDStore(table kvdb.Store) *KvdbIDStore {
 	return &KvdbIDStore{
-		table: table,
-		key:   []byte("id"),
+		table:      table,
+		key:        []byte("id"),
+		failureKey: []byte("failed"),
 	}
 }
 
@@ -39,3 +42,39 @@ func (p *KvdbIDStore) SetID(id string) {
 		log.Crit("Failed to put key-value", "err", err)
 	}
 }
+
+// GetFailure returns the failed migration step, nil if the last migration didn't fail
+func (p *KvdbIDStore) GetFailure() *Failure {
+	b, err := p.table.Get(p.failureKey)
+	if err != nil {
+		log.Crit("Failed to get key-value", "err", err)
+	}
+	if b == nil {
+		return nil
+	}
+	failure := &Failure{}
+	err = rlp.DecodeBytes(b, failure)
+	if err != nil {
+		log.Crit("Failed to decode rlp", "err", err)
+	}
+	return failure
+}
+
+// SetFailure records the failed migration step, nil erases the record
+func (p *KvdbIDStore) SetFailure(failure *Failure) {
+	if failure == nil {
+		err := p.table.Delete(p.failureKey)
+		if err != nil {
+			log.Crit("Failed to delete key", "err", err)
+		}
+		return
+	}
+	b, err := rlp.EncodeToBytes(failure)
+	if err != nil {
+		log.Crit("Failed to encode rlp", "err", err)
+	}
+	err = p.table.Put(p.failureKey, b)
+	if err != nil {
+		log.Crit("Failed to put key-value", "err", err)
+	}
+}
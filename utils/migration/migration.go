@@ -10,9 +10,18 @@ import (
 
 // Migration is a migration step.
 type Migration struct {
-	name string
-	exec func() error
-	prev *Migration
+	name   string
+	exec   func() error
+	tables []string
+	prev   *Migration
+}
+
+// Step is a status of a migration step.
+type Step struct {
+	Name    string   `json:"name"`
+	ID      string   `json:"id"`
+	Tables  []string `json:"tables,omitempty"`
+	Applied bool     `json:"applied"`
 }
 
 // Begin with empty unique migration step.
@@ -39,6 +48,24 @@ func (m *Migration) Next(name string, exec func() error) *Migration {
 	}
 }
 
+// Affects declares the tables modified by the migration step,
+// they are checkpointed before the step and restored if the step fails.
+// All the tables are checkpointed if the step doesn't declare the affected ones.
+func (m *Migration) Affects(tables ...string) *Migration {
+	m.tables = append(m.tables, tables...)
+	return m
+}
+
+// Name of the migration step.
+func (m *Migration) Name() string {
+	return m.name
+}
+
+// Tables modified by the migration step.
+func (m *Migration) Tables() []string {
+	return m.tables
+}
+
 // ID is an uniq migration's id.
 func (m *Migration) ID() string {
 	digest := sha256.New()
@@ -50,6 +77,13 @@ func (m *Migration) ID() string {
 
 // Exec method run migrations chain in order
 func (m *Migration) Exec(curr IDStore) error {
+	return m.ExecWithCheckpoints(curr, nil)
+}
+
+// ExecWithCheckpoints runs migrations chain in order.
+// The tables affected by a step are saved by the checkpointer before the step,
+// and restored if the step fails. The failed step is recorded if curr is a FailureStore.
+func (m *Migration) ExecWithCheckpoints(curr IDStore, cp Checkpointer) error {
 	currID := curr.GetID()
 	myID := m.ID()
 
@@ -64,22 +98,87 @@ func (m *Migration) Exec(curr IDStore) error {
 		return nil
 	}
 
-	err := m.prev.Exec(curr)
+	err := m.prev.ExecWithCheckpoints(curr, cp)
 	if err != nil {
 		return err
 	}
 
+	tables := m.tables
+	if cp != nil && len(tables) == 0 {
+		tables = cp.Tables()
+	}
+	checkpointed := cp != nil && len(tables) != 0
+	if checkpointed {
+		err = cp.Save(tables)
+		if err != nil {
+			return fmt.Errorf("failed to checkpoint tables %v: %v", tables, err)
+		}
+	}
 	err = m.exec()
 	if err != nil {
 		log.Error("'"+m.name+"' migration failed", "err", err)
+		if checkpointed {
+			if rerr := cp.Restore(tables); rerr != nil {
+				log.Error("Failed to restore tables", "tables", tables, "err", rerr)
+			} else {
+				log.Warn("Restored tables", "tables", tables)
+			}
+		}
+		if failures, ok := curr.(FailureStore); ok {
+			failures.SetFailure(&Failure{
+				Name:  m.name,
+				ID:    myID,
+				Error: err.Error(),
+			})
+		}
 		return err
 	}
+	if checkpointed {
+		err = cp.Discard(tables)
+		if err != nil {
+			return fmt.Errorf("failed to discard checkpoint of tables %v: %v", tables, err)
+		}
+	}
 	log.Warn("'" + m.name + "' migration has been applied")
 
 	curr.SetID(myID)
+	if failures, ok := curr.(FailureStore); ok {
+		if failed := failures.GetFailure(); failed != nil && failed.ID == myID {
+			failures.SetFailure(nil)
+		}
+	}
 	return nil
 }
 
+// Steps returns the migrations chain in order, the steps up to the current one are marked as applied.
+func (m *Migration) Steps(curr IDStore) ([]Step, error) {
+	var chain []*Migration
+	for s := m; s != nil; s = s.prev {
+		chain = append([]*Migration{s}, chain...)
+	}
+	currID := curr.GetID()
+	applied := -1
+	for i, s := range chain {
+		if s.ID() == currID {
+			applied = i
+		}
+	}
+	if currID != "" && applied < 0 {
+		return nil, errors.New("unknown version: " + currID)
+	}
+
+	steps := make([]Step, len(chain))
+	for i, s := range chain {
+		steps[i] = Step{
+			Name:    s.name,
+			ID:      s.ID(),
+			Tables:  s.tables,
+			Applied: i <= applied,
+		}
+	}
+	return steps, nil
+}
+
 func (m *Migration) veryFirst() bool {
 	return m.exec == nil
 }
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/table"
 	"github.com/stretchr/testify/require"
 )
 
@@ -98,3 +101,109 @@ func TestMigrations(t *testing.T) {
 		require.Error(err)
 	})
 }
+
+func TestMigrationsCheckpoints(t *testing.T) {
+	require := require.New(t)
+
+	db := memorydb.New()
+	versions := NewKvdbIDStore(table.New(db, []byte("_")))
+	tableA := table.New(db, []byte("a"))
+	tableB := table.New(db, []byte("b"))
+	checkpoints := NewKvdbCheckpointer(table.New(db, []byte("!")), map[string]kvdb.Store{
+		"A": tableA,
+		"B": tableB,
+	})
+	require.NoError(tableA.Put([]byte{1}, []byte{1}))
+	require.NoError(tableB.Put([]byte{1}, []byte{1}))
+
+	begin := Begin("checkpoints")
+	first := begin.Next("01", func() error {
+		return tableA.Put([]byte{2}, []byte{2})
+	}).Affects("A")
+	failing := first.Next("02", func() error {
+		require.NoError(tableA.Delete([]byte{1}))
+		require.NoError(tableB.Put([]byte{1}, []byte{2}))
+		return errors.New("test migration error")
+	}).Affects("A", "B")
+	versions.SetID(begin.ID())
+
+	steps, err := failing.Steps(versions)
+	require.NoError(err)
+	require.Equal([]Step{
+		{Name: "checkpoints", ID: begin.ID(), Applied: true},
+		{Name: "01", ID: first.ID(), Tables: []string{"A"}},
+		{Name: "02", ID: failing.ID(), Tables: []string{"A", "B"}},
+	}, steps)
+
+	require.Error(failing.ExecWithCheckpoints(versions, checkpoints))
+	require.Equal(first.ID(), versions.GetID())
+	require.Equal(&Failure{Name: "02", ID: failing.ID(), Error: "test migration error"}, versions.GetFailure())
+	// the failed step is rolled back, the applied step is kept
+	v, err := tableA.Get([]byte{1})
+	require.NoError(err)
+	require.Equal([]byte{1}, v)
+	v, err = tableA.Get([]byte{2})
+	require.NoError(err)
+	require.Equal([]byte{2}, v)
+	v, err = tableB.Get([]byte{1})
+	require.NoError(err)
+	require.Equal([]byte{1}, v)
+	require.True(isEmpty(table.New(db, []byte("!"))))
+
+	steps, err = failing.Steps(versions)
+	require.NoError(err)
+	require.True(steps[1].Applied)
+	require.False(steps[2].Applied)
+
+	fixed := first.Next("02", func() error {
+		return tableB.Put([]byte{1}, []byte{2})
+	}).Affects("B")
+	require.NoError(fixed.ExecWithCheckpoints(versions, checkpoints))
+	require.Equal(fixed.ID(), versions.GetID())
+	require.Nil(versions.GetFailure())
+	v, err = tableB.Get([]byte{1})
+	require.NoError(err)
+	require.Equal([]byte{2}, v)
+	require.True(isEmpty(table.New(db, []byte("!"))))
+}
+
+func TestMigrationsCheckpointAllTables(t *testing.T) {
+	require := require.New(t)
+
+	db := memorydb.New()
+	versions := NewKvdbIDStore(table.New(db, []byte("_")))
+	tableA := table.New(db, []byte("a"))
+	tableB := table.New(db, []byte("b"))
+	checkpoints := NewKvdbCheckpointer(table.New(db, []byte("!")), map[string]kvdb.Store{
+		"B": tableB,
+		"A": tableA,
+	})
+	require.Equal([]string{"A", "B"}, checkpoints.Tables())
+	require.NoError(tableA.Put([]byte{1}, []byte{1}))
+	require.NoError(tableB.Put([]byte{1}, []byte{1}))
+
+	// the step doesn't declare the affected tables, so all of them are checkpointed
+	begin := Begin("checkpoints")
+	failing := begin.Next("01", func() error {
+		require.NoError(tableA.Delete([]byte{1}))
+		require.NoError(tableB.Put([]byte{2}, []byte{2}))
+		return errors.New("test migration error")
+	})
+	versions.SetID(begin.ID())
+
+	require.Error(failing.ExecWithCheckpoints(versions, checkpoints))
+	require.Equal(begin.ID(), versions.GetID())
+	v, err := tableA.Get([]byte{1})
+	require.NoError(err)
+	require.Equal([]byte{1}, v)
+	v, err = tableB.Get([]byte{2})
+	require.NoError(err)
+	require.Nil(v)
+	require.True(isEmpty(table.New(db, []byte("!"))))
+}
+
+func isEmpty(db kvdb.Iteratee) bool {
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	return !it.Next()
+}